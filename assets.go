@@ -0,0 +1,221 @@
+package templ
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Assets fingerprints static files, so that they can be served with far-future caching. The
+// fingerprint is a hash of the contents of the file, added to its name, e.g. app.css is served as
+// app.3f2a1b4c5d6e7f80.css. When the file changes, so does its URL.
+type Assets struct {
+	fsys   fs.FS
+	prefix string
+	// byName is keyed by the name of the file in the file system.
+	byName map[string]*asset
+	// byPath is keyed by the fingerprinted name.
+	byPath map[string]*asset
+}
+
+type asset struct {
+	name       string
+	hashedName string
+	// hash is the hex encoded fingerprint, which is also used as the ETag.
+	hash string
+	// integrity is the subresource integrity value.
+	integrity string
+}
+
+// NewAssets hashes the files in fsys. The files are served at the URL path prefix, e.g. "/static/".
+func NewAssets(fsys fs.FS, prefix string) (*Assets, error) {
+	a := &Assets{
+		fsys:   fsys,
+		prefix: strings.TrimSuffix(prefix, "/") + "/",
+		byName: map[string]*asset{},
+		byPath: map[string]*asset{},
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		h := sha512.New384()
+		if _, err = io.Copy(h, f); err != nil {
+			return fmt.Errorf("templ: failed to hash asset %q: %w", name, err)
+		}
+		sum := h.Sum(nil)
+		hash := hex.EncodeToString(sum[:8])
+		ext := path.Ext(name)
+		entry := &asset{
+			name:       name,
+			hashedName: strings.TrimSuffix(name, ext) + "." + hash + ext,
+			hash:       hash,
+			integrity:  "sha384-" + base64.StdEncoding.EncodeToString(sum),
+		}
+		a.byName[entry.name] = entry
+		a.byPath[entry.hashedName] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// URL returns the fingerprinted URL of the file. If the file doesn't exist, the URL is not
+// fingerprinted.
+func (a *Assets) URL(name string) SafeURL {
+	name = strings.TrimPrefix(name, "/")
+	if entry, ok := a.byName[name]; ok {
+		return SafeURL(a.prefix + entry.hashedName)
+	}
+	return SafeURL(a.prefix + name)
+}
+
+// Integrity returns the subresource integrity value of the file, e.g. "sha384-...", or an empty
+// string if the file doesn't exist.
+func (a *Assets) Integrity(name string) string {
+	if entry, ok := a.byName[strings.TrimPrefix(name, "/")]; ok {
+		return entry.integrity
+	}
+	return ""
+}
+
+// ServeHTTP serves the files. Fingerprinted URLs are cached by browsers for a year without
+// revalidation, because their contents never change. Files requested by their original name are
+// revalidated on each use.
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutPrefix(r.URL.Path, a.prefix)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	entry, fingerprinted := a.byPath[name]
+	if !fingerprinted {
+		if entry, ok = a.byName[name]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	if fingerprinted {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.Header().Set("ETag", `"`+entry.hash+`"`)
+	http.ServeFileFS(w, r, a.fsys, entry.name)
+}
+
+// Middleware adds the assets to the context of each request, for use by Asset.
+func (a *Assets) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithAssets(r.Context(), a)))
+	})
+}
+
+type assetsContextKeyType int
+
+const assetsContextKey assetsContextKeyType = iota
+
+// WithAssets sets the Assets used by Asset.
+func WithAssets(ctx context.Context, a *Assets) context.Context {
+	return context.WithValue(ctx, assetsContextKey, a)
+}
+
+// GetAssets returns the Assets set with WithAssets, or nil.
+func GetAssets(ctx context.Context) *Assets {
+	a, _ := ctx.Value(assetsContextKey).(*Assets)
+	return a
+}
+
+// Asset returns the fingerprinted URL of the file, using the Assets in the context.
+//
+// If there are no Assets in the context, the name is returned as a URL.
+func Asset(ctx context.Context, name string) SafeURL {
+	if a := GetAssets(ctx); a != nil {
+		return a.URL(name)
+	}
+	return URL(name)
+}
+
+// AssetIntegrity returns the subresource integrity value of the file, using the Assets in the
+// context, or an empty string if it isn't known.
+func AssetIntegrity(ctx context.Context, name string) string {
+	if a := GetAssets(ctx); a != nil {
+		return a.Integrity(name)
+	}
+	return ""
+}
+
+// AssetStylesheet renders a <link rel="stylesheet"> tag for the file, with its integrity.
+func AssetStylesheet(name string) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		return writeStrings(w, `<link rel="stylesheet" href="`, EscapeString(Asset(ctx, name)), `"`, integrityAttribute(ctx, name), `>`)
+	})
+}
+
+// AssetScript renders a <script> tag for the file, with its integrity, and the nonce in the
+// context, if there is one.
+func AssetScript(name string) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		var nonce string
+		if n := GetNonce(ctx); n != "" {
+			nonce = ` nonce="` + EscapeString(n) + `"`
+		}
+		return writeStrings(w, `<script src="`, EscapeString(Asset(ctx, name)), `"`, integrityAttribute(ctx, name), nonce, `></script>`)
+	})
+}
+
+// AssetPreload renders a <link rel="preload"> tag for the file, so that the browser starts
+// downloading it before it's used. The type of content is determined by the file extension.
+func AssetPreload(name string) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		as := preloadDestination(name)
+		var crossOrigin string
+		if as == "font" || as == "fetch" {
+			// Fonts and fetches use CORS mode, so the preload must too, or it won't be used.
+			crossOrigin = ` crossorigin`
+		}
+		return writeStrings(w, `<link rel="preload" href="`, EscapeString(Asset(ctx, name)), `" as="`, as, `"`, integrityAttribute(ctx, name), crossOrigin, `>`)
+	})
+}
+
+func integrityAttribute(ctx context.Context, name string) string {
+	if integrity := AssetIntegrity(ctx, name); integrity != "" {
+		return ` integrity="` + integrity + `"`
+	}
+	return ""
+}
+
+// preloadDestination returns the value of the as attribute of a preload link.
+func preloadDestination(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".css":
+		return "style"
+	case ".js", ".mjs":
+		return "script"
+	case ".woff", ".woff2", ".ttf", ".otf":
+		return "font"
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".avif", ".svg", ".ico":
+		return "image"
+	default:
+		return "fetch"
+	}
+}
@@ -0,0 +1,183 @@
+package templ_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAssets(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.css":          {Data: []byte("body { color: red; }")},
+		"js/app.js":        {Data: []byte("console.log('hello');")},
+		"fonts/font.woff2": {Data: []byte("font")},
+	}
+	assets, err := templ.NewAssets(fsys, "/static")
+	if err != nil {
+		t.Fatalf("failed to create assets: %v", err)
+	}
+	// The fingerprint is the start of the SHA-384 hash, and the integrity is the whole hash.
+	const cssURL = "/static/app.04df2c898b09aa53.css"
+	const cssIntegrity = "sha384-BN8siYsJqlPeNsRFs2pYbTW0uiUBy9v6JVVKpHaS+KNqD0ZFotD5OFKMkI6/s6sb"
+	ctx := templ.WithAssets(context.Background(), assets)
+
+	t.Run("the URL is fingerprinted", func(t *testing.T) {
+		if diff := cmp.Diff(templ.SafeURL(cssURL), templ.Asset(ctx, "app.css")); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff(cssIntegrity, templ.AssetIntegrity(ctx, "app.css")); diff != "" {
+			t.Error(diff)
+		}
+		if url := templ.Asset(ctx, "/js/app.js"); !strings.HasPrefix(string(url), "/static/js/app.") || !strings.HasSuffix(string(url), ".js") {
+			t.Errorf("unexpected URL %q", url)
+		}
+	})
+	t.Run("unknown files are not fingerprinted", func(t *testing.T) {
+		if diff := cmp.Diff(templ.SafeURL("/static/missing.css"), templ.Asset(ctx, "missing.css")); diff != "" {
+			t.Error(diff)
+		}
+		if integrity := templ.AssetIntegrity(ctx, "missing.css"); integrity != "" {
+			t.Errorf("expected no integrity, got %q", integrity)
+		}
+	})
+	t.Run("without assets in the context, the name is used", func(t *testing.T) {
+		if diff := cmp.Diff(templ.SafeURL("/app.css"), templ.Asset(context.Background(), "/app.css")); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("components", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			ctx      context.Context
+			input    templ.Component
+			expected string
+		}{
+			{
+				name:     "stylesheet",
+				ctx:      ctx,
+				input:    templ.AssetStylesheet("app.css"),
+				expected: `<link rel="stylesheet" href="` + cssURL + `" integrity="` + cssIntegrity + `">`,
+			},
+			{
+				name:     "preload",
+				ctx:      ctx,
+				input:    templ.AssetPreload("app.css"),
+				expected: `<link rel="preload" href="` + cssURL + `" as="style" integrity="` + cssIntegrity + `">`,
+			},
+			{
+				name:     "fonts are preloaded with CORS",
+				ctx:      ctx,
+				input:    templ.AssetPreload("fonts/font.woff2"),
+				expected: `<link rel="preload" href="` + string(assets.URL("fonts/font.woff2")) + `" as="font" integrity="` + assets.Integrity("fonts/font.woff2") + `" crossorigin>`,
+			},
+			{
+				name:     "script",
+				ctx:      templ.WithNonce(ctx, "abc"),
+				input:    templ.AssetScript("js/app.js"),
+				expected: `<script src="` + string(assets.URL("js/app.js")) + `" integrity="` + assets.Integrity("js/app.js") + `" nonce="abc"></script>`,
+			},
+			{
+				name:     "unknown files don't have an integrity attribute",
+				ctx:      ctx,
+				input:    templ.AssetScript("missing.js"),
+				expected: `<script src="/static/missing.js"></script>`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var sb strings.Builder
+				if err := tt.input.Render(tt.ctx, &sb); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if diff := cmp.Diff(tt.expected, sb.String()); diff != "" {
+					t.Error(diff)
+				}
+			})
+		}
+	})
+
+	t.Run("serving", func(t *testing.T) {
+		tests := []struct {
+			name                 string
+			path                 string
+			expectedStatus       int
+			expectedCacheControl string
+			expectedBody         string
+		}{
+			{
+				name:                 "fingerprinted files are immutable",
+				path:                 cssURL,
+				expectedStatus:       http.StatusOK,
+				expectedCacheControl: "public, max-age=31536000, immutable",
+				expectedBody:         "body { color: red; }",
+			},
+			{
+				name:                 "files requested by their original name are revalidated",
+				path:                 "/static/app.css",
+				expectedStatus:       http.StatusOK,
+				expectedCacheControl: "no-cache",
+				expectedBody:         "body { color: red; }",
+			},
+			{
+				name:           "old fingerprints are not found",
+				path:           "/static/app.0000000000000000.css",
+				expectedStatus: http.StatusNotFound,
+			},
+			{
+				name:           "paths outside the prefix are not found",
+				path:           "/app.css",
+				expectedStatus: http.StatusNotFound,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				assets.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				if w.Code != tt.expectedStatus {
+					t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+				}
+				if tt.expectedStatus != http.StatusOK {
+					return
+				}
+				if actual := w.Header().Get("Cache-Control"); actual != tt.expectedCacheControl {
+					t.Errorf("expected Cache-Control %q, got %q", tt.expectedCacheControl, actual)
+				}
+				if actual := w.Header().Get("Content-Type"); actual != "text/css; charset=utf-8" {
+					t.Errorf("unexpected Content-Type %q", actual)
+				}
+				if diff := cmp.Diff(tt.expectedBody, w.Body.String()); diff != "" {
+					t.Error(diff)
+				}
+			})
+		}
+		t.Run("the ETag is used to revalidate", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			assets.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/static/app.css", nil))
+			r := httptest.NewRequest(http.MethodGet, "/static/app.css", nil)
+			r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+			w = httptest.NewRecorder()
+			assets.ServeHTTP(w, r)
+			if w.Code != http.StatusNotModified {
+				t.Errorf("expected status %d, got %d", http.StatusNotModified, w.Code)
+			}
+		})
+	})
+
+	t.Run("the middleware adds the assets to the context", func(t *testing.T) {
+		var url templ.SafeURL
+		h := assets.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			url = templ.Asset(r.Context(), "app.css")
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		if diff := cmp.Diff(templ.SafeURL(cssURL), url); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
@@ -24,6 +24,7 @@ type Arguments struct {
 	WorkerCount      int
 	PrettierCommand  string
 	PrettierRequired bool
+	SortClasses      bool
 }
 
 func Run(log *slog.Logger, stdin io.Reader, stdout io.Writer, args Arguments) (err error) {
@@ -31,6 +32,7 @@ func Run(log *slog.Logger, stdin io.Reader, stdout io.Writer, args Arguments) (e
 	formatterConfig := format.Config{
 		PrettierCommand:  args.PrettierCommand,
 		PrettierRequired: args.PrettierRequired,
+		SortClasses:      args.SortClasses,
 	}
 	if len(args.Files) == 0 {
 		src, err := io.ReadAll(stdin)
@@ -0,0 +1,52 @@
+package generatecmd
+
+import (
+	"errors"
+	"go/scanner"
+	"os"
+
+	"github.com/a-h/parse"
+	"github.com/a-h/templ/cmd/templ/generatecmd/proxy"
+)
+
+// snippetContext is the number of lines shown either side of an error in the browser.
+const snippetContext = 3
+
+// newBuildError creates an error to show in the browser from an error returned when
+// generating code for the templ file.
+func newBuildError(fileName string, err error) (be proxy.BuildError) {
+	be = proxy.BuildError{
+		File:    fileName,
+		Message: err.Error(),
+	}
+	if msg, line, col, ok := errorPosition(err); ok {
+		be.Message, be.Line, be.Col = msg, line, col
+	}
+	if be.Line == 0 {
+		return be
+	}
+	if src, err := os.ReadFile(fileName); err == nil {
+		be.Snippet = proxy.Snippet(string(src), be.Line, snippetContext)
+	}
+	return be
+}
+
+// errorPosition returns the message, and the one based line and column of the error, if the error
+// has a position.
+func errorPosition(err error) (msg string, line, col int, ok bool) {
+	var pe parse.ParseError
+	var tce TypeCheckError
+	var list scanner.ErrorList
+	switch {
+	case errors.As(err, &tce) && len(tce.Errors) > 0:
+		// Type check error positions are one based.
+		return tce.Errors[0].Msg, tce.Errors[0].Pos.Line, tce.Errors[0].Pos.Column, true
+	case errors.As(err, &pe):
+		// Parse error positions are zero based.
+		return pe.Msg, pe.Pos.Line + 1, pe.Pos.Col + 1, true
+	case errors.As(err, &list) && len(list) > 0:
+		// Formatting errors have been remapped to one based lines in the templ file.
+		return list[0].Msg, list[0].Pos.Line, list[0].Pos.Column + 1, true
+	}
+	return "", 0, 0, false
+}
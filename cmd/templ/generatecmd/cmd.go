@@ -6,13 +6,17 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +27,8 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/a-h/templ"
+	"github.com/a-h/templ/cmd/templ/generatecmd/dotenv"
+	"github.com/a-h/templ/cmd/templ/generatecmd/jsonevents"
 	"github.com/a-h/templ/cmd/templ/generatecmd/modcheck"
 	"github.com/a-h/templ/cmd/templ/generatecmd/proxy"
 	"github.com/a-h/templ/cmd/templ/generatecmd/run"
@@ -34,6 +40,17 @@ import (
 )
 
 func NewGenerate(log *slog.Logger, args Arguments) (g *Generate, err error) {
+	if args.Command != "" {
+		c := Command{Name: defaultCommandName, Cmd: args.Command}
+		if err = c.parseRestart(); err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(args.Commands, func(existing Command) bool { return existing.Name == c.Name }) {
+			return nil, fmt.Errorf("duplicate command name %q, use Commands instead of Command", c.Name)
+		}
+		args.Commands = append([]Command{c}, args.Commands...)
+		args.Command = ""
+	}
 	g = &Generate{
 		Log:  log,
 		Args: args,
@@ -45,6 +62,13 @@ type Generate struct {
 	Log        *slog.Logger
 	Args       Arguments
 	ShouldSkip func(string) bool
+	// Events is the stream of JSON events, written to Args.JSONEvents, and served by the proxy. It's
+	// nil unless Args.JSONEvents is set.
+	Events *jsonevents.Stream
+	// typeChecker holds generated code until it has been type checked, if Args.TypeCheck is set.
+	typeChecker *typeChecker
+	// cssBundle collects the CSS of constant css templates, if Args.CSSBundle is set.
+	cssBundle *cssBundle
 }
 
 type GenerationEvent struct {
@@ -53,6 +77,17 @@ type GenerationEvent struct {
 	WatchedFileUpdated   bool
 	TemplFileTextUpdated bool
 	TemplFileGoUpdated   bool
+	CSSFileUpdated       bool
+	EnvFileUpdated       bool
+	// UpdatedFiles are the names of the files that caused the event.
+	UpdatedFiles []string
+}
+
+// isHotSwappedCSS returns true if the updated file is a CSS file that's applied in the browser,
+// instead of restarting the commands. CSS files are only hot swapped if -css-hot-swap is set,
+// otherwise they're handled like other watched files.
+func (ge *GenerationEvent) isHotSwappedCSS(fileName string) bool {
+	return ge.CSSFileUpdated && strings.HasSuffix(fileName, ".css")
 }
 
 func (cmd Generate) Run(ctx context.Context) (err error) {
@@ -73,6 +108,16 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 		}
 	}
 
+	// Env files are watched for changes, so they can be reloaded.
+	for i, fileName := range cmd.Args.EnvFiles {
+		if cmd.Args.EnvFiles[i], err = filepath.Abs(fileName); err != nil {
+			return fmt.Errorf("failed to get absolute path of env file: %w", err)
+		}
+	}
+	if cmd.Args.Watch && len(cmd.Args.EnvFiles) > 0 {
+		cmd.Args.WatchPattern = cmd.watchPatternWithEnvFiles()
+	}
+
 	// Load ignore patterns.
 	cmd.ShouldSkip, err = ignorefile.ShouldSkipFunc(cmd.Args.Path, ".templignore_generate")
 	if err != nil {
@@ -87,6 +132,13 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 	if cmd.Args.IncludeTimestamp {
 		opts = append(opts, generator.WithTimestamp(time.Now()))
 	}
+	if cmd.Args.CSSBundle != "" {
+		if cmd.Args.CSSBundle, err = filepath.Abs(cmd.Args.CSSBundle); err != nil {
+			return fmt.Errorf("failed to get absolute path of CSS bundle: %w", err)
+		}
+		opts = append(opts, generator.WithCSSBundle())
+		cmd.cssBundle = newCSSBundle(cmd.Args.CSSBundle, cmd.Args.FileWriter)
+	}
 
 	// Check the version of the templ module.
 	if err := modcheck.Check(cmd.Args.Path); err != nil {
@@ -104,6 +156,16 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 		cmd.Args.FileWriter,
 		cmd.Args.Lazy,
 	)
+	if cmd.Args.JSONEvents != nil {
+		cmd.Events = jsonevents.New(cmd.Args.JSONEvents)
+		fseh.Events = cmd.Events
+	}
+	if cmd.Args.TypeCheck {
+		cmd.typeChecker = newTypeChecker(cmd.Args.Path, cmd.Args.FileWriter)
+		fseh.typeChecker = cmd.typeChecker
+	}
+	fseh.cssBundle = cmd.cssBundle
+	fseh.CSSHotSwap = cmd.Args.CSSHotSwap
 
 	// If we're processing a single file, don't bother setting up the channels/multithreaing.
 	if cmd.Args.FileName != "" {
@@ -111,12 +173,30 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 			Name: cmd.Args.FileName,
 			Op:   fsnotify.Create,
 		})
-		return err
+		if err != nil || cmd.typeChecker == nil {
+			return err
+		}
+		if _, errs := cmd.typeChecker.check(ctx); len(errs) > 0 {
+			return errors.Join(toErrors(errs)...)
+		}
+		return nil
 	}
 
 	// Start timer.
 	start := time.Now()
 
+	// Create the proxy handler before generation starts, so that errors are shown in the browser.
+	var p *proxy.Handler
+	if cmd.Args.Proxy != "" {
+		if p, err = cmd.newProxy(); err != nil {
+			return err
+		}
+		// The events are only served when they're enabled with -json-events.
+		if cmd.Events != nil {
+			p.Events = cmd.Events
+		}
+	}
+
 	// For the initial filesystem walk and subsequent (optional) fsnotify events.
 	events := make(chan fsnotify.Event)
 	// For errs from the watcher.
@@ -136,7 +216,7 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 	// Start process to handle events.
 	grp.Go(func() error {
 		defer close(postGeneration)
-		cmd.handleEvents(ctx, events, errs, fseh, postGeneration)
+		cmd.handleEvents(ctx, events, errs, fseh, postGeneration, p)
 		return nil
 	})
 
@@ -144,7 +224,7 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 	var updates int
 	grp.Go(func() error {
 		defer close(errs)
-		updates, err = cmd.handlePostGenerationEvents(ctx, postGeneration)
+		updates, err = cmd.handlePostGenerationEvents(ctx, postGeneration, errs, p)
 		return err
 	})
 
@@ -167,8 +247,14 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 	if err = grp.Wait(); err != nil {
 		return err
 	}
-	if cmd.Args.Command != "" {
-		cmd.Log.Debug("Killing command", slog.String("command", cmd.Args.Command))
+	// Write the CSS bundle, in case no files were updated.
+	if cmd.cssBundle != nil {
+		if _, err = cmd.cssBundle.write(); err != nil {
+			return err
+		}
+	}
+	if len(cmd.Args.Commands) > 0 {
+		cmd.Log.Debug("Stopping commands", slog.Int("count", len(cmd.Args.Commands)))
 		if err := run.KillAll(); err != nil {
 			cmd.Log.Error("Error killing command", slog.Any("error", err))
 		}
@@ -188,6 +274,21 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 	return nil
 }
 
+// mergeGenerationEvents adds the updates of the event to the grouped event, which may be nil.
+func mergeGenerationEvents(grouped, ge *GenerationEvent) *GenerationEvent {
+	if grouped == nil {
+		return ge
+	}
+	grouped.UpdatedFiles = append(grouped.UpdatedFiles, ge.UpdatedFiles...)
+	grouped.GoFileWritten = grouped.GoFileWritten || ge.GoFileWritten
+	grouped.WatchedFileUpdated = grouped.WatchedFileUpdated || ge.WatchedFileUpdated
+	grouped.TemplFileTextUpdated = grouped.TemplFileTextUpdated || ge.TemplFileTextUpdated
+	grouped.TemplFileGoUpdated = grouped.TemplFileGoUpdated || ge.TemplFileGoUpdated
+	grouped.CSSFileUpdated = grouped.CSSFileUpdated || ge.CSSFileUpdated
+	grouped.EnvFileUpdated = grouped.EnvFileUpdated || ge.EnvFileUpdated
+	return grouped
+}
+
 func (cmd Generate) groupUntilNoMessagesReceivedFor100ms(postGeneration chan *GenerationEvent) (grouped *GenerationEvent, updates int, ok bool, err error) {
 	timeout := time.NewTimer(time.Hour * 24 * 365)
 loop:
@@ -201,13 +302,7 @@ loop:
 				}
 				return nil, 0, false, nil
 			}
-			if grouped == nil {
-				grouped = ge
-			}
-			grouped.GoFileWritten = grouped.GoFileWritten || ge.GoFileWritten
-			grouped.WatchedFileUpdated = grouped.WatchedFileUpdated || ge.WatchedFileUpdated
-			grouped.TemplFileTextUpdated = grouped.TemplFileTextUpdated || ge.TemplFileTextUpdated
-			grouped.TemplFileGoUpdated = grouped.TemplFileGoUpdated || ge.TemplFileGoUpdated
+			grouped = mergeGenerationEvents(grouped, ge)
 			if ge.GoFileWritten {
 				updates++
 			}
@@ -216,7 +311,7 @@ loop:
 			timeout = time.NewTimer(time.Millisecond * 100)
 		case <-timeout.C:
 			// If grouped is nil, or if no updates were made, reset the timer and continue waiting.
-			if grouped == nil || (!grouped.GoFileWritten && !grouped.WatchedFileUpdated && !grouped.TemplFileTextUpdated && !grouped.TemplFileGoUpdated) {
+			if grouped == nil || (!grouped.GoFileWritten && !grouped.WatchedFileUpdated && !grouped.TemplFileTextUpdated && !grouped.TemplFileGoUpdated && !grouped.CSSFileUpdated && !grouped.EnvFileUpdated) {
 				timeout = time.NewTimer(time.Hour * 24 * 365)
 				continue loop
 			}
@@ -226,9 +321,20 @@ loop:
 	}
 }
 
-func (cmd Generate) handlePostGenerationEvents(ctx context.Context, postGeneration chan *GenerationEvent) (updates int, err error) {
+func (cmd Generate) handlePostGenerationEvents(ctx context.Context, postGeneration chan *GenerationEvent, errs chan error, p *proxy.Handler) (updates int, err error) {
 	cmd.Log.Debug("Starting post-generation handler")
-	var p *proxy.Handler
+	var proxyStarted bool
+	var devModeEnv, fileEnv []string
+	if len(cmd.Args.Commands) > 0 && cmd.Args.Watch {
+		devModeEnv = cmd.devModeEnv()
+	}
+	var envLoaded bool
+	started := make(map[string]bool, len(cmd.Args.Commands))
+	claimed := claimedByPattern(cmd.Args.Commands)
+	prefixes := newCommandPrefixes(cmd.Args.Commands)
+	var typeCheckErrorKeys []string
+	// held is the event that's applied once type errors in generated code are fixed.
+	var held *GenerationEvent
 loop:
 	for {
 		grouped, updated, ok, err := cmd.groupUntilNoMessagesReceivedFor100ms(postGeneration)
@@ -239,61 +345,318 @@ loop:
 			break loop
 		}
 
-		// The Go application needs to be restarted if any watched non-templ watched files (i.e. non-templ Go files)
-		// were updated, or if any Go code within a templ file was updated.
-		needsRestart := grouped.WatchedFileUpdated || grouped.TemplFileGoUpdated
-		// If the text in a templ file, or any other changes have happened, reload the browser.
-		needsBrowserReload := grouped.TemplFileTextUpdated || grouped.TemplFileGoUpdated || grouped.WatchedFileUpdated
-
-		cmd.Log.Info("Post-generation event received, processing...", slog.Int("updates", updated), slog.Bool("needsRestart", needsRestart), slog.Bool("needsBrowserReload", needsBrowserReload))
-		updates += updated
-
-		if cmd.Args.Command != "" && needsRestart {
-			cmd.Log.Info("Executing command", slog.String("command", cmd.Args.Command))
-			if cmd.Args.Watch {
-				if err := os.Setenv("TEMPL_DEV_MODE", "true"); err != nil {
-					cmd.Log.Error("Error setting TEMPL_DEV_MODE environment variable", slog.Any("error", err))
+		// Generated code is written once it has been type checked. If generated code has errors,
+		// the event is held, so that the commands aren't restarted, and the browser isn't
+		// reloaded, until they're fixed. Errors in other Go files don't hold the event, since the
+		// commands report them.
+		if cmd.typeChecker != nil {
+			var typeCheckErrs []TypeCheckError
+			typeCheckErrorKeys, typeCheckErrs = cmd.typeCheck(ctx, p, typeCheckErrorKeys)
+			for _, err := range typeCheckErrs {
+				errs <- err
+			}
+			if slices.ContainsFunc(typeCheckErrs, func(err TypeCheckError) bool { return err.TemplFileName != "" }) {
+				held = mergeGenerationEvents(held, grouped)
+				updates += updated
+				if p != nil && !proxyStarted {
+					cmd.startProxy(p)
+					proxyStarted = true
 				}
-				// Check that the path is absolute.
-				// It should have already been made absolute at the start of the Run method, but just in case, we need to make sure it's absolute before setting it as an environment variable.
-				if !filepath.IsAbs(cmd.Args.Path) {
-					cmd.Log.Error("Path is not absolute, this may cause issues with the command execution", slog.String("path", cmd.Args.Path))
+				continue loop
+			}
+		}
+		if held != nil {
+			grouped = mergeGenerationEvents(held, grouped)
+			held = nil
+		}
+
+		// The CSS bundle is written before the commands are restarted, so that they serve the
+		// new stylesheet. It's handled like other watched CSS files, so it's hot swapped if
+		// -css-hot-swap is set, otherwise the commands are restarted.
+		if cmd.cssBundle != nil {
+			written, err := cmd.cssBundle.write()
+			if err != nil {
+				errs <- err
+			}
+			if written {
+				grouped.UpdatedFiles = append(grouped.UpdatedFiles, cmd.Args.CSSBundle)
+				if cmd.Args.CSSHotSwap {
+					grouped.CSSFileUpdated = true
+				} else {
+					grouped.WatchedFileUpdated = true
 				}
-				// Evaluate symlinks to match the behavior in runtime/watchmode.go.
-				watchRoot := cmd.Args.Path
-				if resolved, err := filepath.EvalSymlinks(watchRoot); err == nil {
-					watchRoot = resolved
+			}
+		}
+
+		// Env files are loaded before the commands are first started, and reloaded when they change.
+		var envChanged bool
+		if len(cmd.Args.EnvFiles) > 0 && (!envLoaded || grouped.EnvFileUpdated) {
+			env, err := cmd.loadEnvFiles()
+			if err != nil {
+				cmd.Log.Error("Error loading env files", slog.Any("error", err))
+				if p != nil {
+					p.SetError(envFileErrorKey, proxy.BuildError{Message: err.Error()})
 				}
-				if err := os.Setenv("TEMPL_DEV_MODE_WATCH_ROOT", watchRoot); err != nil {
-					cmd.Log.Error("Error setting TEMPL_DEV_MODE_WATCH_ROOT environment variable", slog.Any("error", err))
+			} else {
+				if p != nil {
+					p.ClearError(envFileErrorKey)
 				}
+				envChanged = envLoaded && !slices.Equal(env, fileEnv)
+				fileEnv = env
+				envLoaded = true
 			}
-			if _, err := run.Run(ctx, cmd.Args.Path, cmd.Args.Command); err != nil {
-				cmd.Log.Error("Error executing command", slog.Any("error", err))
+		}
+
+		// Commands are started after the first generation, and restarted according to their restart
+		// policy. All commands are restarted when the env files change.
+		var restarted []Command
+		for _, c := range cmd.Args.Commands {
+			if !started[c.Name] || envChanged || c.needsRestart(grouped, claimed) {
+				restarted = append(restarted, c)
 			}
 		}
-		if cmd.Args.Proxy != "" {
-			if p == nil {
+		// If the text in a templ file, or any other changes have happened, reload the browser.
+		needsBrowserReload := grouped.TemplFileTextUpdated || grouped.TemplFileGoUpdated || grouped.WatchedFileUpdated || envChanged
+
+		cmd.Log.Info("Post-generation event received, processing...", slog.Int("updates", updated), slog.Int("restarts", len(restarted)), slog.Bool("needsBrowserReload", needsBrowserReload))
+		updates += updated
+
+		nameToWaitReady := map[string]func(ctx context.Context) error{}
+		// Dev mode variables are added last, so that they can't be overridden by env files.
+		env := slices.Concat(fileEnv, devModeEnv)
+		for _, c := range restarted {
+			started[c.Name] = true
+			if waitReady := cmd.runCommand(ctx, c, env, prefixes[c.Name], p); waitReady != nil {
+				nameToWaitReady[c.Name] = waitReady
+			}
+		}
+		if p != nil {
+			if !proxyStarted {
 				cmd.Log.Debug("Starting proxy...")
-				p, err = cmd.startProxy()
-				if err != nil {
-					cmd.Log.Error("Failed to start proxy", slog.Any("error", err))
+				cmd.startProxy(p)
+				proxyStarted = true
+			}
+			if len(nameToWaitReady) > 0 {
+				cmd.waitForCommands(ctx, p, nameToWaitReady)
+			}
+			for _, fileName := range grouped.UpdatedFiles {
+				if !grouped.isHotSwappedCSS(fileName) {
+					continue
 				}
+				cmd.Log.Debug("Sending CSS update event", slog.String("file", fileName))
+				p.SendSSE("css", cmd.relativeURLPath(fileName))
+				cmd.Events.Publish(jsonevents.Event{Type: jsonevents.CSSReload, File: fileName})
 			}
 			if needsBrowserReload {
 				cmd.Log.Debug("Sending reload event")
 				p.SendSSE("message", "reload")
+				cmd.Events.Publish(jsonevents.Event{Type: jsonevents.Reload})
 			}
 		}
 	}
 	return updates, nil
 }
 
-func (cmd Generate) handleEvents(ctx context.Context, events chan fsnotify.Event, errs chan error, fseh *FSEventHandler, postGeneration chan *GenerationEvent) {
+// typeCheck writes the generated code that passes type checking, and shows the errors in the
+// browser. It returns the keys of the errors shown in the browser, so that they can be cleared
+// when they're fixed.
+func (cmd Generate) typeCheck(ctx context.Context, p *proxy.Handler, previousErrorKeys []string) (errorKeys []string, errs []TypeCheckError) {
+	start := time.Now()
+	written, errs := cmd.typeChecker.check(ctx)
+	cmd.Log.Debug("Type checked generated code", slog.Int("written", len(written)), slog.Int("errors", len(errs)), slog.Duration("in", time.Since(start)))
+	for _, err := range errs {
+		fileName := err.TemplFileName
+		if fileName == "" && len(err.Errors) > 0 {
+			fileName = err.Errors[0].Pos.Filename
+		}
+		cmd.Events.Publish(newErrorEvent(fileName, err))
+		if p == nil {
+			continue
+		}
+		key := typeCheckErrorKey(fileName)
+		p.SetError(key, newBuildError(fileName, err))
+		errorKeys = append(errorKeys, key)
+	}
+	if p != nil {
+		for _, key := range previousErrorKeys {
+			if !slices.Contains(errorKeys, key) {
+				p.ClearError(key)
+			}
+		}
+	}
+	return errorKeys, errs
+}
+
+// typeCheckErrorKey is the key of the error shown in the browser when a file fails type checking.
+func typeCheckErrorKey(fileName string) string {
+	return "typecheck:" + fileName
+}
+
+func toErrors(tces []TypeCheckError) (errs []error) {
+	for _, err := range tces {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// devModeEnv returns the environment variables that enable templ's development mode in the commands.
+func (cmd Generate) devModeEnv() (env []string) {
+	// Check that the path is absolute.
+	// It should have already been made absolute at the start of the Run method, but just in case, we need to make sure it's absolute before setting it as an environment variable.
+	if !filepath.IsAbs(cmd.Args.Path) {
+		cmd.Log.Error("Path is not absolute, this may cause issues with the command execution", slog.String("path", cmd.Args.Path))
+	}
+	// Evaluate symlinks to match the behavior in runtime/watchmode.go.
+	watchRoot := cmd.Args.Path
+	if resolved, err := filepath.EvalSymlinks(watchRoot); err == nil {
+		watchRoot = resolved
+	}
+	return []string{
+		"TEMPL_DEV_MODE=true",
+		"TEMPL_DEV_MODE_WATCH_ROOT=" + watchRoot,
+	}
+}
+
+// envFileErrorKey is the key of the error shown in the browser when the env files can't be loaded.
+const envFileErrorKey = "env"
+
+// loadEnvFiles returns the variables in the env files. Variables that are already set in the
+// environment of templ take precedence over the files, and aren't returned.
+func (cmd Generate) loadEnvFiles() (env []string, err error) {
+	vars, err := dotenv.Load(cmd.Args.EnvFiles...)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vars {
+		if _, ok := os.LookupEnv(v.Key); ok {
+			cmd.Log.Debug("Env file variable is overridden by the environment", slog.String("key", v.Key))
+			continue
+		}
+		env = append(env, v.String())
+	}
+	return env, nil
+}
+
+// isEnvFile returns true if the file is one of the env files.
+func (cmd Generate) isEnvFile(fileName string) bool {
+	return slices.Contains(cmd.Args.EnvFiles, fileName)
+}
+
+// watchPatternWithEnvFiles returns the watch pattern, extended to match the env files.
+func (cmd Generate) watchPatternWithEnvFiles() *regexp.Regexp {
+	patterns := []string{cmd.Args.WatchPattern.String()}
+	for _, fileName := range cmd.Args.EnvFiles {
+		patterns = append(patterns, "(^"+regexp.QuoteMeta(fileName)+"$)")
+	}
+	return regexp.MustCompile(strings.Join(patterns, "|"))
+}
+
+// commandErrorKey is the key of the error shown in the browser when the command fails.
+func commandErrorKey(name string) string {
+	return "cmd:" + name
+}
+
+// runCommand starts the command, stopping it first if it's already running. It returns a function
+// that waits until the command is ready, or nil if the command doesn't have a readiness probe.
+func (cmd Generate) runCommand(ctx context.Context, c Command, env []string, prefix string, p *proxy.Handler) (waitReady func(ctx context.Context) error) {
+	cmd.Log.Info("Executing command", slog.String("name", c.Name), slog.String("command", c.Cmd))
+	// When JSON events are written to stdout, the output of commands is written to stderr instead.
+	stdout := io.Writer(os.Stdout)
+	if cmd.Args.JSONEvents != nil {
+		stdout = os.Stderr
+	}
+	opts := run.Options{
+		Stdout: newPrefixWriter(stdout, prefix),
+		Stderr: newPrefixWriter(os.Stderr, prefix),
+		Env:    env,
+		Key:    c.Name,
+	}
+	exited := make(chan struct{})
+	opts.OnExit = func(e run.Exit) {
+		close(exited)
+		cmd.Events.Publish(newCommandExitedEvent(c, e))
+		if p == nil || e.Err == nil {
+			return
+		}
+		p.SetError(commandErrorKey(c.Name), proxy.BuildError{
+			Message: fmt.Sprintf("%s: %s: %v", c.Name, e.Input, e.Err),
+			Output:  e.Output,
+		})
+	}
+	matched := make(chan struct{})
+	if c.ready != nil && c.ready.pattern != nil {
+		onMatch := sync.OnceFunc(func() { close(matched) })
+		opts.Stdout = io.MultiWriter(opts.Stdout, newLogMatcher(c.ready.pattern, onMatch))
+		opts.Stderr = io.MultiWriter(opts.Stderr, newLogMatcher(c.ready.pattern, onMatch))
+	}
+	if p != nil {
+		p.ClearError(commandErrorKey(c.Name))
+	}
+	if _, err := run.Run(ctx, cmd.Args.Path, c.Cmd, opts); err != nil {
+		cmd.Log.Error("Error executing command", slog.String("name", c.Name), slog.Any("error", err))
+		if p != nil {
+			p.SetError(commandErrorKey(c.Name), proxy.BuildError{
+				Message: fmt.Sprintf("%s: %s: %v", c.Name, c.Cmd, err),
+			})
+		}
+		return nil
+	}
+	cmd.Events.Publish(jsonevents.Event{Type: jsonevents.CommandStarted, Name: c.Name, Cmd: c.Cmd})
+	if c.ready == nil {
+		return nil
+	}
+	return func(ctx context.Context) error {
+		return c.ready.wait(ctx, matched, exited)
+	}
+}
+
+func newCommandExitedEvent(c Command, e run.Exit) jsonevents.Event {
+	exitCode := 0
+	event := jsonevents.Event{Type: jsonevents.CommandExited, Name: c.Name, Cmd: c.Cmd, ExitCode: &exitCode}
+	if e.Err != nil {
+		event.Message = e.Err.Error()
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(e.Err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+	return event
+}
+
+// waitForCommands waits until the commands are ready, or the timeout is reached, showing that the
+// app isn't ready in the browser while waiting.
+func (cmd Generate) waitForCommands(ctx context.Context, p *proxy.Handler, nameToWaitReady map[string]func(ctx context.Context) error) {
+	p.SetWaiting(true)
+	defer p.SetWaiting(false)
+	ctx, cancel := context.WithTimeout(ctx, cmd.Args.ReadyTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for name, waitReady := range nameToWaitReady {
+		wg.Go(func() {
+			cmd.Log.Debug("Waiting for command to be ready", slog.String("name", name))
+			start := time.Now()
+			if err := waitReady(ctx); err != nil {
+				cmd.Log.Warn("Command is not ready, reloading anyway", slog.String("name", name), slog.Any("error", err))
+				return
+			}
+			cmd.Log.Info("Command is ready", slog.String("name", name), slog.Duration("in", time.Since(start)))
+		})
+	}
+	wg.Wait()
+}
+
+func (cmd Generate) handleEvents(ctx context.Context, events chan fsnotify.Event, errs chan error, fseh *FSEventHandler, postGeneration chan *GenerationEvent, p *proxy.Handler) {
 	var eventsWG sync.WaitGroup
 	sem := make(chan struct{}, cmd.Args.WorkerCount)
 	cmd.Log.Debug("Starting event handler")
 	for event := range events {
+		// Env files are loaded by the post-generation handler, rather than being treated as watched files.
+		if cmd.isEnvFile(event.Name) {
+			cmd.Log.Debug("Env file updated", slog.String("file", event.Name))
+			postGeneration <- &GenerationEvent{Event: event, EnvFileUpdated: true}
+			continue
+		}
 		eventsWG.Add(1)
 		sem <- struct{}{}
 		go func(event fsnotify.Event) {
@@ -304,7 +667,14 @@ func (cmd Generate) handleEvents(ctx context.Context, events chan fsnotify.Event
 			if err != nil {
 				errs <- err
 			}
-			if !r.GoFileWritten && !r.WatchedFileUpdated && !r.TemplFileTextUpdated && !r.TemplFileGoUpdated {
+			if p != nil {
+				if err != nil {
+					p.SetError(event.Name, newBuildError(event.Name, err))
+				} else if !fseh.HasError(event.Name) {
+					p.ClearError(event.Name)
+				}
+			}
+			if !r.GoFileWritten && !r.WatchedFileUpdated && !r.TemplFileTextUpdated && !r.TemplFileGoUpdated && !r.CSSFileUpdated {
 				cmd.Log.Debug("File not updated", slog.String("file", event.Name))
 				return
 			}
@@ -314,6 +684,8 @@ func (cmd Generate) handleEvents(ctx context.Context, events chan fsnotify.Event
 				WatchedFileUpdated:   r.WatchedFileUpdated,
 				TemplFileTextUpdated: r.TemplFileTextUpdated,
 				TemplFileGoUpdated:   r.TemplFileGoUpdated,
+				CSSFileUpdated:       r.CSSFileUpdated,
+				UpdatedFiles:         []string{event.Name},
 			}
 			cmd.Log.Debug("File updated", slog.String("file", event.Name))
 			postGeneration <- e
@@ -323,6 +695,16 @@ func (cmd Generate) handleEvents(ctx context.Context, events chan fsnotify.Event
 	eventsWG.Wait()
 }
 
+// relativeURLPath returns the path of the file relative to the watched directory, using forward
+// slashes, so that the browser can match it against the URLs of stylesheets.
+func (cmd Generate) relativeURLPath(fileName string) string {
+	rel, err := filepath.Rel(cmd.Args.Path, fileName)
+	if err != nil {
+		return filepath.ToSlash(filepath.Base(fileName))
+	}
+	return filepath.ToSlash(rel)
+}
+
 func (cmd *Generate) walkAndWatch(ctx context.Context, events chan fsnotify.Event, errs chan error) {
 	cmd.Log.Debug("Walking directory", slog.String("path", cmd.Args.Path), slog.Bool("devMode", cmd.Args.Watch))
 	if err := watcher.WalkFiles(ctx, cmd.Args.Path, cmd.Args.WatchPattern, cmd.Args.IgnorePattern, cmd.ShouldSkip, events); err != nil {
@@ -346,6 +728,15 @@ func (cmd *Generate) walkAndWatch(ctx context.Context, events chan fsnotify.Even
 		errs <- FatalError{Err: fmt.Errorf("failed to add path to watcher: %w", err)}
 		return
 	}
+	// Env files outside the path aren't in the watched tree, so they're watched separately.
+	for _, fileName := range cmd.Args.EnvFiles {
+		if strings.HasPrefix(fileName, cmd.Args.Path+string(filepath.Separator)) {
+			continue
+		}
+		if err = rw.AddFile(fileName); err != nil {
+			cmd.Log.Warn("Failed to watch env file, changes won't be reloaded", slog.String("file", fileName), slog.Any("error", err))
+		}
+	}
 	defer func() {
 		if err := rw.Close(); err != nil {
 			cmd.Log.Error("Failed to close watcher", slog.Any("error", err))
@@ -400,7 +791,7 @@ func (cmd *Generate) createTLSTransport() *http.Transport {
 	}
 }
 
-func (cmd *Generate) startProxy() (p *proxy.Handler, err error) {
+func (cmd *Generate) newProxy() (p *proxy.Handler, err error) {
 	var target *url.URL
 	target, err = url.Parse(cmd.Args.Proxy)
 	if err != nil {
@@ -410,7 +801,10 @@ func (cmd *Generate) startProxy() (p *proxy.Handler, err error) {
 	if cmd.Args.ProxyTLSCrt != "" && cmd.Args.ProxyTLSKey != "" {
 		scheme = "https"
 	}
-	p = proxy.New(cmd.Log, scheme, cmd.Args.ProxyBind, cmd.Args.ProxyPort, target)
+	return proxy.New(cmd.Log, scheme, cmd.Args.ProxyBind, cmd.Args.ProxyPort, target), nil
+}
+
+func (cmd *Generate) startProxy(p *proxy.Handler) {
 	go func() {
 		cmd.Log.Info("Proxying", slog.String("from", p.URL), slog.String("to", p.Target.String()))
 		server := &http.Server{
@@ -436,7 +830,7 @@ func (cmd *Generate) startProxy() (p *proxy.Handler, err error) {
 	}()
 	if !cmd.Args.OpenBrowser {
 		cmd.Log.Debug("Not opening browser")
-		return p, nil
+		return
 	}
 	go func() {
 		cmd.Log.Debug("Waiting for proxy to be ready", slog.String("url", p.URL))
@@ -462,5 +856,4 @@ func (cmd *Generate) startProxy() (p *proxy.Handler, err error) {
 			cmd.Log.Error("Failed to open browser", slog.Any("error", err))
 		}
 	}()
-	return p, nil
 }
@@ -0,0 +1,269 @@
+package generatecmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// Restart policies.
+const (
+	// RestartOnGoChange restarts the command when Go code changes. This is the default.
+	RestartOnGoChange = "go"
+	// RestartNever starts the command once, and doesn't restart it.
+	RestartNever = "never"
+	// restartOnPatternPrefix is followed by a regular expression. The command is restarted when a
+	// watched file that matches the expression changes.
+	restartOnPatternPrefix = "pattern:"
+)
+
+// defaultCommandName is the name of a command that is passed to -cmd without a name.
+const defaultCommandName = "cmd"
+
+// Command is a command that is run in watch mode.
+type Command struct {
+	// Name of the command, used to prefix its output.
+	Name string `json:"name"`
+	// Cmd is executed via the system shell.
+	Cmd string `json:"cmd"`
+	// Restart is the restart policy of the command: "go" (the default), "never", or
+	// "pattern:<regexp>".
+	Restart string `json:"restart,omitempty"`
+	// Ready is the readiness probe of the command: "http:<path or URL>", "tcp:<address>", or
+	// "log:<regexp>". The browser is reloaded when the probe passes.
+	Ready string `json:"ready,omitempty"`
+
+	restartPattern *regexp.Regexp
+	ready          *readinessProbe
+}
+
+// parseRestart validates the restart policy.
+func (c *Command) parseRestart() (err error) {
+	switch {
+	case c.Restart == "":
+		c.Restart = RestartOnGoChange
+	case c.Restart == RestartOnGoChange, c.Restart == RestartNever:
+	case strings.HasPrefix(c.Restart, restartOnPatternPrefix):
+		expr := strings.TrimPrefix(c.Restart, restartOnPatternPrefix)
+		if c.restartPattern, err = regexp.Compile(expr); err != nil {
+			return fmt.Errorf("command %q: invalid restart pattern %q: %w", c.Name, expr, err)
+		}
+	default:
+		return fmt.Errorf("command %q: invalid restart policy %q, expected %q, %q or \"pattern:<regexp>\"", c.Name, c.Restart, RestartOnGoChange, RestartNever)
+	}
+	return nil
+}
+
+// needsRestart returns true if the command should be restarted after the grouped event.
+// Commands with the "go" policy are restarted when Go code in templ files changes, or when other
+// watched files change, except CSS files that are hot swapped, and files claimed by another
+// command's restart pattern.
+func (c Command) needsRestart(grouped *GenerationEvent, claimed func(fileName string) bool) bool {
+	switch {
+	case c.Restart == RestartNever:
+		return false
+	case c.restartPattern != nil:
+		return slices.ContainsFunc(grouped.UpdatedFiles, c.restartPattern.MatchString)
+	}
+	if grouped.TemplFileGoUpdated {
+		return true
+	}
+	return slices.ContainsFunc(grouped.UpdatedFiles, func(fileName string) bool {
+		return !strings.HasSuffix(fileName, ".templ") && !grouped.isHotSwappedCSS(fileName) && !claimed(fileName)
+	})
+}
+
+// claimedByPattern returns a function that returns true if the file matches the restart pattern
+// of any of the commands.
+func claimedByPattern(commands []Command) func(fileName string) bool {
+	return func(fileName string) bool {
+		return slices.ContainsFunc(commands, func(c Command) bool {
+			return c.restartPattern != nil && c.restartPattern.MatchString(fileName)
+		})
+	}
+}
+
+// commandNameRegexp matches command names. Names are lowercase, so that commands that start with
+// environment variable assignments, e.g. "GOFLAGS=-race go run .", aren't mistaken for names.
+var commandNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// parseCommandFlag parses a -cmd flag value, which is either a command, or name=command.
+func parseCommandFlag(value string) (c Command) {
+	if name, cmd, ok := strings.Cut(value, "="); ok && commandNameRegexp.MatchString(name) {
+		return Command{Name: name, Cmd: cmd}
+	}
+	return Command{Name: defaultCommandName, Cmd: value}
+}
+
+// commandConfig is the format of the file passed to -cmd-config.
+type commandConfig struct {
+	Commands []Command `json:"commands"`
+}
+
+func readCommandConfig(fileName string) (commands []Command, err error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command config: %w", err)
+	}
+	var config commandConfig
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err = d.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse command config %q: %w", fileName, err)
+	}
+	return config.Commands, nil
+}
+
+// stringSliceFlag collects the values of a flag that can be repeated.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// commandFlags are the flags that configure the commands that are run in watch mode.
+type commandFlags struct {
+	cmd     stringSliceFlag
+	restart stringSliceFlag
+	ready   stringSliceFlag
+	config  string
+}
+
+// newCommands creates the list of commands from the flags. The proxy is used to resolve the
+// paths of HTTP readiness probes.
+func newCommands(flags commandFlags, proxy string) (commands []Command, err error) {
+	for _, value := range flags.cmd {
+		commands = append(commands, parseCommandFlag(value))
+	}
+	if flags.config != "" {
+		fromConfig, err := readCommandConfig(flags.config)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, fromConfig...)
+	}
+	nameToIndex := make(map[string]int, len(commands))
+	for i, c := range commands {
+		if c.Name == "" || c.Cmd == "" {
+			return nil, fmt.Errorf("commands must have a name and a cmd, got name %q and cmd %q", c.Name, c.Cmd)
+		}
+		if _, ok := nameToIndex[c.Name]; ok {
+			return nil, fmt.Errorf("duplicate command name %q, use -cmd name=command to name commands", c.Name)
+		}
+		nameToIndex[c.Name] = i
+	}
+	for _, value := range flags.restart {
+		name, policy, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid -cmd-restart value %q, expected name=policy", value)
+		}
+		i, ok := nameToIndex[name]
+		if !ok {
+			return nil, fmt.Errorf("-cmd-restart: unknown command %q", name)
+		}
+		commands[i].Restart = policy
+	}
+	for _, value := range flags.ready {
+		// The name can be omitted for the default command, since probes can contain "=".
+		name, probe, ok := strings.Cut(value, "=")
+		i, isCommand := nameToIndex[name]
+		if !ok || !isCommand {
+			if i, isCommand = nameToIndex[defaultCommandName]; !isCommand {
+				return nil, fmt.Errorf("invalid -cmd-ready value %q, expected name=probe", value)
+			}
+			probe = value
+		}
+		commands[i].Ready = probe
+	}
+	for i := range commands {
+		if err = commands[i].parseRestart(); err != nil {
+			return nil, err
+		}
+		if commands[i].Ready == "" {
+			continue
+		}
+		if commands[i].ready, err = parseReadinessProbe(commands[i].Ready, proxy); err != nil {
+			return nil, fmt.Errorf("command %q: %w", commands[i].Name, err)
+		}
+	}
+	return commands, nil
+}
+
+var commandColors = []*color.Color{
+	color.New(color.FgCyan),
+	color.New(color.FgMagenta),
+	color.New(color.FgYellow),
+	color.New(color.FgBlue),
+	color.New(color.FgGreen),
+	color.New(color.FgRed),
+}
+
+// newCommandPrefixes returns the prefix to write before each line of output for each command.
+// If there's only one command, its output isn't prefixed.
+func newCommandPrefixes(commands []Command) (prefixes map[string]string) {
+	prefixes = make(map[string]string, len(commands))
+	if len(commands) < 2 {
+		return prefixes
+	}
+	var width int
+	for _, c := range commands {
+		width = max(width, len(c.Name))
+	}
+	for i, c := range commands {
+		prefixes[c.Name] = commandColors[i%len(commandColors)].Sprintf("%-*s |", width, c.Name) + " "
+	}
+	return prefixes
+}
+
+// prefixWriter writes a prefix at the start of each line.
+type prefixWriter struct {
+	m           *sync.Mutex
+	w           io.Writer
+	prefix      []byte
+	atLineStart bool
+}
+
+// outputMutex prevents lines written by different commands from being interleaved.
+var outputMutex = &sync.Mutex{}
+
+func newPrefixWriter(w io.Writer, prefix string) io.Writer {
+	if prefix == "" {
+		return w
+	}
+	return &prefixWriter{
+		m:           outputMutex,
+		w:           w,
+		prefix:      []byte(prefix),
+		atLineStart: true,
+	}
+}
+
+func (pw *prefixWriter) Write(p []byte) (n int, err error) {
+	pw.m.Lock()
+	defer pw.m.Unlock()
+	var buf bytes.Buffer
+	for line := range bytes.Lines(p) {
+		if pw.atLineStart {
+			buf.Write(pw.prefix)
+		}
+		buf.Write(line)
+		pw.atLineStart = line[len(line)-1] == '\n'
+	}
+	if _, err = pw.w.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
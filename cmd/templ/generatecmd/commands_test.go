@@ -0,0 +1,105 @@
+package generatecmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCommandNeedsRestart(t *testing.T) {
+	commands, err := newCommands(commandFlags{cmd: []string{
+		"server=go run .",
+		"tailwind=npx @tailwindcss/cli --watch",
+		`esbuild=npx esbuild --bundle`,
+	}, restart: []string{"tailwind=never", `esbuild=pattern:\.ts$`}}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claimed := claimedByPattern(commands)
+	tests := []struct {
+		name     string
+		event    *GenerationEvent
+		expected map[string]bool
+	}{
+		{
+			name:     "Go file changes restart the server",
+			event:    &GenerationEvent{WatchedFileUpdated: true, UpdatedFiles: []string{"main.go"}},
+			expected: map[string]bool{"server": true},
+		},
+		{
+			name:     "Go code changes in templ files restart the server",
+			event:    &GenerationEvent{TemplFileGoUpdated: true, UpdatedFiles: []string{"index.templ"}},
+			expected: map[string]bool{"server": true},
+		},
+		{
+			name:     "Text changes in templ files don't restart commands",
+			event:    &GenerationEvent{TemplFileTextUpdated: true, UpdatedFiles: []string{"index.templ"}},
+			expected: map[string]bool{},
+		},
+		{
+			name:     "hot swapped CSS changes don't restart commands",
+			event:    &GenerationEvent{CSSFileUpdated: true, UpdatedFiles: []string{"styles.css"}},
+			expected: map[string]bool{},
+		},
+		{
+			name:     "CSS changes restart the server when CSS isn't hot swapped",
+			event:    &GenerationEvent{WatchedFileUpdated: true, UpdatedFiles: []string{"styles.css"}},
+			expected: map[string]bool{"server": true},
+		},
+		{
+			name:     "files that match a restart pattern only restart that command",
+			event:    &GenerationEvent{WatchedFileUpdated: true, UpdatedFiles: []string{"app.ts"}},
+			expected: map[string]bool{"esbuild": true},
+		},
+		{
+			name:     "changes to multiple files can restart multiple commands",
+			event:    &GenerationEvent{WatchedFileUpdated: true, UpdatedFiles: []string{"app.ts", "main.go"}},
+			expected: map[string]bool{"server": true, "esbuild": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range commands {
+				if actual := c.needsRestart(tt.event, claimed); actual != tt.expected[c.Name] {
+					t.Errorf("%s: expected needsRestart to be %v, got %v", c.Name, tt.expected[c.Name], actual)
+				}
+			}
+		})
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := newPrefixWriter(&buf, "a | ")
+	for _, s := range []string{"line 1\nline", " 2\n", "\n", "line 3"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	expected := "a | line 1\na | line 2\na | \na | line 3"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestDeprecatedCommand(t *testing.T) {
+	g, err := NewGenerate(nil, Arguments{
+		Command:  "go run .",
+		Commands: []Command{{Name: "tailwind", Cmd: "npx @tailwindcss/cli --watch"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.Args.Commands) != 2 {
+		t.Fatalf("expected 2 commands, got %d", len(g.Args.Commands))
+	}
+	c := g.Args.Commands[0]
+	if c.Name != defaultCommandName || c.Cmd != "go run ." || c.Restart != RestartOnGoChange {
+		t.Errorf("expected the command to be added to Commands, got %+v", c)
+	}
+	if _, err = NewGenerate(nil, Arguments{
+		Command:  "go run .",
+		Commands: []Command{{Name: defaultCommandName, Cmd: "go run ./other"}},
+	}); err == nil {
+		t.Error("expected an error for a duplicate command name")
+	}
+}
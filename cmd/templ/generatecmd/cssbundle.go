@@ -0,0 +1,88 @@
+package generatecmd
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/a-h/templ/generator"
+)
+
+// cssBundle collects the constant css templates of every templ file, and writes them to a single
+// stylesheet.
+type cssBundle struct {
+	fileName string
+	writer   FileWriterFunc
+
+	m sync.Mutex
+	// templFileNameToClasses is the CSS generated for each templ file.
+	templFileNameToClasses map[string][]generator.CSSBundleClass
+	// written is the content last written to the stylesheet.
+	written []byte
+}
+
+func newCSSBundle(fileName string, writer FileWriterFunc) *cssBundle {
+	b := &cssBundle{
+		fileName:               fileName,
+		writer:                 writer,
+		templFileNameToClasses: map[string][]generator.CSSBundleClass{},
+	}
+	// Avoid rewriting the file if it's up to date.
+	b.written, _ = os.ReadFile(fileName)
+	return b
+}
+
+// set the classes generated for the templ file.
+func (b *cssBundle) set(templFileName string, classes []generator.CSSBundleClass) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.templFileNameToClasses[templFileName] = classes
+}
+
+// delete the classes of a templ file that has been deleted. It returns true if the file had
+// classes, so the stylesheet needs to be written.
+func (b *cssBundle) delete(templFileName string) (deleted bool) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	deleted = len(b.templFileNameToClasses[templFileName]) > 0
+	delete(b.templFileNameToClasses, templFileName)
+	return deleted
+}
+
+// write the stylesheet, if it has changed since it was last written.
+func (b *cssBundle) write() (written bool, err error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	contents := b.contents()
+	if b.written != nil && bytes.Equal(b.written, contents) {
+		return false, nil
+	}
+	if err = b.writer(b.fileName, contents); err != nil {
+		return false, fmt.Errorf("failed to write CSS bundle %q: %w", b.fileName, err)
+	}
+	b.written = contents
+	return true, nil
+}
+
+// contents returns the classes, sorted by ID so that the output is stable. Classes with the
+// same name and CSS have the same ID, so they're only written once.
+func (b *cssBundle) contents() []byte {
+	var classes []generator.CSSBundleClass
+	for _, c := range b.templFileNameToClasses {
+		classes = append(classes, c...)
+	}
+	slices.SortFunc(classes, func(a, b generator.CSSBundleClass) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	classes = slices.Compact(classes)
+	var buf bytes.Buffer
+	buf.WriteString("/* Code generated by templ - DO NOT EDIT. */\n\n")
+	for _, c := range classes {
+		buf.WriteString(c.Class)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,213 @@
+// Package dotenv reads environment variables from .env files.
+//
+// Each line of a file is a KEY=VALUE assignment, optionally prefixed with "export". Blank lines,
+// and lines that start with #, are ignored. Values can be:
+//
+//   - Unquoted: surrounding whitespace is trimmed, and a # preceded by whitespace starts a comment.
+//   - Single quoted: the value is used as-is, and can span multiple lines.
+//   - Double quoted: \n, \r, \t, \", \\ and \$ escapes are processed, and the value can span multiple lines.
+//
+// In unquoted and double quoted values, $KEY and ${KEY} are replaced with the value of the
+// variable, if it was set earlier in the files, or in the environment.
+package dotenv
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Var is an environment variable.
+type Var struct {
+	Key   string
+	Value string
+}
+
+func (v Var) String() string {
+	return v.Key + "=" + v.Value
+}
+
+// Load the files in order. Variables in later files override variables in earlier files.
+func Load(fileNames ...string) (vars []Var, err error) {
+	keyToIndex := map[string]int{}
+	lookup := func(key string) (value string, ok bool) {
+		if i, ok := keyToIndex[key]; ok {
+			return vars[i].Value, true
+		}
+		return os.LookupEnv(key)
+	}
+	for _, fileName := range fileNames {
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read env file: %w", err)
+		}
+		fileVars, err := parse(string(data), lookup)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		for _, v := range fileVars {
+			if i, ok := keyToIndex[v.Key]; ok {
+				vars[i] = v
+				continue
+			}
+			keyToIndex[v.Key] = len(vars)
+			vars = append(vars, v)
+		}
+	}
+	return vars, nil
+}
+
+// Parse the content of a .env file.
+func Parse(s string) (vars []Var, err error) {
+	return parse(s, os.LookupEnv)
+}
+
+var keyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+func parse(s string, lookup func(key string) (string, bool)) (vars []Var, err error) {
+	// Variables defined earlier in the same file can be referenced by later variables.
+	fileVars := map[string]string{}
+	getValue := func(key string) string {
+		if v, ok := fileVars[key]; ok {
+			return v
+		}
+		v, _ := lookup(key)
+		return v
+	}
+	r := &reader{s: bufio.NewScanner(strings.NewReader(s))}
+	for r.next() {
+		line := strings.TrimSpace(r.line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE, got %q", r.lineNumber, line)
+		}
+		key = strings.TrimSpace(key)
+		if !keyRegexp.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid key %q", r.lineNumber, key)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, "'"):
+			if value, err = r.quoted(value, '\''); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(value, `"`):
+			if value, err = r.quoted(value, '"'); err != nil {
+				return nil, err
+			}
+			value = expand(value, true, getValue)
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+			value = expand(value, false, getValue)
+		}
+		fileVars[key] = value
+		vars = append(vars, Var{Key: key, Value: value})
+	}
+	if err = r.s.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+type reader struct {
+	s          *bufio.Scanner
+	line       string
+	lineNumber int
+}
+
+func (r *reader) next() bool {
+	if !r.s.Scan() {
+		return false
+	}
+	r.line = r.s.Text()
+	r.lineNumber++
+	return true
+}
+
+// quoted returns the content of a quoted value, which starts with the quote character, reading
+// further lines until the closing quote is found. Anything after the closing quote is ignored.
+func (r *reader) quoted(value string, quote byte) (string, error) {
+	startLine := r.lineNumber
+	value = value[1:]
+	var sb strings.Builder
+	for {
+		if end := closingQuote(value, quote); end >= 0 {
+			sb.WriteString(value[:end])
+			return sb.String(), nil
+		}
+		sb.WriteString(value)
+		sb.WriteByte('\n')
+		if !r.next() {
+			return "", fmt.Errorf("line %d: unterminated quoted value", startLine)
+		}
+		value = r.line
+	}
+}
+
+// closingQuote returns the index of the closing quote, or -1 if not found. In double quoted
+// values, quotes can be escaped with a backslash.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		if quote == '"' && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+var escapes = map[byte]byte{'n': '\n', 'r': '\r', 't': '\t', '"': '"', '\\': '\\', '$': '$'}
+
+// expand replaces $KEY and ${KEY} with the value returned by getValue. If unescape is true,
+// backslash escapes are processed, so \$ can be used to write a literal $.
+func expand(s string, unescape bool, getValue func(key string) string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case unescape && s[i] == '\\' && i+1 < len(s):
+			if c, ok := escapes[s[i+1]]; ok {
+				sb.WriteByte(c)
+				i++
+				continue
+			}
+			sb.WriteByte(s[i])
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				sb.WriteString(s[i:])
+				return sb.String()
+			}
+			sb.WriteString(getValue(s[i+2 : i+end]))
+			i += end
+		case s[i] == '$':
+			end := i + 1
+			for end < len(s) && isKeyByte(s[end], end == i+1) {
+				end++
+			}
+			if end == i+1 {
+				sb.WriteByte(s[i])
+				continue
+			}
+			sb.WriteString(getValue(s[i+1 : end]))
+			i = end - 1
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+func isKeyByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (!first && c >= '0' && c <= '9')
+}
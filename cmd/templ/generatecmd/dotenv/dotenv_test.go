@@ -0,0 +1,133 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	t.Setenv("TEMPL_DOTENV_TEST", "from-env")
+	tests := []struct {
+		name     string
+		input    string
+		expected []Var
+		err      bool
+	}{
+		{
+			name:     "empty files have no variables",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "comments and blank lines are ignored",
+			input:    "# comment\n\n  # indented comment\nKEY=value\n",
+			expected: []Var{{Key: "KEY", Value: "value"}},
+		},
+		{
+			name:     "the export prefix is ignored",
+			input:    "export KEY=value",
+			expected: []Var{{Key: "KEY", Value: "value"}},
+		},
+		{
+			name:     "whitespace around keys and values is trimmed",
+			input:    "  KEY =  value  ",
+			expected: []Var{{Key: "KEY", Value: "value"}},
+		},
+		{
+			name:     "empty values are allowed",
+			input:    "KEY=",
+			expected: []Var{{Key: "KEY", Value: ""}},
+		},
+		{
+			name:     "unquoted values can have trailing comments",
+			input:    "KEY=value # comment\nURL=http://localhost/#fragment",
+			expected: []Var{{Key: "KEY", Value: "value"}, {Key: "URL", Value: "http://localhost/#fragment"}},
+		},
+		{
+			name:     "single quoted values are literal",
+			input:    `KEY='$HOME\n # not a comment'`,
+			expected: []Var{{Key: "KEY", Value: `$HOME\n # not a comment`}},
+		},
+		{
+			name:     "double quoted values process escapes",
+			input:    `KEY="a\tb\nc \"quoted\" \\ \$HOME"`,
+			expected: []Var{{Key: "KEY", Value: "a\tb\nc \"quoted\" \\ $HOME"}},
+		},
+		{
+			name:     "quoted values can span multiple lines",
+			input:    "KEY=\"line 1\nline 2\"\nOTHER='a\nb'",
+			expected: []Var{{Key: "KEY", Value: "line 1\nline 2"}, {Key: "OTHER", Value: "a\nb"}},
+		},
+		{
+			name:     "variables can reference earlier variables and the environment",
+			input:    "HOST=localhost\nURL=http://${HOST}:8080\nFROM_ENV=\"$TEMPL_DOTENV_TEST\"\nMISSING=${TEMPL_DOTENV_TEST_MISSING}",
+			expected: []Var{{Key: "HOST", Value: "localhost"}, {Key: "URL", Value: "http://localhost:8080"}, {Key: "FROM_ENV", Value: "from-env"}, {Key: "MISSING", Value: ""}},
+		},
+		{
+			name:  "lines without = are an error",
+			input: "KEY",
+			err:   true,
+		},
+		{
+			name:  "invalid keys are an error",
+			input: "MY KEY=value",
+			err:   true,
+		},
+		{
+			name:  "unterminated quotes are an error",
+			input: "KEY=\"value\nOTHER=value",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := Parse(tt.input)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected error, got %v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	if err := os.WriteFile(base, []byte("HOST=localhost\nPORT=8080\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(local, []byte("PORT=9090\nURL=http://$HOST:$PORT\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	actual, err := Load(base, local)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Var{
+		{Key: "HOST", Value: "localhost"},
+		{Key: "PORT", Value: "9090"},
+		{Key: "URL", Value: "http://localhost:9090"},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Run("missing files are an error", func(t *testing.T) {
+		if _, err := Load(filepath.Join(dir, ".env.missing")); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
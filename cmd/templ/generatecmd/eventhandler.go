@@ -21,6 +21,7 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/a-h/templ/cmd/templ/generatecmd/jsonevents"
 	"github.com/a-h/templ/cmd/templ/visualize"
 	"github.com/a-h/templ/generator"
 	"github.com/a-h/templ/internal/syncmap"
@@ -111,6 +112,15 @@ type FSEventHandler struct {
 	keepOrphanedFiles     bool
 	writer                FileWriterFunc
 	lazy                  bool
+	// Events receives the results of generating code for templ files, if set.
+	Events *jsonevents.Stream
+	// typeChecker holds generated code until it has been type checked, if set.
+	typeChecker *typeChecker
+	// cssBundle collects the CSS of constant css templates, if set.
+	cssBundle *cssBundle
+	// CSSHotSwap is true if changes to CSS files are applied in the browser, instead of
+	// restarting the commands. Apps that embed their CSS files need to be restarted.
+	CSSHotSwap bool
 }
 
 type GenerateResult struct {
@@ -122,24 +132,26 @@ type GenerateResult struct {
 	TemplFileTextUpdated bool
 	// TemplFileGoUpdated indicates that Go expressions were updated.
 	TemplFileGoUpdated bool
+	// CSSFileUpdated indicates that a CSS file matching the watch pattern was updated, and that
+	// it can be swapped in the browser, so the command doesn't need to be restarted.
+	CSSFileUpdated bool
 }
 
 func (h *FSEventHandler) HandleEvent(ctx context.Context, event fsnotify.Event) (result GenerateResult, err error) {
 	// Handle _templ.go files.
 	if !event.Has(fsnotify.Remove) && strings.HasSuffix(event.Name, "_templ.go") {
-		_, err = os.Stat(strings.TrimSuffix(event.Name, "_templ.go") + ".templ")
+		templFileName := strings.TrimSuffix(event.Name, "_templ.go") + ".templ"
+		_, err = os.Stat(templFileName)
 		if !os.IsNotExist(err) {
 			return GenerateResult{}, err
 		}
-		// File is orphaned.
-		if h.keepOrphanedFiles {
-			return GenerateResult{}, nil
-		}
-		h.Log.Debug("Deleting orphaned Go file", slog.String("file", event.Name))
-		if err = os.Remove(event.Name); err != nil {
-			h.Log.Warn("Failed to remove orphaned file", slog.Any("error", err))
+		return h.handleOrphanedFile(templFileName, event.Name), nil
+	}
+	// Templ files that have been deleted or renamed orphan their Go files.
+	if (event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)) && strings.HasSuffix(event.Name, ".templ") {
+		if _, err = os.Stat(event.Name); os.IsNotExist(err) {
+			return h.handleOrphanedFile(event.Name, strings.TrimSuffix(event.Name, ".templ")+"_templ.go"), nil
 		}
-		return GenerateResult{WatchedFileUpdated: false, TemplFileGoUpdated: true, TemplFileTextUpdated: false}, nil
 	}
 
 	// If the file hasn't been updated since the last time we processed it, ignore it.
@@ -153,6 +165,9 @@ func (h *FSEventHandler) HandleEvent(ctx context.Context, event fsnotify.Event)
 	updatedModTime := h.fileNameToLastModTime.CompareAndSwap(event.Name, mustBeInTheFuture, fileInfo.ModTime())
 	if !updatedModTime {
 		h.Log.Debug("Skipping file because it wasn't updated", slog.String("file", event.Name))
+		if strings.HasSuffix(event.Name, ".templ") {
+			h.Events.Publish(jsonevents.Event{Type: jsonevents.Skipped, File: event.Name, Reason: jsonevents.SkippedUnchanged})
+		}
 		return GenerateResult{}, nil
 	}
 
@@ -161,6 +176,10 @@ func (h *FSEventHandler) HandleEvent(ctx context.Context, event fsnotify.Event)
 		if h.devMode {
 			h.Log.Info("Watched file updated", slog.String("file", event.Name))
 		}
+		if h.CSSHotSwap && strings.HasSuffix(event.Name, ".css") {
+			result.CSSFileUpdated = true
+			return result, nil
+		}
 		result.WatchedFileUpdated = true
 		return result, nil
 	}
@@ -170,6 +189,7 @@ func (h *FSEventHandler) HandleEvent(ctx context.Context, event fsnotify.Event)
 	// If the go file is newer than the templ file, skip generation, because it's up-to-date.
 	if h.lazy && goFileIsUpToDate(event.Name, fileInfo.ModTime()) {
 		h.Log.Debug("Skipping file because the Go file is up-to-date", slog.String("file", event.Name))
+		h.Events.Publish(jsonevents.Event{Type: jsonevents.Skipped, File: event.Name, Reason: jsonevents.SkippedUpToDate})
 		return GenerateResult{}, nil
 	}
 
@@ -179,25 +199,78 @@ func (h *FSEventHandler) HandleEvent(ctx context.Context, event fsnotify.Event)
 	result, diag, err = h.generate(ctx, event.Name)
 	if err != nil {
 		h.fileNameToError.Set(event.Name)
+		h.Events.Publish(newErrorEvent(event.Name, err))
 		return result, fmt.Errorf("failed to generate code for %q: %w", event.Name, err)
 	}
+	if errorCleared := h.fileNameToError.Delete(event.Name); errorCleared {
+		h.Log.Info("Error cleared", slog.String("file", event.Name), slog.Int("errors", h.fileNameToError.Count()))
+	}
+	h.Events.Publish(jsonevents.Event{
+		Type:          jsonevents.Generated,
+		File:          event.Name,
+		GoFileWritten: result.GoFileWritten,
+		DurationMS:    time.Since(start).Milliseconds(),
+	})
 	if len(diag) > 0 {
 		for _, d := range diag {
 			h.Log.Warn(d.Message,
 				slog.String("from", fmt.Sprintf("%d:%d", d.Range.From.Line, d.Range.From.Col)),
 				slog.String("to", fmt.Sprintf("%d:%d", d.Range.To.Line, d.Range.To.Col)),
 			)
+			// Diagnostic positions are zero based.
+			h.Events.Publish(jsonevents.Event{
+				Type:    jsonevents.Diagnostic,
+				File:    event.Name,
+				Message: d.Message,
+				Range: &jsonevents.Range{
+					From: jsonevents.Position{Line: int(d.Range.From.Line) + 1, Col: int(d.Range.From.Col) + 1},
+					To:   jsonevents.Position{Line: int(d.Range.To.Line) + 1, Col: int(d.Range.To.Col) + 1},
+				},
+			})
 		}
 		return result, nil
 	}
-	if errorCleared := h.fileNameToError.Delete(event.Name); errorCleared {
-		h.Log.Info("Error cleared", slog.String("file", event.Name), slog.Int("errors", h.fileNameToError.Count()))
-	}
 	h.Log.Debug("Generated code", slog.String("file", event.Name), slog.Duration("in", time.Since(start)))
 
 	return result, nil
 }
 
+// newErrorEvent creates an error event, with the position of the error, if it has one.
+func newErrorEvent(fileName string, err error) (e jsonevents.Event) {
+	e = jsonevents.Event{Type: jsonevents.Error, File: fileName, Message: err.Error()}
+	if msg, line, col, ok := errorPosition(err); ok {
+		e.Message = msg
+		pos := jsonevents.Position{Line: line, Col: col}
+		e.Range = &jsonevents.Range{From: pos, To: pos}
+	}
+	return e
+}
+
+// HasError returns true if the last attempt to generate code for the file failed.
+func (h *FSEventHandler) HasError(fileName string) bool {
+	return h.fileNameToError.Get(fileName)
+}
+
+// handleOrphanedFile removes the CSS of a templ file that no longer exists from the CSS bundle,
+// and deletes its Go file, unless orphaned files are kept.
+func (h *FSEventHandler) handleOrphanedFile(templFileName, goFileName string) (result GenerateResult) {
+	if h.cssBundle != nil && h.cssBundle.delete(templFileName) {
+		result.TemplFileGoUpdated = true
+	}
+	if h.keepOrphanedFiles {
+		return result
+	}
+	h.Log.Debug("Deleting orphaned Go file", slog.String("file", goFileName))
+	if err := os.Remove(goFileName); err != nil {
+		if !os.IsNotExist(err) {
+			h.Log.Warn("Failed to remove orphaned file", slog.Any("error", err))
+		}
+		return result
+	}
+	result.TemplFileGoUpdated = true
+	return result
+}
+
 func goFileIsUpToDate(templFileName string, templFileLastMod time.Time) (upToDate bool) {
 	goFileName := strings.TrimSuffix(templFileName, ".templ") + "_templ.go"
 	goFileInfo, err := os.Stat(goFileName)
@@ -234,6 +307,10 @@ func (h *FSEventHandler) generate(ctx context.Context, fileName string) (result
 		return GenerateResult{}, nil, fmt.Errorf("%s generation error: %w", fileName, err)
 	}
 
+	if h.cssBundle != nil {
+		h.cssBundle.set(fileName, generatorOutput.CSSBundle)
+	}
+
 	formattedGoCode, err := format.Source(b.Bytes())
 	if err != nil {
 		err = remapErrorList(err, generatorOutput.SourceMap, fileName)
@@ -249,7 +326,10 @@ func (h *FSEventHandler) generate(ctx context.Context, fileName string) (result
 	}
 	if h.hashes.CompareAndSwap(targetFileName, syncmap.UpdateIfChanged, goCodeHash) {
 		result.GoFileWritten = true
-		if err = h.writer(targetFileName, formattedGoCode); err != nil {
+		if h.typeChecker != nil {
+			// The file is written after it has been type checked.
+			h.typeChecker.add(fileName, targetFileName, formattedGoCode, b.Bytes(), generatorOutput.SourceMap)
+		} else if err = h.writer(targetFileName, formattedGoCode); err != nil {
 			return result, nil, fmt.Errorf("failed to write target file %q: %w", targetFileName, err)
 		}
 	}
@@ -0,0 +1,168 @@
+// Package jsonevents publishes the events that happen while templ generate is running as
+// newline delimited JSON, so that they can be consumed by other tools.
+package jsonevents
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Type of event.
+type Type string
+
+const (
+	// Generated is published when Go code is generated for a templ file.
+	Generated Type = "generated"
+	// Skipped is published when a templ file is skipped, because it hasn't changed.
+	Skipped Type = "skipped"
+	// Error is published when code can't be generated for a templ file, e.g. due to a parse error.
+	Error Type = "error"
+	// Diagnostic is published for each warning found in a templ file.
+	Diagnostic Type = "diagnostic"
+	// CommandStarted is published when a command is started, or restarted.
+	CommandStarted Type = "commandStarted"
+	// CommandExited is published when a command exits by itself, rather than being stopped.
+	CommandExited Type = "commandExited"
+	// Reload is published when the proxy tells the browser to reload.
+	Reload Type = "reload"
+	// CSSReload is published when the proxy tells the browser to reload a stylesheet.
+	CSSReload Type = "cssReload"
+)
+
+// Reasons that a file was skipped.
+const (
+	// SkippedUnchanged means that the file hasn't been modified since it was last processed.
+	SkippedUnchanged = "unchanged"
+	// SkippedUpToDate means that the generated Go file is newer than the templ file.
+	SkippedUpToDate = "upToDate"
+)
+
+// Event is a single line of the stream. Fields that don't apply to the type of event are omitted.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type Type      `json:"type"`
+	// File is the absolute path of the file that the event relates to.
+	File string `json:"file,omitempty"`
+	// Message describes an error or diagnostic.
+	Message string `json:"message,omitempty"`
+	// Range is the location of an error or diagnostic in the file.
+	Range *Range `json:"range,omitempty"`
+	// Reason a file was skipped.
+	Reason string `json:"reason,omitempty"`
+	// GoFileWritten is true if the generated code changed, and was written.
+	GoFileWritten bool `json:"goFileWritten,omitempty"`
+	// DurationMS is the time taken to generate code, in milliseconds.
+	DurationMS int64 `json:"durationMs,omitempty"`
+	// Name of the command.
+	Name string `json:"name,omitempty"`
+	// Cmd is the command that was executed.
+	Cmd string `json:"cmd,omitempty"`
+	// ExitCode of the command, if it exited.
+	ExitCode *int `json:"exitCode,omitempty"`
+}
+
+// Range in a file. Lines and columns are one based.
+type Range struct {
+	From Position `json:"from"`
+	To   Position `json:"to"`
+}
+
+// Position in a file. Lines and columns are one based.
+type Position struct {
+	Line int `json:"line"`
+	Col  int `json:"col"`
+}
+
+// subscriberBufferSize is the number of events that can be queued for a subscriber. If a
+// subscriber doesn't read events quickly enough, further events are dropped.
+const subscriberBufferSize = 256
+
+// New creates a stream that writes events to w, which may be nil, and to subscribers.
+func New(w io.Writer) *Stream {
+	return &Stream{
+		w:           w,
+		subscribers: map[chan []byte]struct{}{},
+		now:         time.Now,
+	}
+}
+
+// Stream of events.
+type Stream struct {
+	m           sync.Mutex
+	w           io.Writer
+	subscribers map[chan []byte]struct{}
+	now         func() time.Time
+}
+
+// Publish the event. If the time isn't set, the current time is used. Publishing to a nil
+// stream does nothing.
+func (s *Stream) Publish(e Event) {
+	if s == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if e.Time.IsZero() {
+		e.Time = s.now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	if s.w != nil {
+		_, _ = s.w.Write(line)
+	}
+	for events := range s.subscribers {
+		select {
+		case events <- line:
+		default:
+		}
+	}
+}
+
+func (s *Stream) subscribe() (events chan []byte, unsubscribe func()) {
+	events = make(chan []byte, subscriberBufferSize)
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.subscribers[events] = struct{}{}
+	return events, func() {
+		s.m.Lock()
+		defer s.m.Unlock()
+		delete(s.subscribers, events)
+	}
+}
+
+// ServeHTTP streams events published after the request is received as newline delimited JSON.
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	events, unsubscribe := s.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	for {
+		select {
+		case line := <-events:
+			if _, err := w.Write(line); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
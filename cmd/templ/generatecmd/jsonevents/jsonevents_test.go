@@ -0,0 +1,112 @@
+package jsonevents
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPublish(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	exitCode := 1
+	tests := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{
+			name:     "generated files include whether the Go file was written",
+			event:    Event{Type: Generated, File: "/app/index.templ", GoFileWritten: true, DurationMS: 3},
+			expected: `{"time":"2025-01-02T03:04:05Z","type":"generated","file":"/app/index.templ","goFileWritten":true,"durationMs":3}`,
+		},
+		{
+			name:     "errors include the range",
+			event:    Event{Type: Error, File: "/app/index.templ", Message: "unexpected EOF", Range: &Range{From: Position{Line: 2, Col: 5}, To: Position{Line: 2, Col: 5}}},
+			expected: `{"time":"2025-01-02T03:04:05Z","type":"error","file":"/app/index.templ","message":"unexpected EOF","range":{"from":{"line":2,"col":5},"to":{"line":2,"col":5}}}`,
+		},
+		{
+			name:     "exited commands include the exit code",
+			event:    Event{Type: CommandExited, Name: "server", Cmd: "go run .", ExitCode: &exitCode},
+			expected: `{"time":"2025-01-02T03:04:05Z","type":"commandExited","name":"server","cmd":"go run .","exitCode":1}`,
+		},
+		{
+			name:     "the time can be set",
+			event:    Event{Type: Reload, Time: now.Add(time.Hour)},
+			expected: `{"time":"2025-01-02T04:04:05Z","type":"reload"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			s := New(&buf)
+			s.now = func() time.Time { return now }
+			s.Publish(tt.event)
+			if diff := cmp.Diff(tt.expected+"\n", buf.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+	t.Run("publishing to a nil stream does nothing", func(t *testing.T) {
+		var s *Stream
+		s.Publish(Event{Type: Reload})
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := New(nil)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	// The response headers are flushed after the subscription is made, so events published
+	// now are received.
+	s.Publish(Event{Type: Generated, File: "a.templ"})
+	s.Publish(Event{Type: Reload})
+
+	scanner := bufio.NewScanner(resp.Body)
+	var actual []Type
+	for len(actual) < 2 && scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("failed to unmarshal %q: %v", scanner.Text(), err)
+		}
+		actual = append(actual, e.Type)
+	}
+	if diff := cmp.Diff([]Type{Generated, Reload}, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Run("only GET is allowed", func(t *testing.T) {
+		resp, err := http.Post(server.URL, "application/json", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+		}
+	})
+}
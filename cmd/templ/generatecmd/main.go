@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"regexp"
 	"runtime"
+	"time"
 
 	_ "net/http/pprof"
 
@@ -33,17 +34,43 @@ Args:
     Set to false to skip inclusion of the templ version in the generated code. (default true)
   -include-timestamp
     Set to true to include the current time in the generated code.
+  -css-bundle <file>
+    Writes the CSS of css templates that don't use Go expressions to a single
+    stylesheet, instead of rendering it in <style> elements, e.g. -css-bundle static/templ.css
   -watch
     Set to true to watch the path for changes and regenerate code.
   -watch-pattern <regexp>
     Set the regexp pattern of files that will be watched for changes. (default: '(.+\.go$)|(.+\.templ$)|(.+_templ\.txt$)')
   -ignore-pattern <regexp>
     Set the regexp pattern of files to ignore when watching for changes. (default: '')
+  -css-hot-swap
+    Set to true to apply changes to watched *.css files in the browser, without
+    restarting the command or reloading the page. Only use it if the app serves
+    the CSS files from disk, rather than embedding them. (default false)
   -open-browser
     Set to false to prevent the browser from opening when using the -proxy flag. (default true)
   -cmd <cmd>
     Set the command to run after generating code. The command is executed via
     the system shell ($SHELL on Unix, %COMSPEC% on Windows).
+    Repeat the flag with name=<cmd> to run multiple named commands, e.g.
+    -cmd server="go run ." -cmd tailwind="npx @tailwindcss/cli -o styles.css --watch"
+  -cmd-restart <name>=<policy>
+    Set when the named command is restarted: "go" to restart when Go code changes,
+    "never", or "pattern:<regexp>" to restart when a watched file matching the
+    regexp changes. (default go)
+  -cmd-ready [<name>=]<probe>
+    Wait for the command to be ready before reloading the browser. The probe is
+    "http:<path or URL>" to wait for a 2xx response (paths are relative to -proxy),
+    "tcp:<address>" to wait for a port to accept connections, or "log:<regexp>"
+    to wait for a line of output that matches the regexp.
+  -cmd-ready-timeout <duration>
+    The maximum time to wait for commands to be ready. (default 30s)
+  -cmd-config <file>
+    Read commands to run from a JSON file.
+  -env-file <file>
+    Load environment variables for the commands from a dotenv file. Repeat the
+    flag to load multiple files, later files override earlier ones. In watch
+    mode, the commands are restarted when the files change.
   -proxy
     Set the URL to proxy after generating code and executing the command.
   -proxyport
@@ -64,6 +91,12 @@ Args:
     Port to run the pprof server on.
   -keep-orphaned-files
     Keeps orphaned generated templ files. (default false)
+  -typecheck
+    Type checks the generated code before writing it, and reports errors at
+    their position in the templ file. Files in packages with errors aren't written.
+  -json-events
+    Writes events, e.g. generated files, errors, and command restarts, to stdout
+    as newline delimited JSON. The output of commands is written to stderr.
   -check
     Checks that generated files are up to date, without writing changes.
     Returns a non-zero exit code if any files need regenerating.
@@ -95,6 +128,8 @@ Examples:
 
 const defaultWatchPattern = `(.+\.go$)|(.+\.templ$)`
 
+const defaultReadyTimeout = 30 * time.Second
+
 func NewArguments(stdout, stderr io.Writer, args []string) (cmdArgs Arguments, log *slog.Logger, help bool, err error) {
 	cmd := flag.NewFlagSet("generate", flag.ContinueOnError)
 	cmd.StringVar(&cmdArgs.FileName, "f", "", "")
@@ -103,11 +138,20 @@ func NewArguments(stdout, stderr io.Writer, args []string) (cmdArgs Arguments, l
 	cmd.BoolVar(&cmdArgs.GenerateSourceMapVisualisations, "source-map-visualisations", false, "")
 	cmd.BoolVar(&cmdArgs.IncludeVersion, "include-version", true, "")
 	cmd.BoolVar(&cmdArgs.IncludeTimestamp, "include-timestamp", false, "")
+	cmd.StringVar(&cmdArgs.CSSBundle, "css-bundle", "", "")
 	cmd.BoolVar(&cmdArgs.Watch, "watch", false, "")
 	watchPatternFlag := cmd.String("watch-pattern", defaultWatchPattern, "")
 	ignorePatternFlag := cmd.String("ignore-pattern", "", "")
+	cmd.BoolVar(&cmdArgs.CSSHotSwap, "css-hot-swap", false, "")
 	cmd.BoolVar(&cmdArgs.OpenBrowser, "open-browser", true, "")
-	cmd.StringVar(&cmdArgs.Command, "cmd", "", "")
+	var cmdFlags commandFlags
+	cmd.Var(&cmdFlags.cmd, "cmd", "")
+	cmd.Var(&cmdFlags.restart, "cmd-restart", "")
+	cmd.Var(&cmdFlags.ready, "cmd-ready", "")
+	cmd.DurationVar(&cmdArgs.ReadyTimeout, "cmd-ready-timeout", defaultReadyTimeout, "")
+	cmd.StringVar(&cmdFlags.config, "cmd-config", "", "")
+	var envFilesFlag stringSliceFlag
+	cmd.Var(&envFilesFlag, "env-file", "")
 	cmd.StringVar(&cmdArgs.Proxy, "proxy", "", "")
 	cmd.IntVar(&cmdArgs.ProxyPort, "proxyport", 7331, "")
 	cmd.StringVar(&cmdArgs.ProxyBind, "proxybind", "127.0.0.1", "")
@@ -118,7 +162,9 @@ func NewArguments(stdout, stderr io.Writer, args []string) (cmdArgs Arguments, l
 	cmd.IntVar(&cmdArgs.PPROFPort, "pprof", 0, "")
 	cmd.BoolVar(&cmdArgs.KeepOrphanedFiles, "keep-orphaned-files", false, "")
 	cmd.BoolVar(&cmdArgs.Lazy, "lazy", false, "")
+	cmd.BoolVar(&cmdArgs.TypeCheck, "typecheck", false, "")
 	cmd.BoolVar(&cmdArgs.Check, "check", false, "")
+	jsonEventsFlag := cmd.Bool("json-events", false, "")
 	verboseFlag := cmd.Bool("v", false, "")
 	logLevelFlag := cmd.String("log-level", "info", "")
 	helpFlag := cmd.Bool("help", false, "")
@@ -137,6 +183,18 @@ func NewArguments(stdout, stderr io.Writer, args []string) (cmdArgs Arguments, l
 	if cmdArgs.Check && *toStdoutFlag {
 		return Arguments{}, log, *helpFlag, fmt.Errorf("cannot use -check with -stdout")
 	}
+	if cmdArgs.CSSBundle != "" && cmdArgs.FileName != "" {
+		return Arguments{}, log, *helpFlag, fmt.Errorf("cannot use -css-bundle with -f, because the bundle contains the CSS of every file")
+	}
+	if cmdArgs.CSSBundle != "" && cmdArgs.Lazy {
+		return Arguments{}, log, *helpFlag, fmt.Errorf("cannot use -css-bundle with -lazy, because the bundle contains the CSS of every file")
+	}
+	if *jsonEventsFlag && *toStdoutFlag {
+		return Arguments{}, log, *helpFlag, fmt.Errorf("cannot use -json-events with -stdout")
+	}
+	if *jsonEventsFlag {
+		cmdArgs.JSONEvents = stdout
+	}
 	cmdArgs.WatchPattern, err = regexp.Compile(*watchPatternFlag)
 	if err != nil {
 		return cmdArgs, log, *helpFlag, fmt.Errorf("invalid watch pattern %q: %w", *watchPatternFlag, err)
@@ -148,6 +206,12 @@ func NewArguments(stdout, stderr io.Writer, args []string) (cmdArgs Arguments, l
 		}
 	}
 
+	cmdArgs.Commands, err = newCommands(cmdFlags, cmdArgs.Proxy)
+	if err != nil {
+		return Arguments{}, log, *helpFlag, err
+	}
+	cmdArgs.EnvFiles = envFilesFlag
+
 	// Default to writing to files unless the stdout flag is set.
 	cmdArgs.FileWriter = FileWriter
 	if *toStdoutFlag {
@@ -169,15 +233,24 @@ func NewArguments(stdout, stderr io.Writer, args []string) (cmdArgs Arguments, l
 }
 
 type Arguments struct {
-	FileName                        string
-	FileWriter                      FileWriterFunc
-	Path                            string
-	Check                           bool
-	Watch                           bool
-	WatchPattern                    *regexp.Regexp
-	IgnorePattern                   *regexp.Regexp
-	OpenBrowser                     bool
+	FileName      string
+	FileWriter    FileWriterFunc
+	Path          string
+	Check         bool
+	Watch         bool
+	WatchPattern  *regexp.Regexp
+	IgnorePattern *regexp.Regexp
+	// CSSHotSwap applies changes to watched CSS files in the browser, instead of restarting the
+	// commands, for apps that serve CSS files from disk.
+	CSSHotSwap  bool
+	OpenBrowser bool
+	// Command is run in watch mode.
+	//
+	// Deprecated: Use Commands. If set, it's added to Commands with the name "cmd".
 	Command                         string
+	Commands                        []Command
+	ReadyTimeout                    time.Duration
+	EnvFiles                        []string
 	ProxyBind                       string
 	ProxyPort                       int
 	Proxy                           string
@@ -188,10 +261,15 @@ type Arguments struct {
 	GenerateSourceMapVisualisations bool
 	IncludeVersion                  bool
 	IncludeTimestamp                bool
+	// CSSBundle is the file that the CSS of constant css templates is written to, if set.
+	CSSBundle string
 	// PPROFPort is the port to run the pprof server on.
 	PPROFPort         int
 	KeepOrphanedFiles bool
 	Lazy              bool
+	TypeCheck         bool
+	// JSONEvents receives events as newline delimited JSON, if set.
+	JSONEvents io.Writer
 }
 
 type ArgumentError struct {
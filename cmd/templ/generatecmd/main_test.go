@@ -4,15 +4,21 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"log/slog"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/a-h/templ/cmd/templ/testproject"
+	"github.com/a-h/templ/generator"
 	"github.com/a-h/templ/runtime"
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -160,6 +166,120 @@ func TestGenerate(t *testing.T) {
 	})
 }
 
+func TestCSSBundle(t *testing.T) {
+	dir := t.TempDir()
+	templates := `package main
+
+css red() {
+	color: red;
+}
+
+css colour(c string) {
+	color: { c };
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "a.templ"), []byte(templates), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The same class in another file is only written to the bundle once.
+	if err := os.WriteFile(filepath.Join(dir, "b.templ"), []byte("package main\n\ncss red() {\n\tcolor: red;\n}\n\ncss blue() {\n\tcolor: blue;\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bundleFileName := filepath.Join(dir, "templ.css")
+
+	err := Run(context.Background(), io.Discard, io.Discard, []string{"-path", dir, "-css-bundle", bundleFileName})
+	if err != nil {
+		t.Fatalf("failed to run generate command: %v", err)
+	}
+
+	bundle, err := os.ReadFile(bundleFileName)
+	if err != nil {
+		t.Fatalf("failed to read CSS bundle: %v", err)
+	}
+	expected := `/* Code generated by templ - DO NOT EDIT. */
+
+.blue_ec74676e{color:blue;}
+.red_050e5e03{color:red;}
+`
+	if diff := cmp.Diff(expected, string(bundle)); diff != "" {
+		t.Error(diff)
+	}
+	goCode, err := os.ReadFile(filepath.Join(dir, "a_templ.go"))
+	if err != nil {
+		t.Fatalf("failed to read generated code: %v", err)
+	}
+	if !strings.Contains(string(goCode), "templ.ConstantCSSClass(`red_050e5e03`)") {
+		t.Errorf("expected the bundled class to be referenced by name, got:\n%s", goCode)
+	}
+	if !strings.Contains(string(goCode), "templ.SanitizeCSS(`color`, c)") {
+		t.Errorf("expected css with expressions to be rendered inline, got:\n%s", goCode)
+	}
+
+	// The bundle is up to date, so check passes.
+	err = Run(context.Background(), io.Discard, io.Discard, []string{"-check", "-path", dir, "-css-bundle", bundleFileName})
+	if err != nil {
+		t.Fatalf("expected check to pass, got error: %v", err)
+	}
+	if err = os.Remove(bundleFileName); err != nil {
+		t.Fatal(err)
+	}
+	err = Run(context.Background(), io.Discard, io.Discard, []string{"-check", "-path", dir, "-css-bundle", bundleFileName})
+	if err == nil {
+		t.Fatal("expected check to fail when the CSS bundle is missing")
+	}
+}
+
+func TestCSSBundleDeletedFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTempl := func(name, contents string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTempl("a.templ", "package main\n\ncss red() {\n\tcolor: red;\n}\n")
+	writeTempl("b.templ", "package main\n\ncss blue() {\n\tcolor: blue;\n}\n")
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	bundleFileName := filepath.Join(dir, "templ.css")
+	fseh := NewFSEventHandler(log, dir, true, []generator.GenerateOpt{generator.WithCSSBundle()}, false, false, FileWriter, false)
+	fseh.cssBundle = newCSSBundle(bundleFileName, FileWriter)
+	for _, name := range []string{"a.templ", "b.templ"} {
+		if _, err := fseh.HandleEvent(context.Background(), fsnotify.Event{Name: filepath.Join(dir, name), Op: fsnotify.Create}); err != nil {
+			t.Fatalf("failed to generate %s: %v", name, err)
+		}
+	}
+
+	// Deleting the templ file removes its classes from the bundle, and deletes its Go file.
+	if err := os.Remove(filepath.Join(dir, "b.templ")); err != nil {
+		t.Fatal(err)
+	}
+	result, err := fseh.HandleEvent(context.Background(), fsnotify.Event{Name: filepath.Join(dir, "b.templ"), Op: fsnotify.Remove})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.TemplFileGoUpdated {
+		t.Error("expected the deleted file to update the generated code")
+	}
+	if _, err = os.Stat(filepath.Join(dir, "b_templ.go")); !os.IsNotExist(err) {
+		t.Errorf("expected b_templ.go to be deleted, got %v", err)
+	}
+	if _, err = fseh.cssBundle.write(); err != nil {
+		t.Fatalf("failed to write CSS bundle: %v", err)
+	}
+	bundle, err := os.ReadFile(bundleFileName)
+	if err != nil {
+		t.Fatalf("failed to read CSS bundle: %v", err)
+	}
+	expected := `/* Code generated by templ - DO NOT EDIT. */
+
+.red_050e5e03{color:red;}
+`
+	if diff := cmp.Diff(expected, string(bundle)); diff != "" {
+		t.Error(diff)
+	}
+}
+
 func TestCheckWriter(t *testing.T) {
 	t.Run("returns no changed files when content matches", func(t *testing.T) {
 		dir := t.TempDir()
@@ -362,8 +482,116 @@ func TestArgs(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if args.Command != "echo hello" {
-			t.Fatalf("expected command to be 'echo hello', got '%s'", args.Command)
+		expected := []Command{{Name: "cmd", Cmd: "echo hello", Restart: RestartOnGoChange}}
+		if diff := cmp.Diff(expected, args.Commands, cmpopts.IgnoreUnexported(Command{})); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+	t.Run("The cmd flag can be repeated to run named commands", func(t *testing.T) {
+		args, _, _, err := NewArguments(io.Discard, io.Discard, []string{
+			"-cmd", "server=GOFLAGS=-race go run .",
+			"-cmd", "tailwind=npx @tailwindcss/cli --watch",
+			"-cmd", "esbuild=npx esbuild --bundle",
+			"-cmd-restart", "tailwind=never",
+			"-cmd-restart", `esbuild=pattern:\.ts$`,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Command{
+			{Name: "server", Cmd: "GOFLAGS=-race go run .", Restart: RestartOnGoChange},
+			{Name: "tailwind", Cmd: "npx @tailwindcss/cli --watch", Restart: RestartNever},
+			{Name: "esbuild", Cmd: "npx esbuild --bundle", Restart: `pattern:\.ts$`},
+		}
+		if diff := cmp.Diff(expected, args.Commands, cmpopts.IgnoreUnexported(Command{})); diff != "" {
+			t.Fatal(diff)
+		}
+		if !args.Commands[2].restartPattern.MatchString("app.ts") {
+			t.Error("expected restart pattern to match")
+		}
+	})
+	t.Run("Commands can be read from a config file", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "commands.json")
+		config := `{"commands": [{"name": "server", "cmd": "go run ."}, {"name": "tailwind", "cmd": "npx @tailwindcss/cli --watch", "restart": "never"}]}`
+		if err := os.WriteFile(fileName, []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		args, _, _, err := NewArguments(io.Discard, io.Discard, []string{"-cmd-config", fileName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []Command{
+			{Name: "server", Cmd: "go run .", Restart: RestartOnGoChange},
+			{Name: "tailwind", Cmd: "npx @tailwindcss/cli --watch", Restart: RestartNever},
+		}
+		if diff := cmp.Diff(expected, args.Commands, cmpopts.IgnoreUnexported(Command{})); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+	t.Run("Readiness probes can be set for the default command without a name", func(t *testing.T) {
+		args, _, _, err := NewArguments(io.Discard, io.Discard, []string{"-cmd", "go run .", "-proxy", "http://localhost:8080", "-cmd-ready", "http:/healthz?full=true"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if args.Commands[0].ready.url != "http://localhost:8080/healthz?full=true" {
+			t.Errorf("unexpected readiness probe URL %q", args.Commands[0].ready.url)
+		}
+	})
+	t.Run("Invalid commands return an error", func(t *testing.T) {
+		tests := [][]string{
+			{"-cmd", "go run .", "-cmd", "go build ."},
+			{"-cmd", "server=go run .", "-cmd-restart", "server=sometimes"},
+			{"-cmd", "server=go run .", "-cmd-restart", "client=never"},
+			{"-cmd", "server=go run .", "-cmd-restart", "server=pattern:("},
+			{"-cmd", "server=go run .", "-cmd-ready", "server=http:/healthz"},
+			{"-cmd", "server=go run .", "-cmd-ready", "log:ready"},
+		}
+		for _, args := range tests {
+			if _, _, _, err := NewArguments(io.Discard, io.Discard, args); err == nil {
+				t.Errorf("expected error for %v", args)
+			}
+		}
+	})
+	t.Run("The env-file flag can be repeated to load multiple files", func(t *testing.T) {
+		args, _, _, err := NewArguments(io.Discard, io.Discard, []string{"-cmd", "go run .", "-env-file", ".env", "-env-file", ".env.local"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{".env", ".env.local"}, args.EnvFiles); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+	t.Run("Env files are added to the watch pattern", func(t *testing.T) {
+		cmd := Generate{Args: Arguments{
+			WatchPattern: regexp.MustCompile(defaultWatchPattern),
+			EnvFiles:     []string{"/app/.env", "/app/config/.env.local"},
+		}}
+		wp := cmd.watchPatternWithEnvFiles()
+		for _, fileName := range []string{"/app/main.go", "/app/.env", "/app/config/.env.local"} {
+			if !wp.MatchString(fileName) {
+				t.Errorf("expected %q to match", fileName)
+			}
+		}
+		for _, fileName := range []string{"/app/.env.local", "/app/.envrc", "/other/app/.env"} {
+			if wp.MatchString(fileName) {
+				t.Errorf("expected %q not to match", fileName)
+			}
+		}
+	})
+	t.Run("-json-events writes events to stdout", func(t *testing.T) {
+		var stdout bytes.Buffer
+		args, _, _, err := NewArguments(&stdout, io.Discard, []string{"-json-events"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if args.JSONEvents != &stdout {
+			t.Fatal("expected JSONEvents to be stdout")
+		}
+	})
+	t.Run("-json-events with -stdout returns an error", func(t *testing.T) {
+		_, _, _, err := NewArguments(io.Discard, io.Discard, []string{"-json-events", "-stdout", "-f", "test.templ"})
+		if err == nil {
+			t.Fatal("expected error when -json-events and -stdout are both set")
 		}
 	})
 	t.Run("-check sets Check to true", func(t *testing.T) {
@@ -387,4 +615,16 @@ func TestArgs(t *testing.T) {
 			t.Fatal("expected error when -check and -stdout are both set")
 		}
 	})
+	t.Run("-css-bundle with -f returns an error", func(t *testing.T) {
+		_, _, _, err := NewArguments(io.Discard, io.Discard, []string{"-css-bundle", "templ.css", "-f", "test.templ"})
+		if err == nil {
+			t.Fatal("expected error when -css-bundle and -f are both set")
+		}
+	})
+	t.Run("-css-bundle with -lazy returns an error", func(t *testing.T) {
+		_, _, _, err := NewArguments(io.Discard, io.Discard, []string{"-css-bundle", "templ.css", "-lazy"})
+		if err == nil {
+			t.Fatal("expected error when -css-bundle and -lazy are both set")
+		}
+	})
 }
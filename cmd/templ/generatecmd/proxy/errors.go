@@ -0,0 +1,125 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// BuildError is an error in a templ file, or a failure of the command, that is shown in an
+// overlay in the browser until it is cleared.
+type BuildError struct {
+	// File that contains the error, if known.
+	File string `json:"file,omitempty"`
+	// Line and Col are 1-based, and zero if the position is unknown.
+	Line int `json:"line,omitempty"`
+	Col  int `json:"col,omitempty"`
+	// Message describing the error.
+	Message string `json:"message"`
+	// Snippet is the source code around the error.
+	Snippet []SnippetLine `json:"snippet,omitempty"`
+	// Output is the output of the command that failed.
+	Output string `json:"output,omitempty"`
+}
+
+// SnippetLine is a line of source code.
+type SnippetLine struct {
+	// Number is the 1-based line number.
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+	// Error is true if the error is on this line.
+	Error bool `json:"error,omitempty"`
+}
+
+// Snippet returns the lines of src around the 1-based line number.
+func Snippet(src string, line int, context int) (snippet []SnippetLine) {
+	lines := strings.Split(src, "\n")
+	from, to := max(line-context, 1), min(line+context, len(lines))
+	for n := from; n <= to; n++ {
+		snippet = append(snippet, SnippetLine{
+			Number: n,
+			Text:   strings.TrimRight(lines[n-1], "\r"),
+			Error:  n == line,
+		})
+	}
+	return snippet
+}
+
+// SetError shows the error in the browser, replacing any error previously set with the same key.
+func (h *Handler) SetError(key string, e BuildError) {
+	h.errorsM.Lock()
+	defer h.errorsM.Unlock()
+	h.errors[key] = e
+	h.errorGenerations[key] = h.generation
+	h.sendErrors()
+}
+
+// ClearError removes the error with the key from the browser.
+func (h *Handler) ClearError(key string) {
+	h.errorsM.Lock()
+	defer h.errorsM.Unlock()
+	if _, ok := h.errors[key]; !ok {
+		return
+	}
+	delete(h.errors, key)
+	delete(h.errorGenerations, key)
+	h.sendErrors()
+}
+
+func (h *Handler) sendErrors() {
+	keys := make([]string, 0, len(h.errors))
+	for k := range h.errors {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	errs := make([]BuildError, len(keys))
+	for i, k := range keys {
+		errs[i] = h.errors[k]
+	}
+	data, err := json.Marshal(errs)
+	if err != nil {
+		h.log.Error("Failed to encode errors", slog.Any("error", err))
+		return
+	}
+	// Retain the errors so that pages that are loaded later also show them.
+	h.sse.Retain("error", string(data))
+}
+
+// hasCurrentErrors returns true if any errors have been set since the app was last restarted.
+func (h *Handler) hasCurrentErrors() bool {
+	h.errorsM.Lock()
+	defer h.errorsM.Unlock()
+	for _, generation := range h.errorGenerations {
+		if generation == h.generation {
+			return true
+		}
+	}
+	return false
+}
+
+// errorHandler is used when the target can't be reached. It serves a page that includes the
+// reload script, so that errors are shown in the browser, and the page reloads when the target
+// is available.
+func (h *Handler) errorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	h.log.Warn("Proxy to target error", slog.String("url", r.URL.String()), slog.Any("error", err))
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusBadGateway)
+	_, _ = fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><title>templ proxy</title></head>
+<body>
+<p>Unable to reach %s: %s</p>
+<p>The page will reload when the application is available.</p>
+<script src="/_templ/reload/script.js"></script>
+</body>
+</html>
+`, html.EscapeString(h.Target.String()), html.EscapeString(err.Error()))
+}
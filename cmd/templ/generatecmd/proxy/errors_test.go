@@ -0,0 +1,198 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnippet(t *testing.T) {
+	src := "a\nb\nc\nd\ne\nf\ng"
+	tests := []struct {
+		name     string
+		line     int
+		expected []SnippetLine
+	}{
+		{
+			name: "lines either side of the error are included",
+			line: 4,
+			expected: []SnippetLine{
+				{Number: 3, Text: "c"},
+				{Number: 4, Text: "d", Error: true},
+				{Number: 5, Text: "e"},
+			},
+		},
+		{
+			name: "the snippet is limited to the start of the file",
+			line: 1,
+			expected: []SnippetLine{
+				{Number: 1, Text: "a", Error: true},
+				{Number: 2, Text: "b"},
+			},
+		},
+		{
+			name: "the snippet is limited to the end of the file",
+			line: 7,
+			expected: []SnippetLine{
+				{Number: 6, Text: "f"},
+				{Number: 7, Text: "g", Error: true},
+			},
+		},
+		{
+			name:     "lines outside of the file are ignored",
+			line:     10,
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := Snippet(src, tt.line, 1)
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	readErrors := func(t *testing.T, proxyURL string) []BuildError {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, proxyURL+"/_templ/reload/events", nil)
+		if err != nil {
+			t.Fatalf("unexpected error creating request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error connecting to events: %v", err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		scanner := bufio.NewScanner(resp.Body)
+		var isError bool
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "event: error" {
+				isError = true
+				continue
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok && isError {
+				var errs []BuildError
+				if err := json.Unmarshal([]byte(data), &errs); err != nil {
+					t.Fatalf("unexpected error decoding errors: %v", err)
+				}
+				return errs
+			}
+		}
+		t.Fatalf("error event not received: %v", scanner.Err())
+		return nil
+	}
+
+	t.Run("errors are sent to clients that connect after they are set", func(t *testing.T) {
+		h := New(log, "http", "127.0.0.1", 0, &url.URL{Scheme: "http", Host: "example.com"})
+		server := httptest.NewServer(h)
+		defer server.Close()
+
+		h.SetError("b.templ", BuildError{File: "b.templ", Line: 2, Col: 3, Message: "b failed"})
+		h.SetError("a.templ", BuildError{File: "a.templ", Message: "a failed"})
+
+		expected := []BuildError{
+			{File: "a.templ", Message: "a failed"},
+			{File: "b.templ", Line: 2, Col: 3, Message: "b failed"},
+		}
+		if diff := cmp.Diff(expected, readErrors(t, server.URL)); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("cleared errors are removed", func(t *testing.T) {
+		h := New(log, "http", "127.0.0.1", 0, &url.URL{Scheme: "http", Host: "example.com"})
+		server := httptest.NewServer(h)
+		defer server.Close()
+
+		h.SetError("a.templ", BuildError{File: "a.templ", Message: "a failed"})
+		h.ClearError("a.templ")
+
+		if errs := readErrors(t, server.URL); len(errs) != 0 {
+			t.Errorf("expected no errors, got %v", errs)
+		}
+		if h.hasCurrentErrors() {
+			t.Error("expected hasCurrentErrors to be false")
+		}
+	})
+	t.Run("errors set before the app is restarted are not current", func(t *testing.T) {
+		h := New(log, "http", "127.0.0.1", 0, &url.URL{Scheme: "http", Host: "example.com"})
+
+		h.SetError("a.templ", BuildError{File: "a.templ", Message: "a failed"})
+		if !h.hasCurrentErrors() {
+			t.Error("expected the error to be current")
+		}
+		h.SetWaiting(true)
+		if h.hasCurrentErrors() {
+			t.Error("expected the error from the previous generation not to be current")
+		}
+		h.SetError("cmd", BuildError{Message: "exit status 1"})
+		if !h.hasCurrentErrors() {
+			t.Error("expected the error set after the restart to be current")
+		}
+	})
+	t.Run("when the target is unavailable, HTML requests receive a page that includes the reload script", func(t *testing.T) {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		targetURL, err := url.Parse(target.URL)
+		if err != nil {
+			t.Fatalf("unexpected error parsing URL: %v", err)
+		}
+		target.Close()
+
+		h := New(log, "http", "127.0.0.1", 0, targetURL)
+		// Errors stop retries, so that the test doesn't wait for them.
+		h.SetError("cmd", BuildError{Message: "exit status 1"})
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusBadGateway {
+			t.Errorf("expected status %d, got %d", http.StatusBadGateway, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `<script src="/_templ/reload/script.js"></script>`) {
+			t.Errorf("expected reload script in body, got %q", w.Body.String())
+		}
+	})
+	t.Run("when the target is unavailable, other requests receive an empty response", func(t *testing.T) {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		targetURL, err := url.Parse(target.URL)
+		if err != nil {
+			t.Fatalf("unexpected error parsing URL: %v", err)
+		}
+		target.Close()
+
+		h := New(log, "http", "127.0.0.1", 0, targetURL)
+		h.SetError("cmd", BuildError{Message: "exit status 1"})
+
+		r := httptest.NewRequest(http.MethodGet, "/api", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusBadGateway {
+			t.Errorf("expected status %d, got %d", http.StatusBadGateway, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("expected empty body, got %q", w.Body.String())
+		}
+	})
+}
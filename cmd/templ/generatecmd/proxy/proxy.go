@@ -14,6 +14,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/a-h/templ/cmd/templ/generatecmd/sse"
@@ -33,6 +34,17 @@ type Handler struct {
 	Target *url.URL
 	p      *httputil.ReverseProxy
 	sse    *sse.Handler
+
+	errorsM sync.Mutex
+	errors  map[string]BuildError
+	// generation is incremented each time the app is restarted, and errorGenerations records the
+	// generation that each error was set in, so that retries are only stopped by errors from
+	// the current generation.
+	generation       int
+	errorGenerations map[string]int
+
+	// Events serves the stream of JSON events at /_templ/events, if set.
+	Events http.Handler
 }
 
 func reloadScript(nonce string) *html.Node {
@@ -131,6 +143,15 @@ const unsupportedContentEncoding = "Unsupported content encoding, hot reload scr
 
 func (h *Handler) modifyResponse(r *http.Response) error {
 	log := h.log.With(slog.String("url", r.Request.URL.String()))
+	if r.StatusCode == http.StatusSwitchingProtocols {
+		log.Debug("Skipping response modification because the connection was upgraded", slog.String("upgrade", r.Header.Get("Upgrade")))
+		return nil
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/event-stream") {
+		// The reverse proxy flushes each write to event streams, so they're passed through as-is.
+		log.Debug("Skipping response modification because the response is an event stream")
+		return nil
+	}
 	if r.Header.Get("templ-skip-modify") == "true" {
 		log.Debug("Skipping response modification because templ-skip-modify header is set")
 		return nil
@@ -273,19 +294,25 @@ outer:
 func New(log *slog.Logger, scheme string, bind string, port int, target *url.URL) (h *Handler) {
 	p := httputil.NewSingleHostReverseProxy(target)
 	p.ErrorLog = stdlog.New(os.Stderr, "Proxy to target error: ", 0)
+	h = &Handler{
+		log:              log,
+		URL:              fmt.Sprintf("%s://%s:%d", scheme, bind, port),
+		Target:           target,
+		p:                p,
+		sse:              sse.New(),
+		errors:           map[string]BuildError{},
+		errorGenerations: map[string]int{},
+	}
 	p.Transport = &roundTripper{
 		maxRetries:      20,
 		initialDelay:    100 * time.Millisecond,
 		backoffExponent: 1.5,
-	}
-	h = &Handler{
-		log:    log,
-		URL:    fmt.Sprintf("%s://%s:%d", scheme, bind, port),
-		Target: target,
-		p:      p,
-		sse:    sse.New(),
+		// If the app has failed since it was last restarted, it may never become available, so
+		// stop retrying.
+		stop: h.hasCurrentErrors,
 	}
 	p.ModifyResponse = h.modifyResponse
+	p.ErrorHandler = h.errorHandler
 	return h
 }
 
@@ -299,6 +326,10 @@ func (p *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
+	if r.URL.Path == "/_templ/events" && p.Events != nil {
+		p.Events.ServeHTTP(w, r)
+		return
+	}
 	if r.URL.Path == "/_templ/reload/events" {
 		switch r.Method {
 		case http.MethodGet:
@@ -320,10 +351,25 @@ func (p *Handler) SendSSE(eventType string, data string) {
 	p.sse.Send(eventType, data)
 }
 
+// SetWaiting shows that the app is being waited for in the browser. Waiting starts a new
+// generation of the app, so errors set before waiting no longer stop requests being retried.
+func (p *Handler) SetWaiting(waiting bool) {
+	status := "ready"
+	if waiting {
+		status = "waiting"
+		p.errorsM.Lock()
+		p.generation++
+		p.errorsM.Unlock()
+	}
+	p.sse.Retain("status", status)
+}
+
 type roundTripper struct {
 	maxRetries      int
 	initialDelay    time.Duration
 	backoffExponent float64
+	// stop returns true if requests should no longer be retried.
+	stop func() bool
 }
 
 func (rt *roundTripper) setShouldSkipResponseModificationHeader(r *http.Request, resp *http.Response) {
@@ -335,10 +381,23 @@ func (rt *roundTripper) setShouldSkipResponseModificationHeader(r *http.Request,
 	resp.Header.Set("templ-skip-modify", "true")
 }
 
+// isUpgrade returns true if the request asks to switch protocols, e.g. to use WebSockets.
+func isUpgrade(r *http.Request) bool {
+	for _, v := range r.Header["Connection"] {
+		for token := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (rt *roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
-	// Read and buffer the body.
+	// Read and buffer the body, so that it can be sent again if the request is retried.
+	// Upgraded connections use the body after the response is received, so it can't be buffered.
 	var bodyBytes []byte
-	if r.Body != nil && r.Body != http.NoBody {
+	if r.Body != nil && r.Body != http.NoBody && !isUpgrade(r) {
 		var err error
 		bodyBytes, err = io.ReadAll(r.Body)
 		if err != nil {
@@ -360,9 +419,21 @@ func (rt *roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 		}
 
 		// Execute the request.
+		// While the app is restarting, requests are retried, so that WebSocket and event stream
+		// clients that reconnect are connected to the app when it's available.
 		resp, err = http.DefaultTransport.RoundTrip(req)
 		if err != nil || resp.StatusCode == http.StatusBadGateway {
-			time.Sleep(rt.initialDelay * time.Duration(math.Pow(rt.backoffExponent, float64(retries))))
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			if rt.stop != nil && rt.stop() {
+				break
+			}
+			select {
+			case <-r.Context().Done():
+				return nil, r.Context().Err()
+			case <-time.After(rt.initialDelay * time.Duration(math.Pow(rt.backoffExponent, float64(retries)))):
+			}
 			continue
 		}
 
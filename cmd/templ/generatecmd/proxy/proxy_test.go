@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -21,6 +22,7 @@ import (
 	"github.com/andybalholm/brotli"
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/net/html"
+	"golang.org/x/net/websocket"
 )
 
 func TestRoundTripper(t *testing.T) {
@@ -688,6 +690,18 @@ func TestProxy(t *testing.T) {
 			t.Fatalf("timeout waiting for sse response")
 		}
 	})
+	t.Run("events: the JSON event stream is served if set", func(t *testing.T) {
+		log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+		h := New(log, "http", "127.0.0.1", 7474, &url.URL{Scheme: "http", Host: "example.com"})
+		h.Events = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, `{"type":"reload"}`+"\n")
+		})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_templ/events", nil))
+		if w.Body.String() != `{"type":"reload"}`+"\n" {
+			t.Errorf("unexpected body %q", w.Body.String())
+		}
+	})
 	t.Run("unsupported encodings result in a warning", func(t *testing.T) {
 		// Arrange
 		r := &http.Response{
@@ -918,3 +932,131 @@ alert("test");
 		}
 	})
 }
+
+func TestPassthrough(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	newProxy := func(t *testing.T, target string) *httptest.Server {
+		t.Helper()
+		u, err := url.Parse(target)
+		if err != nil {
+			t.Fatalf("unexpected error parsing URL: %v", err)
+		}
+		proxyServer := httptest.NewServer(New(log, "http", "127.0.0.1", 0, u))
+		t.Cleanup(proxyServer.Close)
+		return proxyServer
+	}
+
+	t.Run("websocket connections are tunnelled", func(t *testing.T) {
+		echo := websocket.Handler(func(ws *websocket.Conn) {
+			_, _ = io.Copy(ws, ws)
+		})
+		target := httptest.NewServer(echo)
+		defer target.Close()
+		proxyServer := newProxy(t, target.URL)
+
+		wsURL := "ws" + strings.TrimPrefix(proxyServer.URL, "http")
+		ws, err := websocket.Dial(wsURL, "", proxyServer.URL)
+		if err != nil {
+			t.Fatalf("unexpected error dialing websocket: %v", err)
+		}
+		defer func() {
+			_ = ws.Close()
+		}()
+		for _, msg := range []string{"hello", "<body>world</body>"} {
+			if err := websocket.Message.Send(ws, msg); err != nil {
+				t.Fatalf("unexpected error sending message: %v", err)
+			}
+			var actual string
+			if err := websocket.Message.Receive(ws, &actual); err != nil {
+				t.Fatalf("unexpected error receiving message: %v", err)
+			}
+			if actual != msg {
+				t.Errorf("expected %q, got %q", msg, actual)
+			}
+		}
+	})
+	t.Run("event streams are streamed without modification", func(t *testing.T) {
+		next := make(chan struct{})
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := range 2 {
+				_, _ = fmt.Fprintf(w, "data: <body>%d</body>\n\n", i)
+				w.(http.Flusher).Flush()
+				// Wait for the client to receive the event before sending the next one.
+				select {
+				case <-next:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}))
+		defer target.Close()
+		proxyServer := newProxy(t, target.URL)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, proxyServer.URL, nil)
+		if err != nil {
+			t.Fatalf("unexpected error creating request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		scanner := bufio.NewScanner(resp.Body)
+		for i := range 2 {
+			var lines []string
+			for scanner.Scan() && scanner.Text() != "" {
+				lines = append(lines, scanner.Text())
+			}
+			expected := []string{fmt.Sprintf("data: <body>%d</body>", i)}
+			if diff := cmp.Diff(expected, lines); diff != "" {
+				t.Fatalf("event %d: %s", i, diff)
+			}
+			next <- struct{}{}
+		}
+	})
+	t.Run("requests are retried until the target is available", func(t *testing.T) {
+		// Find a free port for the target.
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error listening: %v", err)
+		}
+		addr := l.Addr().String()
+		_ = l.Close()
+		proxyServer := newProxy(t, "http://"+addr)
+
+		// Start the target after the first request has been made, as if it were restarting.
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			l, err := net.Listen("tcp", addr)
+			if err != nil {
+				return
+			}
+			target := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, "data: connected\n\n")
+			})}
+			t.Cleanup(func() { _ = target.Close() })
+			_ = target.Serve(l)
+		}()
+
+		resp, err := http.Get(proxyServer.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("unexpected error reading body: %v", err)
+		}
+		if string(body) != "data: connected\n\n" {
+			t.Errorf("unexpected body: %q", string(body))
+		}
+	})
+}
@@ -2,9 +2,284 @@
   let templ_reloadSrc = window.templ_reloadSrc || new EventSource("/_templ/reload/events");
   templ_reloadSrc.onmessage = (event) => {
     if (event && event.data === "reload") {
-      window.location.reload();
+      templ_morphPage();
     }
   };
+  templ_reloadSrc.addEventListener("css", (event) => templ_swapCSS(event.data));
+  // The "status" event is "waiting" while the app is restarting, and "ready" when it has started.
+  templ_reloadSrc.addEventListener("status", (event) => templ_showWaiting(event.data === "waiting"));
+  // The "error" event is sent by the server with a list of build errors. EventSource also
+  // fires an "error" event without data when the connection fails, which is ignored.
+  templ_reloadSrc.addEventListener("error", (event) => {
+    if (event.data === undefined) {
+      return;
+    }
+    templ_showErrors(JSON.parse(event.data));
+  });
   window.templ_reloadSrc = templ_reloadSrc;
   window.onbeforeunload = () => window.templ_reloadSrc.close();
+
+  // templ_morphPage fetches the current page and updates the document in place, so that form
+  // state, focus and scroll position are kept. If that's not possible, the page is reloaded.
+  async function templ_morphPage() {
+    try {
+      const resp = await fetch(window.location.href, { headers: { "Accept": "text/html" } });
+      const contentType = resp.headers.get("Content-Type") || "";
+      if (!resp.ok || !contentType.startsWith("text/html")) {
+        throw new Error("unexpected response: " + resp.status + " " + contentType);
+      }
+      const doc = new DOMParser().parseFromString(await resp.text(), "text/html");
+      if (!document.head || !doc.head || !document.body || !doc.body) {
+        throw new Error("missing head or body");
+      }
+      morphAttributes(document.documentElement, doc.documentElement);
+      morphAttributes(document.head, doc.head);
+      morphChildren(document.head, doc.head);
+      morphAttributes(document.body, doc.body);
+      morphChildren(document.body, doc.body);
+    } catch (err) {
+      console.debug("templ: unable to update page in place, reloading", err);
+      window.location.reload();
+    }
+  }
+
+  // isSameNode returns true if the new node can be morphed into the old node.
+  function isSameNode(oldNode, newNode) {
+    if (oldNode.nodeType !== newNode.nodeType) {
+      return false;
+    }
+    if (oldNode.nodeType !== Node.ELEMENT_NODE) {
+      return true;
+    }
+    return oldNode.tagName === newNode.tagName && oldNode.id === newNode.id;
+  }
+
+  function morphNode(oldNode, newNode) {
+    if (oldNode.nodeType !== Node.ELEMENT_NODE) {
+      if (oldNode.nodeValue !== newNode.nodeValue) {
+        oldNode.nodeValue = newNode.nodeValue;
+      }
+      return;
+    }
+    // Scripts only run when they're inserted, so changed scripts are replaced.
+    if (oldNode.tagName === "SCRIPT") {
+      if (!isSameScript(oldNode, newNode)) {
+        oldNode.replaceWith(runnableScript(newNode));
+      }
+      return;
+    }
+    morphAttributes(oldNode, newNode);
+    // Keep the content of text areas, since the user may have typed into them.
+    if (oldNode.tagName === "TEXTAREA") {
+      return;
+    }
+    morphChildren(oldNode, newNode);
+  }
+
+  function morphAttributes(oldEl, newEl) {
+    for (const attr of Array.from(oldEl.attributes)) {
+      if (!newEl.hasAttribute(attr.name)) {
+        oldEl.removeAttribute(attr.name);
+      }
+    }
+    for (const attr of Array.from(newEl.attributes)) {
+      if (oldEl.getAttribute(attr.name) !== attr.value) {
+        oldEl.setAttribute(attr.name, attr.value);
+      }
+    }
+  }
+
+  // findMatch finds a node in the old children, starting at the insertion point, that the new node
+  // can be morphed into. Elements with an id are matched by id, wherever they are.
+  function findMatch(oldParent, start, newNode) {
+    if (newNode.nodeType === Node.ELEMENT_NODE && newNode.id) {
+      const match = oldParent.querySelector(":scope > #" + CSS.escape(newNode.id));
+      return match && match.tagName === newNode.tagName ? match : null;
+    }
+    for (let n = start; n; n = n.nextSibling) {
+      if (n.nodeType === Node.ELEMENT_NODE && n.id) {
+        continue;
+      }
+      if (isSameNode(n, newNode)) {
+        return n;
+      }
+      // Don't look past elements that will be matched by later new nodes.
+      if (n.nodeType === Node.ELEMENT_NODE) {
+        break;
+      }
+    }
+    return null;
+  }
+
+  function morphChildren(oldParent, newParent) {
+    let insertionPoint = oldParent.firstChild;
+    for (const newChild of Array.from(newParent.childNodes)) {
+      const match = findMatch(oldParent, insertionPoint, newChild);
+      if (match) {
+        if (match !== insertionPoint) {
+          oldParent.insertBefore(match, insertionPoint);
+        } else {
+          insertionPoint = insertionPoint.nextSibling;
+        }
+        morphNode(match, newChild);
+        continue;
+      }
+      const imported = document.importNode(newChild, true);
+      oldParent.insertBefore(imported, insertionPoint);
+      runScripts(imported);
+    }
+    // Remove any old nodes that weren't matched.
+    while (insertionPoint) {
+      const next = insertionPoint.nextSibling;
+      oldParent.removeChild(insertionPoint);
+      insertionPoint = next;
+    }
+  }
+
+  // isSameScript compares scripts, ignoring the nonce, which may change with each request, and
+  // which browsers hide once the script has been inserted.
+  function isSameScript(oldScript, newScript) {
+    const attributes = (script) => Array.from(script.attributes)
+      .filter((attr) => attr.name !== "nonce")
+      .map((attr) => attr.name + "=" + attr.value)
+      .sort()
+      .join("\n");
+    return oldScript.textContent === newScript.textContent && attributes(oldScript) === attributes(newScript);
+  }
+
+  // runnableScript copies a script element. Scripts created by DOMParser are marked as already
+  // started, so they don't run when they're inserted into the document, but copies do.
+  function runnableScript(script) {
+    const copy = document.createElement("script");
+    for (const attr of Array.from(script.attributes)) {
+      copy.setAttribute(attr.name, attr.value);
+    }
+    copy.textContent = script.textContent;
+    return copy;
+  }
+
+  // runScripts replaces the inserted node's scripts, including the node itself, with copies
+  // that run.
+  function runScripts(node) {
+    if (node.nodeType !== Node.ELEMENT_NODE) {
+      return;
+    }
+    const scripts = node.tagName === "SCRIPT" ? [node] : Array.from(node.querySelectorAll("script"));
+    for (const script of scripts) {
+      script.replaceWith(runnableScript(script));
+    }
+  }
+
+  // templ_swapCSS reloads the stylesheets that match the updated CSS file by updating their
+  // hrefs. If no stylesheets match, all stylesheets on the same origin are reloaded.
+  function templ_swapCSS(fileName) {
+    const links = Array.from(document.querySelectorAll('link[rel="stylesheet"][href]'))
+      .filter((link) => new URL(link.href).origin === window.location.origin);
+    const baseName = fileName.split("/").pop();
+    const matches = links.filter((link) => new URL(link.href).pathname.endsWith("/" + baseName));
+    for (const link of matches.length > 0 ? matches : links) {
+      const url = new URL(link.href);
+      url.searchParams.set("templ_reload", Date.now().toString());
+      link.href = url.toString();
+    }
+  }
+
+  const waitingId = "templ-waiting";
+
+  function templ_showWaiting(waiting) {
+    const existing = document.getElementById(waitingId);
+    if (!waiting) {
+      if (existing) {
+        existing.remove();
+      }
+      return;
+    }
+    if (existing) {
+      return;
+    }
+    const host = document.createElement("div");
+    host.id = waitingId;
+    const root = host.attachShadow({ mode: "open" });
+    const badge = document.createElement("div");
+    badge.textContent = "Waiting for app\u2026";
+    badge.setAttribute("role", "status");
+    badge.style.cssText = "position: fixed; bottom: 1rem; right: 1rem; z-index: 2147483647; padding: 0.5rem 0.75rem; border-radius: 4px; background: #1c1c1c; color: #e8e8e8; font: 13px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; box-shadow: 0 2px 8px rgba(0, 0, 0, 0.3);";
+    root.appendChild(badge);
+    document.documentElement.appendChild(host);
+  }
+
+  const overlayId = "templ-error-overlay";
+  const style = `
+    :host { all: initial; }
+    .overlay { position: fixed; inset: 0; z-index: 2147483647; overflow: auto; padding: 2rem; background: rgba(0, 0, 0, 0.85); color: #e8e8e8; font: 14px/1.5 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; }
+    .error { margin: 0 auto 1.5rem; max-width: 960px; padding: 1rem 1.5rem; border-top: 4px solid #e5484d; background: #1c1c1c; }
+    .location { color: #9ba1a6; }
+    .message { margin: 0.5rem 0; color: #ff9592; white-space: pre-wrap; }
+    pre { margin: 0.5rem 0 0; padding: 0.5rem 0; overflow: auto; background: #111; }
+    .line { display: block; padding: 0 1rem; }
+    .line.error-line { background: rgba(229, 72, 77, 0.25); }
+    .number { display: inline-block; min-width: 3em; color: #6f6f6f; user-select: none; }
+    .output { padding: 0.5rem 1rem; }
+    button { position: fixed; top: 1rem; right: 1rem; padding: 0.25rem 0.75rem; border: 1px solid #6f6f6f; border-radius: 4px; background: #1c1c1c; color: #e8e8e8; font: inherit; cursor: pointer; }
+  `;
+
+  function el(tag, className, text) {
+    const e = document.createElement(tag);
+    if (className) {
+      e.className = className;
+    }
+    if (text !== undefined) {
+      e.textContent = text;
+    }
+    return e;
+  }
+
+  function renderError(err) {
+    const div = el("div", "error");
+    if (err.file) {
+      let location = err.file;
+      if (err.line) {
+        location += ":" + err.line + (err.col ? ":" + err.col : "");
+      }
+      div.appendChild(el("div", "location", location));
+    }
+    div.appendChild(el("div", "message", err.message));
+    if (err.snippet && err.snippet.length > 0) {
+      const pre = el("pre");
+      for (const line of err.snippet) {
+        const code = el("code", line.error ? "line error-line" : "line");
+        code.appendChild(el("span", "number", String(line.number)));
+        code.appendChild(document.createTextNode(line.text));
+        pre.appendChild(code);
+      }
+      div.appendChild(pre);
+    }
+    if (err.output) {
+      div.appendChild(el("pre", "output", err.output));
+    }
+    return div;
+  }
+
+  function templ_showErrors(errors) {
+    const existing = document.getElementById(overlayId);
+    if (existing) {
+      existing.remove();
+    }
+    if (!errors || errors.length === 0) {
+      return;
+    }
+    const host = el("div");
+    host.id = overlayId;
+    const root = host.attachShadow({ mode: "open" });
+    root.appendChild(el("style", "", style));
+    const overlay = el("div", "overlay");
+    const close = el("button", "", "Close");
+    close.addEventListener("click", () => host.remove());
+    overlay.appendChild(close);
+    for (const err of errors) {
+      overlay.appendChild(renderError(err));
+    }
+    root.appendChild(overlay);
+    document.documentElement.appendChild(host);
+  }
 })();
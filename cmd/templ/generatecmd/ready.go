@@ -0,0 +1,175 @@
+package generatecmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Readiness probe prefixes.
+const (
+	readyHTTPPrefix = "http:"
+	readyTCPPrefix  = "tcp:"
+	readyLogPrefix  = "log:"
+)
+
+// readinessProbe checks that a command is ready to receive requests.
+type readinessProbe struct {
+	// url is requested until it returns a 2xx status code.
+	url string
+	// address is dialled until a TCP connection can be made.
+	address string
+	// pattern is matched against each line of the command's output.
+	pattern *regexp.Regexp
+}
+
+// parseReadinessProbe parses "http:<path or URL>", "tcp:<address>", or "log:<regexp>". Paths
+// are relative to the proxy target.
+func parseReadinessProbe(value string, proxy string) (probe *readinessProbe, err error) {
+	switch {
+	case strings.HasPrefix(value, readyHTTPPrefix), strings.HasPrefix(value, "https:"):
+		if u, err := url.Parse(value); err == nil && u.Host != "" {
+			return &readinessProbe{url: u.String()}, nil
+		}
+		target := strings.TrimPrefix(value, readyHTTPPrefix)
+		if !strings.HasPrefix(target, "/") {
+			return nil, fmt.Errorf("invalid HTTP readiness probe %q, expected http:/<path> or a URL", value)
+		}
+		if proxy == "" {
+			return nil, fmt.Errorf("HTTP readiness probe %q requires -proxy, or use a URL, e.g. http://localhost:8080%s", value, target)
+		}
+		base, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+		}
+		ref, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTP readiness probe %q: %w", value, err)
+		}
+		return &readinessProbe{url: base.ResolveReference(ref).String()}, nil
+	case strings.HasPrefix(value, readyTCPPrefix):
+		address := strings.TrimPrefix(value, readyTCPPrefix)
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return nil, fmt.Errorf("invalid TCP readiness probe %q: %w", value, err)
+		}
+		return &readinessProbe{address: address}, nil
+	case strings.HasPrefix(value, readyLogPrefix):
+		expr := strings.TrimPrefix(value, readyLogPrefix)
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log readiness probe %q: %w", value, err)
+		}
+		return &readinessProbe{pattern: pattern}, nil
+	}
+	return nil, fmt.Errorf("invalid readiness probe %q, expected http:<path or URL>, tcp:<address> or log:<regexp>", value)
+}
+
+// readinessPollInterval is the time between attempts to connect to the command.
+const readinessPollInterval = 100 * time.Millisecond
+
+// wait until the probe passes, the command exits, or the context is done. For log probes, matched
+// is closed when a line of output matches the pattern.
+func (p *readinessProbe) wait(ctx context.Context, matched, exited <-chan struct{}) error {
+	if p.pattern != nil {
+		select {
+		case <-matched:
+			return nil
+		case <-exited:
+			return fmt.Errorf("command exited")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+	for {
+		if p.check(ctx) {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-exited:
+			return fmt.Errorf("command exited")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *readinessProbe) check(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if p.address != "" {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", p.address)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
+}
+
+// maxLogLineLength is the maximum length of a line of output that is matched.
+const maxLogLineLength = 64 * 1024
+
+// logMatcher is written to with the output of a command, and calls onMatch when a line of
+// output matches the pattern.
+type logMatcher struct {
+	m       sync.Mutex
+	pattern *regexp.Regexp
+	line    []byte
+	onMatch func()
+	done    bool
+}
+
+func newLogMatcher(pattern *regexp.Regexp, onMatch func()) *logMatcher {
+	return &logMatcher{
+		pattern: pattern,
+		onMatch: onMatch,
+	}
+}
+
+func (lm *logMatcher) Write(p []byte) (n int, err error) {
+	lm.m.Lock()
+	defer lm.m.Unlock()
+	if lm.done {
+		return len(p), nil
+	}
+	lm.line = append(lm.line, p...)
+	for {
+		i := bytes.IndexByte(lm.line, '\n')
+		if i < 0 {
+			break
+		}
+		if lm.pattern.Match(lm.line[:i]) {
+			lm.done = true
+			lm.line = nil
+			lm.onMatch()
+			return len(p), nil
+		}
+		lm.line = lm.line[i+1:]
+	}
+	// Don't buffer long lines indefinitely.
+	if len(lm.line) > maxLogLineLength {
+		lm.line = lm.line[len(lm.line)-maxLogLineLength:]
+	}
+	return len(p), nil
+}
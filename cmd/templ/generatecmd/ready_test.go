@@ -0,0 +1,158 @@
+package generatecmd
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseReadinessProbe(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		proxy    string
+		expected readinessProbe
+		err      bool
+	}{
+		{
+			name:     "HTTP paths are relative to the proxy target",
+			value:    "http:/healthz",
+			proxy:    "http://localhost:8080",
+			expected: readinessProbe{url: "http://localhost:8080/healthz"},
+		},
+		{
+			name:     "HTTP probes can use a URL",
+			value:    "http://localhost:9090/ready",
+			expected: readinessProbe{url: "http://localhost:9090/ready"},
+		},
+		{
+			name:     "HTTPS probes can use a URL",
+			value:    "https://localhost:9090/ready",
+			expected: readinessProbe{url: "https://localhost:9090/ready"},
+		},
+		{
+			name:  "HTTP paths require a proxy",
+			value: "http:/healthz",
+			err:   true,
+		},
+		{
+			name:     "TCP probes use an address",
+			value:    "tcp:localhost:8080",
+			expected: readinessProbe{address: "localhost:8080"},
+		},
+		{
+			name:  "TCP probes require a port",
+			value: "tcp:localhost",
+			err:   true,
+		},
+		{
+			name:  "invalid log patterns are an error",
+			value: "log:(",
+			err:   true,
+		},
+		{
+			name:  "unknown probes are an error",
+			value: "exec:true",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseReadinessProbe(tt.value, tt.proxy)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", actual)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *actual != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *actual)
+			}
+		})
+	}
+}
+
+func TestReadinessProbeWait(t *testing.T) {
+	never := make(chan struct{})
+
+	t.Run("HTTP probes wait for a 2xx response", func(t *testing.T) {
+		ready := time.Now().Add(200 * time.Millisecond)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if time.Now().Before(ready) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer s.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		p := &readinessProbe{url: s.URL}
+		if err := p.wait(ctx, never, never); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if time.Now().Before(ready) {
+			t.Error("expected to wait until the server was ready")
+		}
+	})
+	t.Run("TCP probes wait for a connection", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer func() {
+			_ = l.Close()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		p := &readinessProbe{address: l.Addr().String()}
+		if err := p.wait(ctx, never, never); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("probes stop waiting when the command exits", func(t *testing.T) {
+		exited := make(chan struct{})
+		close(exited)
+		p := &readinessProbe{address: "127.0.0.1:1"}
+		if err := p.wait(context.Background(), never, exited); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+	t.Run("probes stop waiting when the timeout is reached", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		p := &readinessProbe{pattern: regexp.MustCompile("listening")}
+		if err := p.wait(ctx, never, never); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestLogMatcher(t *testing.T) {
+	var matches int
+	lm := newLogMatcher(regexp.MustCompile(`^listening on :\d+$`), func() { matches++ })
+	for _, s := range []string{"starting\nlisten", "ing on :80", "80\n", "listening on :8080\n"} {
+		if _, err := lm.Write([]byte(s)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if matches != 1 {
+		t.Errorf("expected 1 match, got %d", matches)
+	}
+
+	t.Run("long lines are truncated", func(t *testing.T) {
+		lm := newLogMatcher(regexp.MustCompile(`ready`), func() { t.Error("unexpected match") })
+		if _, err := lm.Write([]byte(strings.Repeat("x", maxLogLineLength*2))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(lm.line) != maxLogLineLength {
+			t.Errorf("expected line to be truncated to %d, got %d", maxLogLineLength, len(lm.line))
+		}
+	})
+}
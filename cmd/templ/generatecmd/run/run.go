@@ -0,0 +1,85 @@
+package run
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Options for running a command.
+type Options struct {
+	// Stdout and Stderr receive the output of the command. If nil, os.Stdout and os.Stderr are used.
+	Stdout io.Writer
+	Stderr io.Writer
+	// OnExit is called when the command exits by itself, rather than being stopped.
+	OnExit ExitHandler
+	// Env contains additional environment variables in the form "KEY=value". They're added to the
+	// environment of the current process, replacing variables with the same key.
+	Env []string
+	// Key identifies the process, so that it's stopped when another process is started with the
+	// same key. If empty, the input is used.
+	Key string
+}
+
+func (o Options) key(input string) string {
+	if o.Key == "" {
+		return input
+	}
+	return o.Key
+}
+
+func (o Options) stdout() io.Writer {
+	if o.Stdout == nil {
+		return os.Stdout
+	}
+	return o.Stdout
+}
+
+func (o Options) stderr() io.Writer {
+	if o.Stderr == nil {
+		return os.Stderr
+	}
+	return o.Stderr
+}
+
+// stopTimeout is how long a process is given to exit after being interrupted, before it's killed.
+const stopTimeout = 5 * time.Second
+
+// Exit is the result of a command that exited by itself, rather than being stopped.
+type Exit struct {
+	// Input is the command that was run.
+	Input string
+	// Err is the error returned by the command, or nil if it exited successfully.
+	Err error
+	// Output is the end of the combined stdout and stderr of the command.
+	Output string
+}
+
+// ExitHandler is called when a command exits by itself.
+type ExitHandler func(e Exit)
+
+// maxOutputSize is the amount of output retained for each command.
+const maxOutputSize = 16 * 1024
+
+// tailWriter retains the end of the output written to it.
+type tailWriter struct {
+	m   sync.Mutex
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (n int, err error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > maxOutputSize {
+		w.buf = append(w.buf[:0], w.buf[len(w.buf)-maxOutputSize:]...)
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return string(w.buf)
+}
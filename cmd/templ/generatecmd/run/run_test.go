@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"syscall"
 	"testing"
 	"time"
@@ -67,7 +68,7 @@ func TestGoRun(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			cmd, err := run.Run(ctx, dir, tt.cmd)
+			cmd, err := run.Run(ctx, dir, tt.cmd, run.Options{})
 			if err != nil {
 				t.Fatalf("failed to run program: %v", err)
 			}
@@ -93,6 +94,84 @@ func TestGoRun(t *testing.T) {
 	}
 }
 
+func TestExitHandler(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows.")
+	}
+	exits := make(chan run.Exit, 1)
+	_, err := run.Run(context.Background(), t.TempDir(), "echo compile error && exit 3", run.Options{
+		Stdout: io.Discard,
+		OnExit: func(e run.Exit) {
+			exits <- e
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to run program: %v", err)
+	}
+	select {
+	case e := <-exits:
+		if e.Err == nil {
+			t.Error("expected an error")
+		}
+		if e.Output != "compile error\n" {
+			t.Errorf("expected output to be captured, got %q", e.Output)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the exit handler")
+	}
+}
+
+func TestEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows.")
+	}
+	t.Setenv("TEMPL_RUN_TEST_OVERRIDDEN", "process")
+	exits := make(chan run.Exit, 1)
+	_, err := run.Run(context.Background(), t.TempDir(), `echo "$TEMPL_RUN_TEST_ADDED $TEMPL_RUN_TEST_OVERRIDDEN"`, run.Options{
+		Stdout: io.Discard,
+		OnExit: func(e run.Exit) {
+			exits <- e
+		},
+		Env: []string{"TEMPL_RUN_TEST_ADDED=added", "TEMPL_RUN_TEST_OVERRIDDEN=option"},
+	})
+	if err != nil {
+		t.Fatalf("failed to run program: %v", err)
+	}
+	select {
+	case e := <-exits:
+		if e.Output != "added option\n" {
+			t.Errorf("expected environment variables to be set, got %q", e.Output)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the exit handler")
+	}
+}
+
+func TestKey(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on Windows.")
+	}
+	defer func() { _ = run.KillAll() }()
+	// Processes with the same input, but different keys, don't stop each other.
+	a, err := run.Run(context.Background(), t.TempDir(), "sleep 10", run.Options{Key: "a"})
+	if err != nil {
+		t.Fatalf("failed to run program: %v", err)
+	}
+	if _, err = run.Run(context.Background(), t.TempDir(), "sleep 10", run.Options{Key: "b"}); err != nil {
+		t.Fatalf("failed to run program: %v", err)
+	}
+	if err := a.Process.Signal(syscall.Signal(0)); err != nil {
+		t.Fatalf("expected process %q to still be running, got %v", "a", err)
+	}
+	// Starting a process with the same key stops the previous one.
+	if _, err = run.Run(context.Background(), t.TempDir(), "sleep 10", run.Options{Key: "a"}); err != nil {
+		t.Fatalf("failed to run program: %v", err)
+	}
+	if err := a.Process.Signal(syscall.Signal(0)); err == nil {
+		t.Fatalf("expected process %q to be stopped", "a")
+	}
+}
+
 func readResponse(url string) (body string, err error) {
 	resp, err := http.Get(url)
 	if err != nil {
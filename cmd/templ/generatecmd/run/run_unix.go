@@ -6,38 +6,59 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
 
+type process struct {
+	cmd     *exec.Cmd
+	done    chan struct{}
+	stopped atomic.Bool
+}
+
 var (
 	m       = &sync.Mutex{}
-	running = map[string]*exec.Cmd{}
+	running = map[string]*process{}
 )
 
+// KillAll stops all running processes. The processes are stopped concurrently, so that they
+// all have the same amount of time to shut down gracefully.
 func KillAll() (err error) {
 	m.Lock()
 	defer m.Unlock()
-	var errs []error
-	for _, cmd := range running {
-		if err := kill(cmd); err != nil {
-			errs = append(errs, fmt.Errorf("failed to kill process %d: %w", cmd.Process.Pid, err))
-		}
+	var wg sync.WaitGroup
+	errs := make([]error, 0, len(running))
+	var errsM sync.Mutex
+	for _, p := range running {
+		wg.Go(func() {
+			if err := kill(p); err != nil {
+				errsM.Lock()
+				defer errsM.Unlock()
+				errs = append(errs, fmt.Errorf("failed to kill process %d: %w", p.cmd.Process.Pid, err))
+			}
+		})
 	}
-	running = map[string]*exec.Cmd{}
+	wg.Wait()
+	running = map[string]*process{}
 	return errors.Join(errs...)
 }
 
-func kill(cmd *exec.Cmd) (err error) {
-	pgid := -cmd.Process.Pid
-	errs := make([]error, 4)
+func kill(p *process) (err error) {
+	p.stopped.Store(true)
+	pgid := -p.cmd.Process.Pid
+	errs := make([]error, 3)
 	errs[0] = ignoreExited(syscall.Kill(pgid, syscall.SIGINT))
 	errs[1] = ignoreExited(syscall.Kill(pgid, syscall.SIGTERM))
-	errs[2] = ignoreExited(cmd.Wait())
-	errs[3] = ignoreExited(syscall.Kill(pgid, syscall.SIGKILL))
+	select {
+	case <-p.done:
+	case <-time.After(stopTimeout):
+	}
+	errs[2] = ignoreExited(syscall.Kill(pgid, syscall.SIGKILL))
 	return errors.Join(errs...)
 }
 
@@ -54,34 +75,50 @@ func ignoreExited(err error) error {
 	return err
 }
 
-func Run(ctx context.Context, workingDir string, input string) (cmd *exec.Cmd, err error) {
+// Run the input using the shell, stopping any process that was previously started with the
+// same key, see Options.Key.
+func Run(ctx context.Context, workingDir string, input string, opts Options) (cmd *exec.Cmd, err error) {
 	m.Lock()
 	defer m.Unlock()
-	cmd, ok := running[input]
+	key := opts.key(input)
+	p, ok := running[key]
 	if ok {
-		if err := kill(cmd); err != nil {
-			return cmd, fmt.Errorf("failed to kill process %d: %w", cmd.Process.Pid, err)
+		if err := kill(p); err != nil {
+			return p.cmd, fmt.Errorf("failed to kill process %d: %w", p.cmd.Process.Pid, err)
 		}
 
-		delete(running, input)
+		delete(running, key)
 	}
 	shell := os.Getenv("SHELL")
 	if shell == "" {
 		shell = "/bin/sh"
 	}
 	cmd = exec.CommandContext(ctx, shell, "-c", input)
+	// Interrupt the process group when the context is done, so that the processes can shut down gracefully.
+	cmd.Cancel = func() error {
+		return ignoreExited(syscall.Kill(-cmd.Process.Pid, syscall.SIGINT))
+	}
 	// Wait for the process to finish gracefully before termination.
 	cmd.WaitDelay = time.Second * 3
-	cmd.Env = os.Environ()
+	cmd.Env = append(os.Environ(), opts.Env...)
 	cmd.Dir = workingDir
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	output := new(tailWriter)
+	cmd.Stdout = io.MultiWriter(opts.stdout(), output)
+	cmd.Stderr = io.MultiWriter(opts.stderr(), output)
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 	if err := cmd.Start(); err != nil {
 		return cmd, err
 	}
-	running[input] = cmd
+	p = &process{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		err := cmd.Wait()
+		close(p.done)
+		if opts.OnExit != nil && !p.stopped.Load() {
+			opts.OnExit(Exit{Input: input, Err: err, Output: output.String()})
+		}
+	}()
+	running[key] = p
 	return cmd, nil
 }
@@ -4,31 +4,50 @@ package run
 
 import (
 	"context"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
 	"sync"
+	"sync/atomic"
 )
 
+type process struct {
+	cmd     *exec.Cmd
+	done    chan struct{}
+	stopped atomic.Bool
+}
+
 var (
 	m       = &sync.Mutex{}
-	running = map[string]*exec.Cmd{}
+	running = map[string]*process{}
 )
 
+// KillAll stops all running processes.
 func KillAll() (err error) {
 	m.Lock()
 	defer m.Unlock()
-	for _, cmd := range running {
-		kill := exec.Command("TASKKILL", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid))
-		kill.Stderr = os.Stderr
-		kill.Stdout = os.Stdout
-		err := kill.Run()
-		if err != nil {
-			return err
-		}
+	var wg sync.WaitGroup
+	errs := make([]error, 0, len(running))
+	var errsM sync.Mutex
+	for _, p := range running {
+		wg.Go(func() {
+			if err := kill(p); err != nil {
+				errsM.Lock()
+				defer errsM.Unlock()
+				errs = append(errs, err)
+			}
+		})
 	}
-	running = map[string]*exec.Cmd{}
-	return
+	wg.Wait()
+	running = map[string]*process{}
+	return errors.Join(errs...)
+}
+
+func kill(p *process) (err error) {
+	p.stopped.Store(true)
+	return Stop(p.cmd)
 }
 
 func Stop(cmd *exec.Cmd) (err error) {
@@ -38,34 +57,42 @@ func Stop(cmd *exec.Cmd) (err error) {
 	return kill.Run()
 }
 
-func Run(ctx context.Context, workingDir string, input string) (cmd *exec.Cmd, err error) {
+// Run the input using the shell, stopping any process that was previously started with the
+// same key, see Options.Key.
+func Run(ctx context.Context, workingDir string, input string, opts Options) (cmd *exec.Cmd, err error) {
 	m.Lock()
 	defer m.Unlock()
-	cmd, ok := running[input]
+	key := opts.key(input)
+	p, ok := running[key]
 	if ok {
-		kill := exec.Command("TASKKILL", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid))
-		kill.Stderr = os.Stderr
-		kill.Stdout = os.Stdout
-		err := kill.Run()
-		if err != nil {
-			return cmd, err
+		if err := kill(p); err != nil {
+			return p.cmd, err
 		}
-		delete(running, input)
+		delete(running, key)
 	}
 	shell := os.Getenv("COMSPEC")
 	if shell == "" {
 		shell = "cmd.exe"
 	}
 	cmd = exec.Command(shell, "/C", input)
-	cmd.Env = os.Environ()
+	cmd.Env = append(os.Environ(), opts.Env...)
 	cmd.Dir = workingDir
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	output := new(tailWriter)
+	cmd.Stdout = io.MultiWriter(opts.stdout(), output)
+	cmd.Stderr = io.MultiWriter(opts.stderr(), output)
 
 	if err := cmd.Start(); err != nil {
 		return cmd, err
 	}
-	running[input] = cmd
+	p = &process{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		err := cmd.Wait()
+		close(p.done)
+		if opts.OnExit != nil && !p.stopped.Load() {
+			opts.OnExit(Exit{Input: input, Err: err, Output: output.String()})
+		}
+	}()
+	running[key] = p
 	return cmd, nil
 }
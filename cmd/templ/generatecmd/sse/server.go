@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +14,7 @@ func New() *Handler {
 	return &Handler{
 		m:        new(sync.Mutex),
 		requests: map[int64]chan event{},
+		retained: map[string]string{},
 	}
 }
 
@@ -20,6 +22,8 @@ type Handler struct {
 	m        *sync.Mutex
 	counter  int64
 	requests map[int64]chan event
+	// retained events are sent to clients when they connect.
+	retained map[string]string
 }
 
 type event struct {
@@ -27,18 +31,40 @@ type event struct {
 	Data string
 }
 
+// eventBufferSize is the number of events that can be queued for a client. If a client
+// doesn't read events quickly enough, the oldest events are dropped, so that the client
+// always receives the latest event.
+const eventBufferSize = 16
+
 // Send an event to all connected clients.
 func (s *Handler) Send(eventType string, data string) {
 	s.m.Lock()
 	defer s.m.Unlock()
-	for _, f := range s.requests {
-		f := f
-		go func(f chan event) {
-			f <- event{
-				Type: eventType,
-				Data: data,
+	s.send(event{Type: eventType, Data: data})
+}
+
+// Retain sends an event to all connected clients, and to clients that connect later. The
+// event replaces any previously retained event of the same type.
+func (s *Handler) Retain(eventType string, data string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.retained[eventType] = data
+	s.send(event{Type: eventType, Data: data})
+}
+
+func (s *Handler) send(e event) {
+	for _, events := range s.requests {
+		select {
+		case events <- e:
+		default:
+			// The client isn't keeping up, so drop the oldest event to make room for the latest.
+			// Events are only sent while the lock is held, so there's room after receiving.
+			select {
+			case <-events:
+			default:
 			}
-		}(f)
+			events <- e
+		}
 	}
 }
 
@@ -51,14 +77,16 @@ func (s *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	id := atomic.AddInt64(&s.counter, 1)
 	s.m.Lock()
-	events := make(chan event)
+	events := make(chan event, eventBufferSize+len(s.retained))
+	for eventType, data := range s.retained {
+		events <- event{Type: eventType, Data: data}
+	}
 	s.requests[id] = events
 	s.m.Unlock()
 	defer func() {
 		s.m.Lock()
 		defer s.m.Unlock()
 		delete(s.requests, id)
-		close(events)
 	}()
 
 	timer := time.NewTimer(0)
@@ -72,7 +100,7 @@ loop:
 			}
 			timer.Reset(time.Second * 5)
 		case e := <-events:
-			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, e.Data); err != nil {
+			if _, err := fmt.Fprintf(w, "event: %s\n%s\n", e.Type, formatData(e.Data)); err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -82,3 +110,15 @@ loop:
 		w.(http.Flusher).Flush()
 	}
 }
+
+// formatData writes each line of the data as a separate data field, so that data containing
+// newlines doesn't end the event early.
+func formatData(data string) string {
+	var sb strings.Builder
+	for line := range strings.SplitSeq(data, "\n") {
+		sb.WriteString("data: ")
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
@@ -0,0 +1,25 @@
+package sse
+
+import "testing"
+
+func TestSendKeepsTheLatestEvents(t *testing.T) {
+	h := New()
+	events := make(chan event, eventBufferSize)
+	h.requests[1] = events
+	for i := range eventBufferSize + 2 {
+		h.Send("message", string(rune('a'+i)))
+	}
+	if len(events) != eventBufferSize {
+		t.Fatalf("expected %d events, got %d", eventBufferSize, len(events))
+	}
+	if first := <-events; first.Data != "c" {
+		t.Errorf("expected the oldest events to be dropped, got %q first", first.Data)
+	}
+	var last event
+	for len(events) > 0 {
+		last = <-events
+	}
+	if expected := string(rune('a' + eventBufferSize + 1)); last.Data != expected {
+		t.Errorf("expected the latest event %q, got %q", expected, last.Data)
+	}
+}
@@ -1,7 +1,9 @@
 package testeventhandler
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"go/scanner"
@@ -16,8 +18,10 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/a-h/templ/cmd/templ/generatecmd"
+	"github.com/a-h/templ/cmd/templ/generatecmd/jsonevents"
 	"github.com/a-h/templ/generator"
 )
 
@@ -236,3 +240,96 @@ func TestErrorLocationMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestWatchedFileUpdated(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	dir := t.TempDir()
+	fseh := generatecmd.NewFSEventHandler(log, dir, true, []generator.GenerateOpt{}, false, false, generatecmd.FileWriter, false)
+
+	tests := []struct {
+		name       string
+		fileName   string
+		cssHotSwap bool
+		expected   generatecmd.GenerateResult
+	}{
+		{
+			name:     "go files restart the command",
+			fileName: "main.go",
+			expected: generatecmd.GenerateResult{WatchedFileUpdated: true},
+		},
+		{
+			name:     "css files restart the command by default",
+			fileName: "embedded.css",
+			expected: generatecmd.GenerateResult{WatchedFileUpdated: true},
+		},
+		{
+			name:       "css files are swapped in the browser when hot swapping is enabled",
+			fileName:   "styles.css",
+			cssHotSwap: true,
+			expected:   generatecmd.GenerateResult{CSSFileUpdated: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fseh.CSSHotSwap = test.cssHotSwap
+			fileName := filepath.Join(dir, test.fileName)
+			if err := os.WriteFile(fileName, []byte{}, 0o644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			result, err := fseh.HandleEvent(context.Background(), fsnotify.Event{Name: fileName, Op: fsnotify.Write})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, result); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestJSONEvents(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+	dir := t.TempDir()
+	fseh := generatecmd.NewFSEventHandler(log, dir, false, []generator.GenerateOpt{}, false, false, generatecmd.FileWriter, false)
+	var buf bytes.Buffer
+	fseh.Events = jsonevents.New(&buf)
+
+	fileName := filepath.Join(dir, "hello.templ")
+	handle := func(contents string, modTime time.Time) {
+		if contents != "" {
+			if err := os.WriteFile(fileName, []byte(contents), 0o644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+		}
+		if err := os.Chtimes(fileName, modTime, modTime); err != nil {
+			t.Fatalf("failed to set file time: %v", err)
+		}
+		_, _ = fseh.HandleEvent(context.Background(), fsnotify.Event{Name: fileName, Op: fsnotify.Write})
+	}
+	start := time.Now()
+	handle("package hello\n\ntempl hello() {\n\t<div>Hello</div>\n}\n", start)
+	handle("", start)
+	handle("package hello\n\ntempl hello() {\n\t<div>Hello\n}\n", start.Add(time.Second))
+
+	var actual []jsonevents.Event
+	for line := range strings.Lines(buf.String()) {
+		var e jsonevents.Event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("failed to unmarshal %q: %v", line, err)
+		}
+		actual = append(actual, e)
+	}
+	expected := []jsonevents.Event{
+		{Type: jsonevents.Generated, File: fileName, GoFileWritten: true},
+		{Type: jsonevents.Skipped, File: fileName, Reason: jsonevents.SkippedUnchanged},
+		{
+			Type:    jsonevents.Error,
+			File:    fileName,
+			Message: "<div>: close tag not found",
+			Range:   &jsonevents.Range{From: jsonevents.Position{Line: 5, Col: 1}, To: jsonevents.Position{Line: 5, Col: 1}},
+		},
+	}
+	if diff := cmp.Diff(expected, actual, cmpopts.IgnoreFields(jsonevents.Event{}, "Time", "DurationMS")); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,204 @@
+package generatecmd
+
+import (
+	"context"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/a-h/templ/parser/v2"
+)
+
+// typeChecker holds generated code until the packages that contain it have been type checked.
+type typeChecker struct {
+	m sync.Mutex
+	// dir is the directory that packages are loaded from.
+	dir    string
+	writer FileWriterFunc
+	// pending is a map of generated Go file names to code that hasn't been written yet.
+	pending map[string]pendingFile
+}
+
+type pendingFile struct {
+	templFileName string
+	// formatted code is written once the package has been type checked.
+	formatted []byte
+	// unformatted code is type checked, because positions in the source map refer to it.
+	unformatted []byte
+	sourceMap   *parser.SourceMap
+}
+
+func newTypeChecker(dir string, writer FileWriterFunc) *typeChecker {
+	return &typeChecker{
+		dir:     dir,
+		writer:  writer,
+		pending: map[string]pendingFile{},
+	}
+}
+
+// add generated code to be type checked. If code has already been added for the file, it is
+// replaced.
+func (tc *typeChecker) add(templFileName, goFileName string, formatted, unformatted []byte, sourceMap *parser.SourceMap) {
+	// Package loading requires absolute paths in the overlay.
+	if abs, err := filepath.Abs(goFileName); err == nil {
+		goFileName = abs
+	}
+	tc.m.Lock()
+	defer tc.m.Unlock()
+	tc.pending[goFileName] = pendingFile{
+		templFileName: templFileName,
+		formatted:     formatted,
+		unformatted:   unformatted,
+		sourceMap:     sourceMap,
+	}
+}
+
+// TypeCheckError is a type error in generated code, or in another file in the same package.
+type TypeCheckError struct {
+	// TemplFileName is the templ file that the error was mapped back to, or empty if the error
+	// isn't in generated code. Generated code with errors isn't written until they're fixed.
+	TemplFileName string
+	// Errors found in the file. Positions are in the templ file, if the error was mapped. Lines
+	// and columns are one based.
+	Errors scanner.ErrorList
+}
+
+func (e TypeCheckError) Error() string {
+	return "type check failed: " + e.Errors.Error()
+}
+
+func (e TypeCheckError) Unwrap() error {
+	return e.Errors
+}
+
+// check type checks the packages that contain pending code. Files without errors are written, even
+// if other files in the package have errors. Files with errors are kept until the next check.
+func (tc *typeChecker) check(ctx context.Context) (written []string, errs []TypeCheckError) {
+	tc.m.Lock()
+	defer tc.m.Unlock()
+	if len(tc.pending) == 0 {
+		return nil, nil
+	}
+	overlay := make(map[string][]byte, len(tc.pending))
+	var dirs []string
+	for goFileName, f := range tc.pending {
+		overlay[goFileName] = f.unformatted
+		if dir := filepath.Dir(goFileName); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	pkgs, err := packages.Load(&packages.Config{
+		Context: ctx,
+		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:     tc.dir,
+		Overlay: overlay,
+	}, dirs...)
+	if err != nil {
+		return nil, []TypeCheckError{{Errors: scanner.ErrorList{{Msg: fmt.Sprintf("failed to load packages: %v", err)}}}}
+	}
+
+	fileNameToErrors := map[string]scanner.ErrorList{}
+	var fileNames []string
+	failed := map[string]bool{}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 {
+			continue
+		}
+		// Loading export data compiles the package, so type and parse errors are also reported
+		// as list errors, which refer to temporary copies of the overlay files.
+		hasSourceErrors := slices.ContainsFunc(pkg.Errors, func(pe packages.Error) bool {
+			return pe.Kind == packages.TypeError || pe.Kind == packages.ParseError
+		})
+		for _, pe := range pkg.Errors {
+			if hasSourceErrors && pe.Kind == packages.ListError {
+				continue
+			}
+			e := parseErrorPosition(pe)
+			if _, ok := fileNameToErrors[e.Pos.Filename]; !ok {
+				fileNames = append(fileNames, e.Pos.Filename)
+			}
+			fileNameToErrors[e.Pos.Filename] = append(fileNameToErrors[e.Pos.Filename], e)
+			// Only the generated files with errors are kept, so that they're checked again next
+			// time. Errors that aren't in a file, e.g. failing to load the package, keep all of
+			// the generated files in the package.
+			if e.Pos.Filename == "" {
+				for _, goFileName := range pkg.CompiledGoFiles {
+					failed[goFileName] = true
+				}
+				continue
+			}
+			failed[e.Pos.Filename] = true
+		}
+	}
+
+	for _, fileName := range fileNames {
+		list := fileNameToErrors[fileName]
+		f, ok := tc.pending[fileName]
+		if !ok {
+			errs = append(errs, TypeCheckError{Errors: list})
+			continue
+		}
+		for _, e := range list {
+			// go/types positions are one based, source map positions are zero based.
+			src, ok := f.sourceMap.SourcePositionFromTarget(uint32(e.Pos.Line-1), uint32(max(e.Pos.Column-1, 0)))
+			if !ok {
+				continue
+			}
+			e.Pos = token.Position{
+				Filename: f.templFileName,
+				Offset:   int(src.Index),
+				Line:     int(src.Line) + 1,
+				Column:   int(src.Col) + 1,
+			}
+		}
+		errs = append(errs, TypeCheckError{TemplFileName: f.templFileName, Errors: list})
+	}
+
+	for goFileName, f := range tc.pending {
+		if failed[goFileName] {
+			continue
+		}
+		if err := tc.writer(goFileName, f.formatted); err != nil {
+			errs = append(errs, TypeCheckError{TemplFileName: f.templFileName, Errors: scanner.ErrorList{{
+				Pos: token.Position{Filename: f.templFileName},
+				Msg: fmt.Sprintf("failed to write target file %q: %v", goFileName, err),
+			}}})
+			continue
+		}
+		delete(tc.pending, goFileName)
+		written = append(written, goFileName)
+	}
+	slices.Sort(written)
+	return written, errs
+}
+
+// parseErrorPosition converts a package error, which has a position of "file:line:col",
+// "file:line", or "-", into a scanner error.
+func parseErrorPosition(pe packages.Error) (e *scanner.Error) {
+	e = &scanner.Error{Msg: pe.Msg}
+	pos := pe.Pos
+	if col, err := strconv.Atoi(pos[strings.LastIndex(pos, ":")+1:]); err == nil {
+		pos = pos[:strings.LastIndex(pos, ":")]
+		if line, err := strconv.Atoi(pos[strings.LastIndex(pos, ":")+1:]); err == nil {
+			e.Pos.Line = line
+			e.Pos.Column = col
+			e.Pos.Filename = pos[:strings.LastIndex(pos, ":")]
+			return e
+		}
+		// The position only had a line.
+		e.Pos.Line = col
+		e.Pos.Filename = pos
+		return e
+	}
+	if pos != "-" {
+		e.Pos.Filename = pos
+	}
+	return e
+}
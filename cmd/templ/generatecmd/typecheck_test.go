@@ -0,0 +1,160 @@
+package generatecmd
+
+import (
+	"context"
+	"go/token"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/a-h/templ/cmd/templ/generatecmd/modcheck"
+	"github.com/a-h/templ/generator"
+)
+
+func TestTypeCheck(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test in short mode.")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	moduleRoot, err := modcheck.WalkUp(wd)
+	if err != nil {
+		t.Fatalf("failed to find module root: %v", err)
+	}
+	dir := t.TempDir()
+	writeFile := func(name, contents string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write %q: %v", name, err)
+		}
+	}
+	writeFile("go.mod", "module example.com/typecheck\n\ngo 1.25\n\nrequire github.com/a-h/templ v0.0.0\n\nreplace github.com/a-h/templ => "+moduleRoot+"\n")
+	writeFile("main.go", "package main\n\nfunc main() {\n\t_ = hello(\"World\")\n}\n")
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	var written []string
+	writer := func(name string, contents []byte) error {
+		written = append(written, filepath.Base(name))
+		return FileWriter(name, contents)
+	}
+	fseh := NewFSEventHandler(log, dir, false, []generator.GenerateOpt{}, false, false, writer, false)
+	tc := newTypeChecker(dir, writer)
+	fseh.typeChecker = tc
+	generate := func(contents string) {
+		t.Helper()
+		writeFile("hello.templ", contents)
+		if _, err := fseh.HandleEvent(context.Background(), fsnotify.Event{Name: filepath.Join(dir, "hello.templ"), Op: fsnotify.Write}); err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+	}
+
+	t.Run("type errors are mapped to the templ file, and the file isn't written", func(t *testing.T) {
+		generate("package main\n\ntempl hello(name string) {\n\t<div>{ name + 1 }</div>\n}\n")
+		written = nil
+		actualWritten, errs := tc.check(context.Background())
+		if len(actualWritten) != 0 || len(written) != 0 {
+			t.Errorf("expected no files to be written, got %v", written)
+		}
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %v", errs)
+		}
+		if errs[0].TemplFileName != filepath.Join(dir, "hello.templ") {
+			t.Errorf("expected the error to be in the templ file, got %q", errs[0].TemplFileName)
+		}
+		msg, line, col, ok := errorPosition(errs[0])
+		if !ok {
+			t.Fatalf("expected the error to have a position")
+		}
+		if line != 4 || col != 9 {
+			t.Errorf("expected error at 4:9, got %d:%d: %s", line, col, msg)
+		}
+		if !strings.Contains(msg, "mismatched types") && !strings.Contains(msg, "cannot convert") {
+			t.Errorf("unexpected message %q", msg)
+		}
+	})
+	t.Run("code is written once the errors are fixed", func(t *testing.T) {
+		generate("package main\n\ntempl hello(name string) {\n\t<div>{ name + \"!\" }</div>\n}\n")
+		written = nil
+		_, errs := tc.check(context.Background())
+		if len(errs) != 0 {
+			t.Fatalf("unexpected errors: %v", errs)
+		}
+		if diff := cmp.Diff([]string{"hello_templ.go"}, written); diff != "" {
+			t.Error(diff)
+		}
+		if len(tc.pending) != 0 {
+			t.Errorf("expected no pending files, got %d", len(tc.pending))
+		}
+	})
+	t.Run("errors in Go files don't prevent generated code in the package from being written", func(t *testing.T) {
+		writeFile("main.go", "package main\n\nfunc main() {\n\t_ = hello(1)\n}\n")
+		generate("package main\n\ntempl hello(name string) {\n\t<div>{ name }</div>\n}\n")
+		written = nil
+		_, errs := tc.check(context.Background())
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %v", errs)
+		}
+		if errs[0].TemplFileName != "" {
+			t.Errorf("expected the error not to be mapped to a templ file, got %q", errs[0].TemplFileName)
+		}
+		if pos := errs[0].Errors[0].Pos; filepath.Base(pos.Filename) != "main.go" || pos.Line != 4 {
+			t.Errorf("expected the error to be in main.go:4, got %v", pos)
+		}
+		if diff := cmp.Diff([]string{"hello_templ.go"}, written); diff != "" {
+			t.Error(diff)
+		}
+		if len(tc.pending) != 0 {
+			t.Errorf("expected no pending files, got %d", len(tc.pending))
+		}
+	})
+	t.Run("only generated files with errors are kept", func(t *testing.T) {
+		writeFile("main.go", "package main\n\nfunc main() {\n\t_ = hello(\"World\")\n}\n")
+		generate("package main\n\ntempl hello(name string) {\n\t<p>{ name }</p>\n}\n")
+		writeFile("other.templ", "package main\n\ntempl other() {\n\t<div>{ 1 + \"a\" }</div>\n}\n")
+		if _, err := fseh.HandleEvent(context.Background(), fsnotify.Event{Name: filepath.Join(dir, "other.templ"), Op: fsnotify.Write}); err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		written = nil
+		_, errs := tc.check(context.Background())
+		if len(errs) != 1 || errs[0].TemplFileName != filepath.Join(dir, "other.templ") {
+			t.Fatalf("expected 1 error in other.templ, got %v", errs)
+		}
+		if diff := cmp.Diff([]string{"hello_templ.go"}, written); diff != "" {
+			t.Error(diff)
+		}
+		if _, ok := tc.pending[filepath.Join(dir, "other_templ.go")]; !ok || len(tc.pending) != 1 {
+			t.Errorf("expected other_templ.go to be pending, got %d pending files", len(tc.pending))
+		}
+	})
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		pos      string
+		expected token.Position
+	}{
+		{pos: "/app/main.go:4:12", expected: token.Position{Filename: "/app/main.go", Line: 4, Column: 12}},
+		{pos: "/app/main.go:4", expected: token.Position{Filename: "/app/main.go", Line: 4}},
+		{pos: `C:\app\main.go:4:12`, expected: token.Position{Filename: `C:\app\main.go`, Line: 4, Column: 12}},
+		{pos: "/app/main.go", expected: token.Position{Filename: "/app/main.go"}},
+		{pos: "-", expected: token.Position{}},
+		{pos: "", expected: token.Position{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pos, func(t *testing.T) {
+			actual := parseErrorPosition(packages.Error{Pos: tt.pos, Msg: "error"})
+			if diff := cmp.Diff(tt.expected, actual.Pos); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
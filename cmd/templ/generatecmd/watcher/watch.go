@@ -34,6 +34,8 @@ func Recursive(
 		Events:        out,
 		Errors:        errors,
 		timers:        make(map[timerKey]*time.Timer),
+		files:         make(map[string]bool),
+		dirs:          make(map[string]bool),
 		loopComplete:  sync.WaitGroup{},
 	}
 	w.loopComplete.Add(1)
@@ -89,6 +91,10 @@ type RecursiveWatcher struct {
 	timerMu       sync.Mutex
 	timers        map[timerKey]*time.Timer
 	loopComplete  sync.WaitGroup
+	// files outside the recursively watched directories, see AddFile.
+	filesMu sync.Mutex
+	files   map[string]bool
+	dirs    map[string]bool
 }
 
 type timerKey struct {
@@ -120,7 +126,11 @@ func (w *RecursiveWatcher) loop() {
 			if !ok {
 				return
 			}
-			if event.Has(fsnotify.Create) {
+			if inDir, isFile := w.isFile(event.Name); inDir {
+				if !isFile {
+					continue
+				}
+			} else if event.Has(fsnotify.Create) {
 				if err := w.Add(event.Name); err != nil {
 					w.Errors <- err
 				}
@@ -173,3 +183,26 @@ func (w *RecursiveWatcher) Add(dir string) error {
 		return w.w.Add(currentPath)
 	})
 }
+
+// AddFile watches a file that's outside the recursively watched directories. Its directory is
+// watched, since editors often replace files rather than writing to them, but events for other
+// files in the directory are ignored.
+func (w *RecursiveWatcher) AddFile(fileName string) error {
+	w.filesMu.Lock()
+	defer w.filesMu.Unlock()
+	dir := filepath.Dir(fileName)
+	if err := w.w.Add(dir); err != nil {
+		return err
+	}
+	w.files[fileName] = true
+	w.dirs[dir] = true
+	return nil
+}
+
+// isFile returns whether the file is in a directory added by AddFile, and whether it's one of
+// the added files.
+func (w *RecursiveWatcher) isFile(fileName string) (inDir, isFile bool) {
+	w.filesMu.Lock()
+	defer w.filesMu.Unlock()
+	return w.dirs[filepath.Dir(fileName)], w.files[fileName]
+}
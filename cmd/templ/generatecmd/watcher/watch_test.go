@@ -3,6 +3,7 @@ package watcher
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"testing"
 	"time"
@@ -205,3 +206,40 @@ func TestIgnorePatternTakesPrecedenceOverWatchPattern(t *testing.T) {
 		_ = rw.Close()
 	}
 }
+
+func TestWatchAddFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan fsnotify.Event, 2)
+	errors := make(chan error)
+	rw, err := Recursive(ctx, regexp.MustCompile(".*"), nil, nil, events, errors)
+	if err != nil {
+		t.Fatal(fmt.Errorf("failed to create recursive watcher: %w", err))
+	}
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	if err = rw.AddFile(envFile); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	go func() {
+		// Other files in the directory are ignored.
+		rw.w.Events <- fsnotify.Event{Name: filepath.Join(dir, "main.go"), Op: fsnotify.Write}
+		rw.w.Events <- fsnotify.Event{Name: envFile, Op: fsnotify.Write}
+	}()
+	var names []string
+	exp := time.After(300 * time.Millisecond)
+	for {
+		select {
+		case event := <-rw.Events:
+			names = append(names, event.Name)
+		case <-exp:
+			if len(names) != 1 || names[0] != envFile {
+				t.Errorf("expected an event for %q, got %v", envFile, names)
+			}
+			cancel()
+			if err := rw.Close(); err != nil {
+				t.Errorf("unexpected error closing watcher: %v", err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package i18ncmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/a-h/templ/cmd/templ/processor"
+	"github.com/a-h/templ/internal/ignorefile"
+	"github.com/a-h/templ/parser/v2"
+	"github.com/natefinch/atomic"
+)
+
+type Arguments struct {
+	// Format of the catalog, "pot" or "xliff".
+	Format string
+	// Output file, or empty to write to stdout.
+	Output string
+	// SourceLanguage of the messages, used in XLIFF catalogs.
+	SourceLanguage string
+	// Paths to files or directories containing templ files.
+	Paths []string
+}
+
+// Message is a translatable message, and the locations it's used in.
+type Message struct {
+	ID         string
+	Plural     string
+	Context    string
+	Args       []string
+	References []Reference
+}
+
+// Reference is the location of a message within a templ file.
+type Reference struct {
+	FileName string
+	// Line is 1-based.
+	Line int
+}
+
+// Extract writes a catalog of the translatable messages within the templ files to the output.
+func Extract(log *slog.Logger, stdout io.Writer, args Arguments) (err error) {
+	if len(args.Paths) == 0 {
+		args.Paths = []string{"."}
+	}
+	if args.Format == "" {
+		args.Format = "pot"
+	}
+	if args.SourceLanguage == "" {
+		args.SourceLanguage = "en"
+	}
+	var write func(w io.Writer, msgs []Message) error
+	switch args.Format {
+	case "pot":
+		write = writePOT
+	case "xliff":
+		write = func(w io.Writer, msgs []Message) error {
+			return writeXLIFF(w, args.SourceLanguage, msgs)
+		}
+	default:
+		return fmt.Errorf("unsupported format %q, expected pot or xliff", args.Format)
+	}
+
+	fileNames, err := findTemplates(args.Paths)
+	if err != nil {
+		return err
+	}
+	var msgs []Message
+	for _, fileName := range fileNames {
+		tf, err := parser.Parse(fileName)
+		if err != nil {
+			return fmt.Errorf("failed to parse %q: %w", fileName, err)
+		}
+		msgs = appendMessages(msgs, filepath.ToSlash(fileName), tf.Messages())
+	}
+	log.Info("Extracted messages", slog.Int("files", len(fileNames)), slog.Int("messages", len(msgs)))
+
+	if args.Output == "" {
+		return write(stdout, msgs)
+	}
+	var buf bytes.Buffer
+	if err = write(&buf, msgs); err != nil {
+		return err
+	}
+	if err = atomic.WriteFile(args.Output, &buf); err != nil {
+		return fmt.Errorf("failed to write %q: %w", args.Output, err)
+	}
+	return nil
+}
+
+// findTemplates returns the templ files within the paths, in a consistent order.
+func findTemplates(paths []string) (fileNames []string, err error) {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			fileNames = append(fileNames, path)
+			continue
+		}
+		shouldSkip, err := ignorefile.ShouldSkipFunc(path, ".templignore_i18n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse .templignore_i18n: %w", err)
+		}
+		templates := make(chan string)
+		errs := make(chan error, 1)
+		go func() {
+			defer close(templates)
+			errs <- processor.FindTemplates(path, shouldSkip, templates)
+		}()
+		for fileName := range templates {
+			fileNames = append(fileNames, fileName)
+		}
+		if err = <-errs; err != nil {
+			return nil, err
+		}
+	}
+	slices.Sort(fileNames)
+	return slices.Compact(fileNames), nil
+}
+
+// appendMessages adds the messages to the catalog. Messages that are already in the catalog
+// have the reference added.
+func appendMessages(catalog []Message, fileName string, msgs []parser.Message) []Message {
+	for _, msg := range msgs {
+		ref := Reference{FileName: fileName, Line: int(msg.Range.From.Line) + 1}
+		index := slices.IndexFunc(catalog, func(m Message) bool {
+			return m.ID == msg.ID && m.Context == msg.Context
+		})
+		if index >= 0 {
+			if catalog[index].Plural == "" {
+				catalog[index].Plural = msg.Plural
+			}
+			catalog[index].References = append(catalog[index].References, ref)
+			continue
+		}
+		m := Message{
+			ID:         msg.ID,
+			Plural:     msg.Plural,
+			Context:    msg.Context,
+			References: []Reference{ref},
+		}
+		for _, arg := range msg.Args {
+			m.Args = append(m.Args, arg.Value)
+		}
+		catalog = append(catalog, m)
+	}
+	return catalog
+}
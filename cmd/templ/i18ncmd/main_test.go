@@ -0,0 +1,76 @@
+package i18ncmd
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTemplate = `package test
+
+templ Greeting(name string) {
+	<p i18n>Hello, { name }!</p>
+	<a i18n i18n-context="menu" title="Go home" href="/">Home</a>
+}
+
+templ Items(n int) {
+	<p i18n i18n-count={ n } i18n-plural="{ n } items">One item</p>
+	<p i18n>Hello, { name }!</p>
+}
+`
+
+func extract(t *testing.T, format string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "template.templ"), []byte(testTemplate), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	var stdout bytes.Buffer
+	if err := Extract(log, &stdout, Arguments{Format: format, Paths: []string{dir}}); err != nil {
+		t.Fatalf("failed to extract: %v", err)
+	}
+	return strings.ReplaceAll(stdout.String(), filepath.ToSlash(dir)+"/", "")
+}
+
+func TestExtractPOT(t *testing.T) {
+	actual := extract(t, "pot")
+	expected := []string{
+		"#. Keep the placeholders: { name }\n#: template.templ:4\n#: template.templ:10\nmsgid \"Hello, { name }!\"\nmsgstr \"\"\n",
+		"#: template.templ:5\nmsgctxt \"menu\"\nmsgid \"Go home\"\nmsgstr \"\"\n",
+		"#: template.templ:5\nmsgctxt \"menu\"\nmsgid \"Home\"\nmsgstr \"\"\n",
+		"#. Keep the placeholders: { n }\n#: template.templ:9\nmsgid \"One item\"\nmsgid_plural \"{ n } items\"\nmsgstr[0] \"\"\nmsgstr[1] \"\"\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(actual, e) {
+			t.Errorf("expected output to contain:\n%s\ngot:\n%s", e, actual)
+		}
+	}
+}
+
+func TestExtractXLIFF(t *testing.T) {
+	actual := extract(t, "xliff")
+	expected := []string{
+		`<file source-language="en" datatype="plaintext" original="templ">`,
+		`<source>Hello, { name }!</source>`,
+		`<context context-type="x-gettext-msgctxt">menu</context>`,
+		`restype="x-gettext-plurals"`,
+		`<source>{ n } items</source>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(actual, e) {
+			t.Errorf("expected output to contain %q, got:\n%s", e, actual)
+		}
+	}
+}
+
+func TestExtractUnsupportedFormat(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+	if err := Extract(log, io.Discard, Arguments{Format: "json", Paths: []string{t.TempDir()}}); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
@@ -0,0 +1,63 @@
+package i18ncmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// writePOT writes the messages as a gettext .pot template, which translators copy to a .po
+// file for each locale.
+func writePOT(w io.Writer, msgs []Message) error {
+	bw := bufio.NewWriter(w)
+	_, _ = fmt.Fprintln(bw, `# Translatable messages extracted from templ files with "templ i18n extract".`)
+	_, _ = fmt.Fprintln(bw, `#`)
+	_, _ = fmt.Fprintln(bw, `msgid ""`)
+	_, _ = fmt.Fprintln(bw, `msgstr ""`)
+	_, _ = fmt.Fprintln(bw, poQuote("MIME-Version: 1.0\n"))
+	_, _ = fmt.Fprintln(bw, poQuote("Content-Type: text/plain; charset=UTF-8\n"))
+	_, _ = fmt.Fprintln(bw, poQuote("Content-Transfer-Encoding: 8bit\n"))
+	_, _ = fmt.Fprintln(bw, poQuote("X-Generator: templ "+templ.Version()+"\n"))
+	for _, msg := range msgs {
+		_, _ = fmt.Fprintln(bw)
+		if note := placeholderNote(msg); note != "" {
+			_, _ = fmt.Fprintf(bw, "#. %s\n", note)
+		}
+		for _, ref := range msg.References {
+			_, _ = fmt.Fprintf(bw, "#: %s:%d\n", ref.FileName, ref.Line)
+		}
+		if msg.Context != "" {
+			_, _ = fmt.Fprintf(bw, "msgctxt %s\n", poQuote(msg.Context))
+		}
+		_, _ = fmt.Fprintf(bw, "msgid %s\n", poQuote(msg.ID))
+		if msg.Plural != "" {
+			_, _ = fmt.Fprintf(bw, "msgid_plural %s\n", poQuote(msg.Plural))
+			_, _ = fmt.Fprintln(bw, `msgstr[0] ""`)
+			_, _ = fmt.Fprintln(bw, `msgstr[1] ""`)
+			continue
+		}
+		_, _ = fmt.Fprintln(bw, `msgstr ""`)
+	}
+	return bw.Flush()
+}
+
+// placeholderNote returns a note for translators that lists the placeholders in the message.
+func placeholderNote(msg Message) string {
+	if len(msg.Args) == 0 {
+		return ""
+	}
+	placeholders := make([]string, len(msg.Args))
+	for i, arg := range msg.Args {
+		placeholders[i] = "{ " + strings.TrimSpace(arg) + " }"
+	}
+	return "Keep the placeholders: " + strings.Join(placeholders, ", ")
+}
+
+var poEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+func poQuote(s string) string {
+	return `"` + poEscaper.Replace(s) + `"`
+}
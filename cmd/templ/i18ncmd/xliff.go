@@ -0,0 +1,124 @@
+package i18ncmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type xliffDocument struct {
+	XMLName xml.Name  `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string    `xml:"version,attr"`
+	File    xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	SourceLanguage string    `xml:"source-language,attr"`
+	Datatype       string    `xml:"datatype,attr"`
+	Original       string    `xml:"original,attr"`
+	Body           xliffBody `xml:"body"`
+}
+
+type xliffBody struct {
+	Items []any
+}
+
+type xliffGroup struct {
+	XMLName    xml.Name         `xml:"group"`
+	ID         string           `xml:"id,attr"`
+	Restype    string           `xml:"restype,attr"`
+	TransUnits []xliffTransUnit `xml:"trans-unit"`
+}
+
+type xliffTransUnit struct {
+	XMLName       xml.Name            `xml:"trans-unit"`
+	ID            string              `xml:"id,attr"`
+	Source        string              `xml:"source"`
+	ContextGroups []xliffContextGroup `xml:"context-group"`
+	Notes         []xliffNote         `xml:"note"`
+}
+
+type xliffContextGroup struct {
+	Purpose  string         `xml:"purpose,attr"`
+	Contexts []xliffContext `xml:"context"`
+}
+
+type xliffContext struct {
+	ContextType string `xml:"context-type,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type xliffNote struct {
+	From  string `xml:"from,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeXLIFF writes the messages as an XLIFF 1.2 document. Plural messages are written as a
+// group of the singular and plural forms, using the gettext convention.
+func writeXLIFF(w io.Writer, sourceLanguage string, msgs []Message) error {
+	doc := xliffDocument{
+		Version: "1.2",
+		File: xliffFile{
+			SourceLanguage: sourceLanguage,
+			Datatype:       "plaintext",
+			Original:       "templ",
+		},
+	}
+	for _, msg := range msgs {
+		id := messageID(msg)
+		unit := xliffTransUnit{
+			ID:     id,
+			Source: msg.ID,
+		}
+		for _, ref := range msg.References {
+			unit.ContextGroups = append(unit.ContextGroups, xliffContextGroup{
+				Purpose: "location",
+				Contexts: []xliffContext{
+					{ContextType: "sourcefile", Value: ref.FileName},
+					{ContextType: "linenumber", Value: strconv.Itoa(ref.Line)},
+				},
+			})
+		}
+		if msg.Context != "" {
+			unit.ContextGroups = append(unit.ContextGroups, xliffContextGroup{
+				Purpose:  "information",
+				Contexts: []xliffContext{{ContextType: "x-gettext-msgctxt", Value: msg.Context}},
+			})
+		}
+		if note := placeholderNote(msg); note != "" {
+			unit.Notes = append(unit.Notes, xliffNote{From: "developer", Value: note})
+		}
+		if msg.Plural == "" {
+			doc.File.Body.Items = append(doc.File.Body.Items, unit)
+			continue
+		}
+		singular, plural := unit, unit
+		singular.ID = id + "[0]"
+		plural.ID = id + "[1]"
+		plural.Source = msg.Plural
+		doc.File.Body.Items = append(doc.File.Body.Items, xliffGroup{
+			ID:         id,
+			Restype:    "x-gettext-plurals",
+			TransUnits: []xliffTransUnit{singular, plural},
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write XLIFF: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// messageID returns a stable ID for the message, derived from its context and text.
+func messageID(msg Message) string {
+	h := sha256.Sum256([]byte(msg.Context + "\x04" + msg.ID))
+	return hex.EncodeToString(h[:8])
+}
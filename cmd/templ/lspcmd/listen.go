@@ -0,0 +1,205 @@
+package lspcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/a-h/templ/lsp/jsonrpc2"
+	"golang.org/x/net/websocket"
+)
+
+// listenAddress is a parsed -listen argument, e.g. tcp://127.0.0.1:7575 or ws://127.0.0.1:7575/lsp.
+type listenAddress struct {
+	Scheme string
+	Host   string
+	Path   string
+}
+
+func parseListenAddress(s string) (la listenAddress, err error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return la, fmt.Errorf("invalid listen address %q: %w", s, err)
+	}
+	if u.Host == "" {
+		return la, fmt.Errorf("invalid listen address %q: expected a host and port, e.g. tcp://127.0.0.1:7575", s)
+	}
+	switch u.Scheme {
+	case "tcp":
+		if u.Path != "" && u.Path != "/" {
+			return la, fmt.Errorf("invalid listen address %q: tcp addresses do not have a path", s)
+		}
+	case "ws":
+		if u.Path == "" {
+			u.Path = "/"
+		}
+	default:
+		return la, fmt.Errorf("invalid listen address %q: unsupported scheme %q, expected tcp or ws", s, u.Scheme)
+	}
+	return listenAddress{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   u.Path,
+	}, nil
+}
+
+// sessionHandler serves a single editor connection until the stream is closed.
+type sessionHandler func(ctx context.Context, log *slog.Logger, stream jsonrpc2.Stream) error
+
+// listen serves each connection to the address as an independent LSP session, with its own
+// gopls process, so that a single long-lived server can be shared between editor windows.
+func listen(ctx context.Context, log *slog.Logger, args Arguments) (err error) {
+	la, err := parseListenAddress(args.Listen)
+	if err != nil {
+		return err
+	}
+	if args.HTTPDebug != "" {
+		log.Warn("the http debug server is not available when listening for connections, ignoring")
+		args.HTTPDebug = ""
+	}
+	ln, err := net.Listen("tcp", la.Host)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", la.Host, err)
+	}
+	log.Info("lsp: listening", slog.String("scheme", la.Scheme), slog.String("addr", ln.Addr().String()))
+	handler := func(ctx context.Context, log *slog.Logger, stream jsonrpc2.Stream) error {
+		return run(ctx, log, stream, args)
+	}
+	if la.Scheme == "ws" {
+		return serveWebSocket(ctx, log, ln, la.Path, args.AllowedOrigins, handler)
+	}
+	return serveTCP(ctx, log, ln, handler)
+}
+
+// serveTCP serves LSP sessions over TCP, using the same Content-Length framing as stdio.
+func serveTCP(ctx context.Context, log *slog.Logger, ln net.Listener, handler sessionHandler) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sessionLog := log.With(slog.String("remote", conn.RemoteAddr().String()))
+			runSession(ctx, sessionLog, jsonrpc2.NewStream(conn), handler)
+		}()
+	}
+}
+
+// serveWebSocket serves LSP sessions over WebSockets. Each WebSocket message contains
+// a single JSON-RPC message without Content-Length headers, as expected by browser-based
+// language clients.
+func serveWebSocket(ctx context.Context, log *slog.Logger, ln net.Listener, path string, allowedOrigins []string, handler sessionHandler) error {
+	var sessions sessionGroup
+	ws := websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			return checkWebSocketOrigin(r, allowedOrigins)
+		},
+		Handler: func(conn *websocket.Conn) {
+			if !sessions.add() {
+				// The server is shutting down.
+				return
+			}
+			defer sessions.done()
+			sessionLog := log.With(slog.String("remote", conn.Request().RemoteAddr))
+			runSession(ctx, sessionLog, jsonrpc2.NewRawStream(conn), handler)
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, ws)
+	srv := &http.Server{
+		Handler: mux,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	err := srv.Serve(ln)
+	sessions.wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// sessionGroup waits for sessions to end. Unlike a sync.WaitGroup, sessions can be started
+// concurrently with wait, e.g. by connections that were accepted before the server closed.
+type sessionGroup struct {
+	m      sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// add starts a session, returning false if the group is waiting, and no more sessions can start.
+func (g *sessionGroup) add() bool {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed {
+		return false
+	}
+	g.wg.Add(1)
+	return true
+}
+
+func (g *sessionGroup) done() {
+	g.wg.Done()
+}
+
+// wait prevents new sessions from starting, and waits for running sessions to end.
+func (g *sessionGroup) wait() {
+	g.m.Lock()
+	g.closed = true
+	g.m.Unlock()
+	g.wg.Wait()
+}
+
+func runSession(ctx context.Context, log *slog.Logger, stream jsonrpc2.Stream, handler sessionHandler) {
+	log.Info("lsp: session started")
+	if err := handler(ctx, log, stream); err != nil {
+		log.Error("lsp: session failed", slog.Any("error", err))
+	}
+	if err := stream.Close(); err != nil {
+		log.Debug("lsp: failed to close session stream", slog.Any("error", err))
+	}
+	log.Info("lsp: session ended")
+}
+
+// checkWebSocketOrigin prevents web pages on other origins from connecting to the language
+// server through the user's browser. Clients that don't send an Origin header, such as editors,
+// are always allowed.
+func checkWebSocketOrigin(r *http.Request, allowedOrigins []string) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	if slices.Contains(allowedOrigins, "*") || slices.Contains(allowedOrigins, origin) {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("invalid origin %q: %w", origin, err)
+	}
+	if u.Host == r.Host {
+		return nil
+	}
+	return fmt.Errorf("origin %q is not allowed, use -allowed-origin to allow it", origin)
+}
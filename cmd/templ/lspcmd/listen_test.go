@@ -0,0 +1,184 @@
+package lspcmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ/lsp/jsonrpc2"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/websocket"
+)
+
+func TestParseListenAddress(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    listenAddress
+		expectedErr bool
+	}{
+		{
+			input:    "tcp://127.0.0.1:7575",
+			expected: listenAddress{Scheme: "tcp", Host: "127.0.0.1:7575"},
+		},
+		{
+			input:    "ws://127.0.0.1:7575",
+			expected: listenAddress{Scheme: "ws", Host: "127.0.0.1:7575", Path: "/"},
+		},
+		{
+			input:    "ws://localhost:7575/lsp",
+			expected: listenAddress{Scheme: "ws", Host: "localhost:7575", Path: "/lsp"},
+		},
+		{
+			input:       "tcp://127.0.0.1:7575/lsp",
+			expectedErr: true,
+		},
+		{
+			input:       "http://127.0.0.1:7575",
+			expectedErr: true,
+		},
+		{
+			input:       "127.0.0.1:7575",
+			expectedErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := parseListenAddress(tt.input)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+// echoSession replies to every request with the name of the method that was called.
+func echoSession(ctx context.Context, log *slog.Logger, stream jsonrpc2.Stream) error {
+	conn := jsonrpc2.NewConn(stream)
+	conn.Go(ctx, func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+		return reply(ctx, req.Method(), nil)
+	})
+	select {
+	case <-ctx.Done():
+	case <-conn.Done():
+	}
+	return nil
+}
+
+func callEcho(t *testing.T, ctx context.Context, stream jsonrpc2.Stream, method string) {
+	t.Helper()
+	conn := jsonrpc2.NewConn(stream)
+	conn.Go(ctx, jsonrpc2.MethodNotFoundHandler)
+	defer func() {
+		_ = conn.Close()
+	}()
+	var result string
+	if _, err := conn.Call(ctx, method, nil, &result); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if result != method {
+		t.Errorf("expected %q, got %q", method, result)
+	}
+}
+
+func TestServeTCP(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serveTCP(ctx, log, ln, echoSession)
+	}()
+
+	// Multiple clients can share the server.
+	for _, method := range []string{"initialize", "shutdown"} {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		callEcho(t, ctx, jsonrpc2.NewStream(conn), method)
+	}
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServeWebSocket(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- serveWebSocket(ctx, log, ln, "/lsp", []string{"http://editor.example.com"}, echoSession)
+	}()
+
+	t.Run("allowed origins can connect", func(t *testing.T) {
+		conn, err := websocket.Dial("ws://"+ln.Addr().String()+"/lsp", "", "http://editor.example.com")
+		if err != nil {
+			t.Fatalf("failed to dial: %v", err)
+		}
+		callEcho(t, ctx, jsonrpc2.NewRawStream(conn), "initialize")
+	})
+	t.Run("other origins are rejected", func(t *testing.T) {
+		_, err := websocket.Dial("ws://"+ln.Addr().String()+"/lsp", "", "http://attacker.example.com")
+		if err == nil {
+			t.Fatal("expected the connection to be rejected")
+		}
+	})
+
+	cancel()
+	if err := <-errs; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckWebSocketOrigin(t *testing.T) {
+	tests := []struct {
+		name           string
+		origin         string
+		allowedOrigins []string
+		expectedErr    bool
+	}{
+		{name: "no origin", origin: ""},
+		{name: "same host", origin: "http://example.com"},
+		{name: "allowed origin", origin: "http://editor.example.com", allowedOrigins: []string{"http://editor.example.com"}},
+		{name: "wildcard", origin: "http://editor.example.com", allowedOrigins: []string{"*"}},
+		{name: "other origin", origin: "http://editor.example.com", expectedErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "http://example.com/lsp", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			err := checkWebSocketOrigin(r, tt.allowedOrigins)
+			if tt.expectedErr != (err != nil) {
+				t.Errorf("expected error=%v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -970,6 +970,11 @@ func (tc TestClient) Configuration(ctx context.Context, params *protocol.Configu
 	return nil, nil
 }
 
+func (tc TestClient) ShowDocument(ctx context.Context, params *protocol.ShowDocumentParams) (result *protocol.ShowDocumentResult, err error) {
+	tc.log.Info("client: Received ShowDocument", slog.Any("params", params))
+	return nil, nil
+}
+
 func (tc TestClient) WorkspaceFolders(ctx context.Context) (result []protocol.WorkspaceFolder, err error) {
 	tc.log.Info("client: Received WorkspaceFolders")
 	return nil, nil
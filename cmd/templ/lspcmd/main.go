@@ -31,6 +31,11 @@ type Arguments struct {
 	// NoPreload disables preloading of templ files on server startup (useful for large monorepos)
 	NoPreload    bool
 	FormatConfig format.Config
+	// Listen sets the address to accept LSP connections on, e.g. tcp://127.0.0.1:7575 or
+	// ws://127.0.0.1:7575/lsp. Leave empty to communicate over stdin and stdout.
+	Listen string
+	// AllowedOrigins are the web origins allowed to connect when listening for WebSocket connections.
+	AllowedOrigins []string
 }
 
 func Run(stdin io.Reader, stdout, stderr io.Writer, args Arguments) (err error) {
@@ -70,6 +75,9 @@ func Run(stdin io.Reader, stdout, stderr io.Writer, args Arguments) (err error)
 		log = slog.New(slog.NewJSONHandler(file, nil))
 		log.Debug("Logging to file", slog.String("file", args.Log))
 	}
+	if args.Listen != "" {
+		return listen(ctx, log, args)
+	}
 	templStream := jsonrpc2.NewStream(newStdRwc(log, "templStream", stdout, stdin))
 	return run(ctx, log, templStream, args)
 }
@@ -90,7 +98,7 @@ func run(ctx context.Context, log *slog.Logger, templStream jsonrpc2.Stream, arg
 	})
 	if err != nil {
 		log.Error("failed to start gopls", slog.Any("error", err))
-		os.Exit(1)
+		return fmt.Errorf("failed to start gopls: %w", err)
 	}
 	log.Info("found gopls", slog.String("location", goplsLocation))
 
@@ -0,0 +1,262 @@
+// Package preview renders templ components in the browser, without wiring up a route in the
+// application.
+//
+// Each component is rendered by a throwaway main package that is compiled and run with the Go
+// toolchain. The main package, and the code generated by the language server for templ files
+// that haven't been saved, are added to the build with an overlay, so nothing is written to the
+// module.
+package preview
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ/cmd/templ/generatecmd/modcheck"
+)
+
+// GeneratedCodeFunc returns Go code that has been generated for templ files in the directory, but
+// may not have been written to disk. The map is keyed by the absolute path of the Go file.
+type GeneratedCodeFunc func(dir string) map[string]string
+
+// renderTimeout is the maximum time allowed to compile and run the component.
+const renderTimeout = time.Minute
+
+// readHeaderTimeout is the maximum time allowed to read the headers of a request. There's no
+// write timeout, because rendering compiles the component.
+const readHeaderTimeout = 10 * time.Second
+
+// New creates a preview server. The server starts listening the first time a URL is requested.
+func New(log *slog.Logger, generatedCode GeneratedCodeFunc) *Server {
+	return &Server{
+		log:           log,
+		generatedCode: generatedCode,
+		token:         newToken(),
+		goCommand:     "go",
+	}
+}
+
+// Server renders templ components over HTTP.
+type Server struct {
+	log           *slog.Logger
+	generatedCode GeneratedCodeFunc
+	// token is part of every URL, so that other websites can't ask the server to run code.
+	token     string
+	goCommand string
+
+	m       sync.Mutex
+	server  *http.Server
+	baseURL string
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// URL of a page that renders the component. The component is a templ or function in the package
+// in dir that takes no arguments. The server is started if it isn't already running.
+func (s *Server) URL(dir, component string) (string, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.baseURL == "" {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return "", fmt.Errorf("failed to start preview server: %w", err)
+		}
+		s.server = &http.Server{
+			Handler:           s,
+			ReadHeaderTimeout: readHeaderTimeout,
+		}
+		go func(server *http.Server) {
+			if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.log.Error("preview server failed", slog.Any("error", err))
+			}
+		}(s.server)
+		s.baseURL = "http://" + l.Addr().String()
+		s.log.Info("preview server started", slog.String("url", s.baseURL))
+	}
+	q := url.Values{}
+	q.Set("dir", dir)
+	q.Set("component", component)
+	return s.baseURL + "/" + s.token + "?" + q.Encode(), nil
+}
+
+// Close stops the server, if it's running, and closes its connections, which cancels previews
+// that are being rendered. The server is started again if another URL is requested.
+func (s *Server) Close() (err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.server == nil {
+		return nil
+	}
+	err = s.server.Close()
+	s.server = nil
+	s.baseURL = ""
+	return err
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/"+s.token {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	dir, component := r.URL.Query().Get("dir"), r.URL.Query().Get("component")
+	if !filepath.IsAbs(dir) || !token.IsIdentifier(component) {
+		http.Error(w, "invalid dir or component", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), renderTimeout)
+	defer cancel()
+	html, err := s.Render(ctx, dir, component)
+	if err != nil {
+		s.log.Info("preview failed", slog.String("dir", dir), slog.String("component", component), slog.Any("error", err))
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(html)
+}
+
+// ErrMainPackage is returned when the component is in a main package, which can't be imported
+// by the throwaway main package.
+var ErrMainPackage = errors.New("components in package main can't be previewed, move the component to another package")
+
+// Render the component in the package in dir, and return the HTML.
+func (s *Server) Render(ctx context.Context, dir, component string) (html []byte, err error) {
+	moduleRoot, err := modcheck.WalkUp(dir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "templ-preview-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+	o := overlay{Replace: map[string]string{}}
+	addFile := func(fileName, contents string) error {
+		tmpFileName := filepath.Join(tmp, fmt.Sprintf("%d.go", len(o.Replace)))
+		if err := os.WriteFile(tmpFileName, []byte(contents), 0o644); err != nil {
+			return fmt.Errorf("failed to write overlay file: %w", err)
+		}
+		o.Replace[fileName] = tmpFileName
+		return nil
+	}
+	for fileName, contents := range s.generatedCode(dir) {
+		if err := addFile(fileName, contents); err != nil {
+			return nil, err
+		}
+	}
+	overlayFileName := filepath.Join(tmp, "overlay.json")
+	writeOverlay := func() error {
+		b, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(overlayFileName, b, 0o644)
+	}
+	if err = writeOverlay(); err != nil {
+		return nil, fmt.Errorf("failed to write overlay: %w", err)
+	}
+
+	// Find the package, taking unsaved code into account.
+	out, err := s.run(ctx, dir, "list", "-overlay", overlayFileName, "-f", "{{.ImportPath}} {{.Name}}", ".")
+	if err != nil {
+		return nil, err
+	}
+	importPath, packageName, _ := strings.Cut(strings.TrimSpace(string(out)), " ")
+	if packageName == "main" {
+		return nil, ErrMainPackage
+	}
+
+	// The component may not be exported, so export it from a file added to its package.
+	if err = addFile(filepath.Join(dir, "templ_preview_export.go"), exportFile(packageName, component)); err != nil {
+		return nil, err
+	}
+	// The main package is created in a directory that's ignored by ./... patterns.
+	mainDir := filepath.Join(moduleRoot, "_templpreview")
+	if err = addFile(filepath.Join(mainDir, "main.go"), mainFile(importPath)); err != nil {
+		return nil, err
+	}
+	if err = writeOverlay(); err != nil {
+		return nil, fmt.Errorf("failed to write overlay: %w", err)
+	}
+	return s.run(ctx, moduleRoot, "run", "-overlay", overlayFileName, "./_templpreview")
+}
+
+type overlay struct {
+	Replace map[string]string
+}
+
+func (s *Server) run(ctx context.Context, dir string, args ...string) (stdout []byte, err error) {
+	cmd := exec.CommandContext(ctx, s.goCommand, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go %s failed: %w\n%s", args[0], err, stderr.String())
+	}
+	return stdout, nil
+}
+
+// exportName is the name of the function that exports the component from its package. It uses
+// the prefix that's reserved for generated code, so that it doesn't clash with names in the
+// package, but must start with an upper case letter to be exported.
+const exportName = "Templ_7745c5c3_Preview"
+
+func exportFile(packageName, component string) string {
+	return fmt.Sprintf(`package %s
+
+import templ_7745c5c3_Preview "github.com/a-h/templ"
+
+func %s() templ_7745c5c3_Preview.Component {
+	return %s()
+}
+`, packageName, exportName, component)
+}
+
+func mainFile(importPath string) string {
+	return fmt.Sprintf(`package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	component %q
+)
+
+func main() {
+	if err := component.%s().Render(context.Background(), os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`, importPath, exportName)
+}
@@ -0,0 +1,240 @@
+package preview
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ/cmd/templ/generatecmd/modcheck"
+)
+
+func TestRender(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test in short mode.")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	moduleRoot, err := modcheck.WalkUp(wd)
+	if err != nil {
+		t.Fatalf("failed to find module root: %v", err)
+	}
+	dir := t.TempDir()
+	writeFile := func(name, contents string) {
+		t.Helper()
+		fileName := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fileName), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fileName, []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write %q: %v", name, err)
+		}
+	}
+	writeFile("go.mod", "module example.com/preview\n\ngo 1.25\n\nrequire github.com/a-h/templ v0.0.0\n\nreplace github.com/a-h/templ => "+moduleRoot+"\n")
+	writeFile("go.sum", "")
+	writeFile("main.go", "package main\n\nimport \"github.com/a-h/templ\"\n\nfunc main() {}\n\nfunc hello() templ.Component { return templ.Raw(\"main\") }\n")
+	writeFile("components/components.go", `package components
+
+import "github.com/a-h/templ"
+
+func hello() templ.Component {
+	return templ.Raw("<p>Hello</p>")
+}
+
+func ExampleButton() templ.Component {
+	return templ.Raw("<button>Example</button>")
+}
+
+func TemplPreview() templ.Component {
+	return templ.Raw("<p>Preview</p>")
+}
+`)
+	// The generated code for the templ file only exists in the language server.
+	generated := map[string]map[string]string{
+		filepath.Join(dir, "components"): {
+			filepath.Join(dir, "components", "unsaved_templ.go"): `package components
+
+import "github.com/a-h/templ"
+
+func unsaved() templ.Component {
+	return templ.Raw("<p>Unsaved</p>")
+}
+`,
+		},
+	}
+	s := New(slog.New(slog.DiscardHandler), func(dir string) map[string]string {
+		return generated[dir]
+	})
+
+	tests := []struct {
+		name      string
+		dir       string
+		component string
+		expected  string
+	}{
+		{
+			name:      "unexported components can be rendered",
+			dir:       filepath.Join(dir, "components"),
+			component: "hello",
+			expected:  "<p>Hello</p>",
+		},
+		{
+			name:      "examples can be rendered",
+			dir:       filepath.Join(dir, "components"),
+			component: "ExampleButton",
+			expected:  "<button>Example</button>",
+		},
+		{
+			name:      "components can have any exported name",
+			dir:       filepath.Join(dir, "components"),
+			component: "TemplPreview",
+			expected:  "<p>Preview</p>",
+		},
+		{
+			name:      "generated code that hasn't been written is used",
+			dir:       filepath.Join(dir, "components"),
+			component: "unsaved",
+			expected:  "<p>Unsaved</p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := s.Render(context.Background(), tt.dir, tt.component)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, string(actual))
+			}
+		})
+	}
+	t.Run("components in package main can't be previewed", func(t *testing.T) {
+		_, err := s.Render(context.Background(), dir, "hello")
+		if !errors.Is(err, ErrMainPackage) {
+			t.Errorf("expected ErrMainPackage, got %v", err)
+		}
+	})
+	t.Run("compilation errors are returned", func(t *testing.T) {
+		_, err := s.Render(context.Background(), filepath.Join(dir, "components"), "missing")
+		if err == nil || !strings.Contains(err.Error(), "undefined: missing") {
+			t.Errorf("expected compilation error, got %v", err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(dir, "_templpreview")); !os.IsNotExist(err) {
+		t.Errorf("expected the main package not to be written to disk, got %v", err)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := New(slog.New(slog.DiscardHandler), func(dir string) map[string]string { return nil })
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		query    url.Values
+		expected int
+	}{
+		{
+			name:     "requests without the token are not found",
+			method:   http.MethodGet,
+			path:     "/",
+			query:    url.Values{"dir": {"/app"}, "component": {"hello"}},
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "only GET is allowed",
+			method:   http.MethodPost,
+			path:     "/" + s.token,
+			query:    url.Values{"dir": {"/app"}, "component": {"hello"}},
+			expected: http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "the directory must be absolute",
+			method:   http.MethodGet,
+			path:     "/" + s.token,
+			query:    url.Values{"dir": {"app"}, "component": {"hello"}},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "the component must be an identifier",
+			method:   http.MethodGet,
+			path:     "/" + s.token,
+			query:    url.Values{"dir": {"/app"}, "component": {"hello(); os.Exit(1)"}},
+			expected: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path+"?"+tt.query.Encode(), nil)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.expected {
+				body, _ := io.ReadAll(w.Body)
+				t.Errorf("expected status %d, got %d: %s", tt.expected, w.Code, body)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	s := New(slog.New(slog.DiscardHandler), func(dir string) map[string]string { return nil })
+	u, err := s.URL("/app/components", "hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		t.Fatalf("invalid URL %q: %v", u, err)
+	}
+	if parsed.Path != "/"+s.token {
+		t.Errorf("expected the path to be the token, got %q", parsed.Path)
+	}
+	if dir := parsed.Query().Get("dir"); dir != "/app/components" {
+		t.Errorf("unexpected dir %q", dir)
+	}
+	if component := parsed.Query().Get("component"); component != "hello" {
+		t.Errorf("unexpected component %q", component)
+	}
+}
+
+func TestClose(t *testing.T) {
+	s := New(slog.New(slog.DiscardHandler), func(dir string) map[string]string { return nil })
+	u, err := s.URL("/app/components", "hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp, err := http.Get(u); err == nil {
+		_ = resp.Body.Close()
+		t.Error("expected the server to be stopped")
+	}
+	restarted, err := s.URL("/app/components", "hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() {
+		_ = s.Close()
+	}()
+	resp, err := http.Get(restarted)
+	if err != nil {
+		t.Fatalf("expected the server to be started again: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	// The directory doesn't exist, so rendering fails, but the request is handled.
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+}
@@ -0,0 +1,351 @@
+package proxy
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path"
+	"slices"
+	"strings"
+
+	lsp "github.com/a-h/templ/lsp/protocol"
+	"github.com/a-h/templ/parser/v2"
+	"github.com/a-h/templ/parser/v2/visitor"
+)
+
+// templDecl is a `templ` component declaration within a .templ file.
+type templDecl struct {
+	URI      lsp.DocumentURI
+	Package  string
+	Name     string
+	Receiver string
+	// ReceiverType is the name of the receiver's type, e.g. `Card` for `(c *Card)`.
+	ReceiverType string
+	// VarTypes are the type names of the receiver and parameters, used to resolve method calls.
+	VarTypes map[string]string
+	// NameRange is the range of the component name within the declaration.
+	NameRange parser.Range
+	Template  *parser.HTMLTemplate
+}
+
+// templCall is a component rendered with `@Name(...)`, or the legacy `{! Name(...) }` syntax.
+type templCall struct {
+	// Qualifier is the package name, or the receiver variable, e.g. `components` in `@components.Button()`.
+	Qualifier string
+	Name      string
+	Range     parser.Range
+}
+
+func (d templDecl) callHierarchyItem() lsp.CallHierarchyItem {
+	detail := "templ " + d.Package
+	if d.Receiver != "" {
+		detail += " (" + d.Receiver + ")"
+	}
+	return lsp.CallHierarchyItem{
+		Name:           d.Name,
+		Kind:           lsp.SymbolKindFunction,
+		Detail:         detail,
+		URI:            d.URI,
+		Range:          templRangeToLSPRange(d.Template.Range),
+		SelectionRange: templRangeToLSPRange(d.NameRange),
+	}
+}
+
+// templDecls parses all of the templ documents known to the server and returns the
+// components they declare, ordered by URI.
+func (p *Server) templDecls() (decls []templDecl) {
+	uris := p.TemplSource.URIs()
+	slices.Sort(uris)
+	for _, u := range uris {
+		decls = append(decls, p.templDeclsInDocument(lsp.DocumentURI(u))...)
+	}
+	return decls
+}
+
+func (p *Server) templDeclsInDocument(templURI lsp.DocumentURI) (decls []templDecl) {
+	doc, ok := p.TemplSource.Get(string(templURI))
+	if !ok {
+		return nil
+	}
+	tf, err := parser.ParseString(doc.String())
+	if err != nil && tf == nil {
+		return nil
+	}
+	pkg := strings.TrimSpace(strings.TrimPrefix(tf.Package.Expression.Value, "package"))
+	for _, n := range tf.Nodes {
+		t, ok := n.(*parser.HTMLTemplate)
+		if !ok {
+			continue
+		}
+		name, receiver, nameRange, ok := parseTemplDeclName(t.Expression)
+		if !ok {
+			continue
+		}
+		receiverType, varTypes := parseTemplDeclVarTypes(t.Expression)
+		decls = append(decls, templDecl{
+			URI:          templURI,
+			Package:      pkg,
+			Name:         name,
+			Receiver:     receiver,
+			ReceiverType: receiverType,
+			VarTypes:     varTypes,
+			NameRange:    nameRange,
+			Template:     t,
+		})
+	}
+	return decls
+}
+
+// parseTemplDeclName extracts the component name from a templ declaration, e.g. `(c Comp) Button(name string)`.
+func parseTemplDeclName(expr parser.Expression) (name, receiver string, nameRange parser.Range, ok bool) {
+	const prefix = "package p\nfunc "
+	src := prefix + expr.Value + " {}"
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, goparser.SkipObjectResolution)
+	if err != nil || len(f.Decls) == 0 {
+		return
+	}
+	fn, isFunc := f.Decls[0].(*ast.FuncDecl)
+	if !isFunc || fn.Name == nil {
+		return
+	}
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		receiver = expr.Value[int(fn.Recv.Opening)-1-len(prefix) : int(fn.Recv.Closing)-len(prefix)]
+	}
+	// token.Pos values are 1-based offsets into src.
+	start := int(fn.Name.Pos()) - 1 - len(prefix)
+	end := start + len(fn.Name.Name)
+	nameRange = parser.Range{
+		From: offsetPosition(expr, start),
+		To:   offsetPosition(expr, end),
+	}
+	return fn.Name.Name, receiver, nameRange, true
+}
+
+// parseTemplDeclVarTypes returns the type name of the receiver, and the type names of the
+// receiver and parameters of a templ declaration, e.g. `(c *Card) Title(b components.Button)`
+// returns `Card`, and `c` is a `Card`, and `b` is a `components.Button`.
+func parseTemplDeclVarTypes(expr parser.Expression) (receiverType string, varTypes map[string]string) {
+	f, err := goparser.ParseFile(token.NewFileSet(), "", "package p\nfunc "+expr.Value+" {}", goparser.SkipObjectResolution)
+	if err != nil || len(f.Decls) == 0 {
+		return
+	}
+	fn, isFunc := f.Decls[0].(*ast.FuncDecl)
+	if !isFunc {
+		return
+	}
+	varTypes = map[string]string{}
+	add := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				varTypes[name.Name] = typeName(field.Type)
+			}
+		}
+	}
+	add(fn.Recv)
+	add(fn.Type.Params)
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		receiverType = typeName(fn.Recv.List[0].Type)
+	}
+	return receiverType, varTypes
+}
+
+// typeName returns the name of the type, without pointers or type arguments, e.g. `Card` for
+// `*Card[T]`, or `components.Card` for `components.Card`.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	case *ast.IndexListExpr:
+		return typeName(t.X)
+	case *ast.ParenExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			return x.Name + "." + t.Sel.Name
+		}
+	}
+	return ""
+}
+
+// offsetPosition returns the position of the byte offset within the expression.
+func offsetPosition(expr parser.Expression, offset int) (pos parser.Position) {
+	pos = expr.Range.From
+	for _, c := range expr.Value[:offset] {
+		pos.Index += int64(len(string(c)))
+		if c == '\n' {
+			pos.Line++
+			pos.Col = 0
+			continue
+		}
+		pos.Col += uint32(len(string(c)))
+	}
+	return pos
+}
+
+// parseTemplCall extracts the component name from a call expression, e.g. `components.Button("text")`.
+// Expressions that don't call a function, such as `@body`, are not calls.
+func parseTemplCall(expr parser.Expression) (call templCall, ok bool) {
+	e, err := goparser.ParseExpr(expr.Value)
+	if err != nil {
+		return call, false
+	}
+	ce, isCall := e.(*ast.CallExpr)
+	if !isCall {
+		return call, false
+	}
+	call.Range = expr.Range
+	switch fun := ce.Fun.(type) {
+	case *ast.Ident:
+		call.Name = fun.Name
+	case *ast.SelectorExpr:
+		x, isIdent := fun.X.(*ast.Ident)
+		if !isIdent {
+			return call, false
+		}
+		call.Qualifier = x.Name
+		call.Name = fun.Sel.Name
+	default:
+		return call, false
+	}
+	return call, true
+}
+
+// templCalls returns the components rendered by the template, including those passed as children.
+func templCalls(t *parser.HTMLTemplate) (calls []templCall) {
+	v := visitor.New()
+	visitTemplElementExpression := v.TemplElementExpression
+	v.TemplElementExpression = func(n *parser.TemplElementExpression) error {
+		if call, ok := parseTemplCall(n.Expression); ok {
+			calls = append(calls, call)
+		}
+		return visitTemplElementExpression(n)
+	}
+	v.CallTemplateExpression = func(n *parser.CallTemplateExpression) error {
+		if call, ok := parseTemplCall(n.Expression); ok {
+			calls = append(calls, call)
+		}
+		return nil
+	}
+	_ = t.Visit(v)
+	return calls
+}
+
+// resolveTemplCall finds the declaration of the component called from the caller.
+//
+// Unqualified calls resolve to components in the same directory. Qualified calls resolve to
+// components in a package with a matching name, or to a method of the type of the caller's
+// receiver or parameter, e.g. `@c.Title()` within `templ (c Card) Page()`.
+func resolveTemplCall(caller templDecl, call templCall, decls []templDecl) (d templDecl, ok bool) {
+	callerDir := path.Dir(string(caller.URI))
+	varType, isVar := caller.VarTypes[call.Qualifier]
+	varPkg, varTypeName, isQualifiedType := strings.Cut(varType, ".")
+	for _, decl := range decls {
+		if decl.Name != call.Name {
+			continue
+		}
+		sameDir := path.Dir(string(decl.URI)) == callerDir
+		switch {
+		case call.Qualifier == "":
+			if decl.Receiver == "" && sameDir {
+				return decl, true
+			}
+		case isVar:
+			// A variable shadows a package with the same name.
+			if decl.Receiver == "" {
+				continue
+			}
+			if isQualifiedType && decl.Package == varPkg && decl.ReceiverType == varTypeName && !sameDir {
+				return decl, true
+			}
+			if !isQualifiedType && decl.ReceiverType == varType && sameDir {
+				return decl, true
+			}
+		default:
+			if decl.Receiver == "" && decl.Package == call.Qualifier && !sameDir {
+				return decl, true
+			}
+		}
+	}
+	return d, false
+}
+
+// findTemplDecl finds the component declared at the given URI and range, as returned in
+// a call hierarchy item.
+func findTemplDecl(decls []templDecl, templURI lsp.DocumentURI, name string, r lsp.Range) (d templDecl, ok bool) {
+	for _, decl := range decls {
+		if decl.URI == templURI && decl.Name == name && lspRangeContains(templRangeToLSPRange(decl.Template.Range), r.Start) {
+			return decl, true
+		}
+	}
+	return d, false
+}
+
+// enclosingTemplDecl returns the component declaration that contains the position.
+func enclosingTemplDecl(templURI lsp.DocumentURI, pos lsp.Position, decls []templDecl) (d templDecl, ok bool) {
+	for _, decl := range decls {
+		if decl.URI == templURI && lspRangeContains(templRangeToLSPRange(decl.Template.Range), pos) {
+			return decl, true
+		}
+	}
+	return d, false
+}
+
+// calledTemplDeclAt returns the declaration of the component called at the position.
+func calledTemplDeclAt(templURI lsp.DocumentURI, pos lsp.Position, decls []templDecl) (d templDecl, ok bool) {
+	caller, ok := enclosingTemplDecl(templURI, pos, decls)
+	if !ok {
+		return d, false
+	}
+	for _, call := range templCalls(caller.Template) {
+		if lspRangeContains(templRangeToLSPRange(call.Range), pos) {
+			return resolveTemplCall(caller, call, decls)
+		}
+	}
+	return d, false
+}
+
+// templCallsContain returns true if every call made by the caller has been found by the templ
+// parser, i.e. the caller's templ document is loaded, and each call maps into a templ call.
+func templCallsContain(templCalls []lsp.CallHierarchyIncomingCall, call lsp.CallHierarchyIncomingCall) bool {
+	if len(call.FromRanges) == 0 {
+		return false
+	}
+	for _, r := range call.FromRanges {
+		found := slices.ContainsFunc(templCalls, func(c lsp.CallHierarchyIncomingCall) bool {
+			return c.From.URI == call.From.URI && slices.ContainsFunc(c.FromRanges, func(fr lsp.Range) bool {
+				return lspRangeContains(fr, r.Start)
+			})
+		})
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func templRangeToLSPRange(r parser.Range) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{Line: r.From.Line, Character: r.From.Col},
+		End:   lsp.Position{Line: r.To.Line, Character: r.To.Col},
+	}
+}
+
+func lspRangeContains(r lsp.Range, pos lsp.Position) bool {
+	if pos.Line < r.Start.Line || pos.Line > r.End.Line {
+		return false
+	}
+	if pos.Line == r.Start.Line && pos.Character < r.Start.Character {
+		return false
+	}
+	if pos.Line == r.End.Line && pos.Character > r.End.Character {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,337 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	lsp "github.com/a-h/templ/lsp/protocol"
+	"github.com/a-h/templ/parser/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+const callHierarchyPage = `package pages
+
+import "example.com/components"
+
+templ Page() {
+	@Layout("Home") {
+		@components.Button("Click")
+	}
+}
+
+templ Layout(title string) {
+	<title>{ title }</title>
+	{ children... }
+	@Footer()
+}
+
+templ Footer() {
+	<footer></footer>
+}
+`
+
+const callHierarchyComponents = `package components
+
+templ Button(text string) {
+	<button>{ text }</button>
+}
+
+templ Card() {
+	@Button("Card")
+}
+`
+
+func newCallHierarchyTestServer(mock *mockServer) *Server {
+	s := newTestServer(mock)
+	s.TemplSource.Set("file:///project/pages/page.templ", NewDocument(s.Log, callHierarchyPage))
+	s.TemplSource.Set("file:///project/components/components.templ", NewDocument(s.Log, callHierarchyComponents))
+	return s
+}
+
+func TestParseTemplDeclName(t *testing.T) {
+	tests := []struct {
+		name             string
+		expr             string
+		expectedName     string
+		expectedReceiver string
+		expectedFrom     uint32
+	}{
+		{
+			name:         "function",
+			expr:         "Button(text string)",
+			expectedName: "Button",
+			expectedFrom: 6,
+		},
+		{
+			name:             "method",
+			expr:             "(c Card) Title()",
+			expectedName:     "Title",
+			expectedReceiver: "(c Card)",
+			expectedFrom:     15,
+		},
+		{
+			name:         "generic",
+			expr:         "List[T any](items []T)",
+			expectedName: "List",
+			expectedFrom: 6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr := newTestExpression(tt.expr, 2, 6)
+			name, receiver, r, ok := parseTemplDeclName(expr)
+			if !ok {
+				t.Fatal("expected declaration to be parsed")
+			}
+			if name != tt.expectedName {
+				t.Errorf("expected name %q, got %q", tt.expectedName, name)
+			}
+			if receiver != tt.expectedReceiver {
+				t.Errorf("expected receiver %q, got %q", tt.expectedReceiver, receiver)
+			}
+			if r.From.Line != 2 || r.From.Col != tt.expectedFrom {
+				t.Errorf("expected name to start at 2:%d, got %d:%d", tt.expectedFrom, r.From.Line, r.From.Col)
+			}
+			if r.To.Col-r.From.Col != uint32(len(tt.expectedName)) {
+				t.Errorf("expected name range to be %d long, got %d", len(tt.expectedName), r.To.Col-r.From.Col)
+			}
+		})
+	}
+}
+
+func TestParseTemplCall(t *testing.T) {
+	tests := []struct {
+		expr              string
+		expectedQualifier string
+		expectedName      string
+		expectedOK        bool
+	}{
+		{expr: `Button("text")`, expectedName: "Button", expectedOK: true},
+		{expr: `components.Button("text")`, expectedQualifier: "components", expectedName: "Button", expectedOK: true},
+		{expr: `body`, expectedOK: false},
+		{expr: `getComponent()()`, expectedOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			call, ok := parseTemplCall(newTestExpression(tt.expr, 0, 0))
+			if ok != tt.expectedOK {
+				t.Fatalf("expected ok=%v, got %v", tt.expectedOK, ok)
+			}
+			if call.Qualifier != tt.expectedQualifier || call.Name != tt.expectedName {
+				t.Errorf("expected %s.%s, got %s.%s", tt.expectedQualifier, tt.expectedName, call.Qualifier, call.Name)
+			}
+		})
+	}
+}
+
+func TestPrepareCallHierarchyOnTemplCall(t *testing.T) {
+	mock := &mockServer{}
+	s := newCallHierarchyTestServer(mock)
+	// Cursor on `@components.Button("Click")`.
+	result, err := s.PrepareCallHierarchy(context.Background(), &lsp.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: "file:///project/pages/page.templ"},
+			Position:     lsp.Position{Line: 6, Character: 16},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.prepareCallHierarchyParams != nil {
+		t.Error("expected the request not to be forwarded to gopls")
+	}
+	expected := []lsp.CallHierarchyItem{
+		{
+			Name:   "Button",
+			Kind:   lsp.SymbolKindFunction,
+			Detail: "templ components",
+			URI:    "file:///project/components/components.templ",
+			Range: lsp.Range{
+				Start: lsp.Position{Line: 2, Character: 0},
+				End:   lsp.Position{Line: 4, Character: 1},
+			},
+			SelectionRange: lsp.Range{
+				Start: lsp.Position{Line: 2, Character: 6},
+				End:   lsp.Position{Line: 2, Character: 12},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestPrepareCallHierarchyOnTemplDeclaration(t *testing.T) {
+	mock := &mockServer{}
+	s := newCallHierarchyTestServer(mock)
+	result, err := s.PrepareCallHierarchy(context.Background(), &lsp.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: "file:///project/pages/page.templ"},
+			Position:     lsp.Position{Line: 10, Character: 8},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 1 || result[0].Name != "Layout" {
+		t.Fatalf("expected the Layout component, got %v", result)
+	}
+}
+
+func TestIncomingCallsListsTemplCallers(t *testing.T) {
+	mock := &mockServer{
+		incomingCallsResult: []lsp.CallHierarchyIncomingCall{
+			{
+				From: lsp.CallHierarchyItem{
+					Name: "Card",
+					URI:  "file:///project/components/components_templ.go",
+				},
+				FromRanges: []lsp.Range{
+					{Start: lsp.Position{Line: 40, Character: 10}, End: lsp.Position{Line: 40, Character: 24}},
+				},
+			},
+			{
+				From: lsp.CallHierarchyItem{
+					Name: "Other",
+					URI:  "file:///project/other/other_templ.go",
+				},
+				FromRanges: []lsp.Range{
+					{Start: lsp.Position{Line: 12, Character: 4}, End: lsp.Position{Line: 12, Character: 20}},
+				},
+			},
+			{
+				From: lsp.CallHierarchyItem{
+					Name: "handler",
+					URI:  "file:///project/main.go",
+				},
+			},
+		},
+	}
+	s := newCallHierarchyTestServer(mock)
+	// Map `Button("Card")` in the Card component to the generated Go code.
+	sm := parser.NewSourceMap()
+	sm.Add(newTestExpression(`Button("Card")`, 7, 2), parser.Range{
+		From: parser.Position{Line: 40, Col: 10},
+		To:   parser.Position{Line: 40, Col: 24},
+	})
+	s.SourceMapCache.Set("file:///project/components/components.templ", sm)
+	items, err := s.PrepareCallHierarchy(context.Background(), &lsp.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: "file:///project/components/components.templ"},
+			Position:     lsp.Position{Line: 2, Character: 8},
+		},
+	})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("failed to prepare call hierarchy: %v, %v", items, err)
+	}
+	result, err := s.IncomingCalls(context.Background(), &lsp.CallHierarchyIncomingCallsParams{
+		Item: items[0],
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, r := range result {
+		names = append(names, r.From.Name)
+	}
+	// Callers in loaded templ files are found by the templ parser, other callers by gopls.
+	if diff := cmp.Diff([]string{"Card", "Page", "Other", "handler"}, names); diff != "" {
+		t.Error(diff)
+	}
+	if result[1].From.URI != "file:///project/pages/page.templ" {
+		t.Errorf("expected caller to be in a templ file, got %q", result[1].From.URI)
+	}
+	expectedRange := lsp.Range{
+		Start: lsp.Position{Line: 6, Character: 3},
+		End:   lsp.Position{Line: 6, Character: 29},
+	}
+	if diff := cmp.Diff([]lsp.Range{expectedRange}, result[1].FromRanges); diff != "" {
+		t.Error(diff)
+	}
+	if mock.incomingCallsParams.Item.URI != "file:///project/components/components_templ.go" {
+		t.Errorf("expected the item to be converted to Go for gopls, got %q", mock.incomingCallsParams.Item.URI)
+	}
+}
+
+func TestOutgoingCallsIncludesChildren(t *testing.T) {
+	mock := &mockServer{}
+	s := newCallHierarchyTestServer(mock)
+	items, err := s.PrepareCallHierarchy(context.Background(), &lsp.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: "file:///project/pages/page.templ"},
+			Position:     lsp.Position{Line: 4, Character: 7},
+		},
+	})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("failed to prepare call hierarchy: %v, %v", items, err)
+	}
+	result, err := s.OutgoingCalls(context.Background(), &lsp.CallHierarchyOutgoingCallsParams{
+		Item: items[0],
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.outgoingCallsParams != nil {
+		t.Error("expected the request not to be forwarded to gopls")
+	}
+	var names []string
+	for _, r := range result {
+		names = append(names, r.To.Name)
+	}
+	if diff := cmp.Diff([]string{"Layout", "Button"}, names); diff != "" {
+		t.Error(diff)
+	}
+	if result[1].To.URI != "file:///project/components/components.templ" {
+		t.Errorf("expected callee to be in a templ file, got %q", result[1].To.URI)
+	}
+}
+
+func TestResolveTemplCallChecksReceiverType(t *testing.T) {
+	const methods = `package components
+
+type Card struct{}
+
+type Dialog struct{}
+
+templ (c Card) Title() {
+	<h1>Card</h1>
+}
+
+templ (d Dialog) Title() {
+	<h1>Dialog</h1>
+}
+
+templ (d *Dialog) Page(c Card) {
+	@d.Title()
+	@c.Title()
+	@x.Title()
+}
+`
+	s := newTestServer(&mockServer{})
+	s.TemplSource.Set("file:///project/components/methods.templ", NewDocument(s.Log, methods))
+	decls := s.templDecls()
+	caller, ok := findTemplDecl(decls, "file:///project/components/methods.templ", "Page", lsp.Range{Start: lsp.Position{Line: 14, Character: 0}})
+	if !ok {
+		t.Fatal("expected to find the Page component")
+	}
+	var receivers []string
+	for _, call := range templCalls(caller.Template) {
+		if callee, ok := resolveTemplCall(caller, call, decls); ok {
+			receivers = append(receivers, callee.Receiver)
+		}
+	}
+	// `x` isn't a receiver or parameter, so its type is unknown.
+	if diff := cmp.Diff([]string{"(d Dialog)", "(c Card)"}, receivers); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func newTestExpression(value string, line, col uint32) parser.Expression {
+	return parser.Expression{
+		Value: value,
+		Range: parser.Range{
+			From: parser.Position{Line: line, Col: col},
+			To:   parser.Position{Line: line, Col: col + uint32(len(value))},
+		},
+	}
+}
@@ -119,6 +119,11 @@ func (p Client) ShowMessageRequest(ctx context.Context, params *lsp.ShowMessageR
 	return p.Target.ShowMessageRequest(ctx, params)
 }
 
+func (p Client) ShowDocument(ctx context.Context, params *lsp.ShowDocumentParams) (result *lsp.ShowDocumentResult, err error) {
+	p.Log.Info("client <- server: ShowDocument", slog.String("uri", string(params.URI)))
+	return p.Target.ShowDocument(ctx, params)
+}
+
 func (p Client) Telemetry(ctx context.Context, params any) (err error) {
 	p.Log.Info("client <- server: Telemetry")
 	return p.Target.Telemetry(ctx, params)
@@ -14,6 +14,9 @@ import (
 type mockClient struct {
 	applyEditParams         *lsp.ApplyWorkspaceEditParams
 	publishDiagnosticParams *lsp.PublishDiagnosticsParams
+	// publishedDiagnostics contains every set of diagnostics published, in order.
+	publishedDiagnostics []*lsp.PublishDiagnosticsParams
+	showDocumentParams   *lsp.ShowDocumentParams
 }
 
 func (m *mockClient) ApplyEdit(ctx context.Context, params *lsp.ApplyWorkspaceEditParams) (*lsp.ApplyWorkspaceEditResponse, error) {
@@ -23,6 +26,7 @@ func (m *mockClient) ApplyEdit(ctx context.Context, params *lsp.ApplyWorkspaceEd
 
 func (m *mockClient) PublishDiagnostics(ctx context.Context, params *lsp.PublishDiagnosticsParams) error {
 	m.publishDiagnosticParams = params
+	m.publishedDiagnostics = append(m.publishedDiagnostics, params)
 	return nil
 }
 
@@ -32,6 +36,10 @@ func (m *mockClient) WorkDoneProgressCreate(context.Context, *lsp.WorkDoneProgre
 }
 func (m *mockClient) LogMessage(context.Context, *lsp.LogMessageParams) error   { return nil }
 func (m *mockClient) ShowMessage(context.Context, *lsp.ShowMessageParams) error { return nil }
+func (m *mockClient) ShowDocument(ctx context.Context, params *lsp.ShowDocumentParams) (*lsp.ShowDocumentResult, error) {
+	m.showDocumentParams = params
+	return &lsp.ShowDocumentResult{Success: true}, nil
+}
 func (m *mockClient) ShowMessageRequest(context.Context, *lsp.ShowMessageRequestParams) (*lsp.MessageActionItem, error) {
 	return nil, nil
 }
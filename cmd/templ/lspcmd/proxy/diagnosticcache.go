@@ -59,3 +59,9 @@ func (dc *DiagnosticCache) AddGoDiagnostics(uri string, templDiagnostics []lsp.D
 	dc.cache[uri] = diag
 	return append(diag.goplsDiagnostics, templDiagnostics...)
 }
+
+func (dc *DiagnosticCache) Delete(uri string) {
+	dc.m.Lock()
+	defer dc.m.Unlock()
+	delete(dc.cache, uri)
+}
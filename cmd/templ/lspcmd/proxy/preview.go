@@ -0,0 +1,212 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	goast "go/ast"
+	goparser "go/parser"
+	"go/token"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lsp "github.com/a-h/templ/lsp/protocol"
+	"github.com/a-h/templ/lsp/uri"
+	"github.com/a-h/templ/parser/v2"
+)
+
+// previewCommand is run by the Preview code lens, with the templ file URI and the name of the
+// component to render as arguments.
+const previewCommand = "templ.preview"
+
+// examplePrefix is the prefix of the name of a templ or function that takes no arguments, and
+// renders an example of the component with the rest of the name, e.g. ExampleButton.
+const examplePrefix = "Example"
+
+// previewCodeLenses returns a Preview code lens for each templ declaration in the file that takes
+// no arguments, or has an example in the same package.
+func (p *Server) previewCodeLenses(templURI lsp.DocumentURI) (lenses []lsp.CodeLens) {
+	doc, ok := p.TemplSource.Get(string(templURI))
+	if !ok {
+		return nil
+	}
+	tf, err := parser.ParseString(doc.String())
+	if err != nil {
+		return nil
+	}
+	var examples map[string]bool
+	for _, n := range tf.Nodes {
+		t, ok := n.(*parser.HTMLTemplate)
+		if !ok {
+			continue
+		}
+		name, parameterless, ok := parseTemplDeclSignature(t.Expression)
+		if !ok {
+			continue
+		}
+		component := name
+		if !parameterless {
+			if examples == nil {
+				examples = p.examples(filepath.Dir(templURI.Filename()))
+			}
+			component = examplePrefix + name
+			if !examples[component] {
+				continue
+			}
+		}
+		pos := lsp.Position{Line: t.Range.From.Line, Character: t.Range.From.Col}
+		lenses = append(lenses, lsp.CodeLens{
+			Range: lsp.Range{Start: pos, End: pos},
+			Command: &lsp.Command{
+				Title:     "Preview",
+				Command:   previewCommand,
+				Arguments: []any{string(templURI), component},
+			},
+		})
+	}
+	return lenses
+}
+
+// parseTemplDeclSignature returns the name of a templ declaration, and whether it takes no
+// arguments. Declarations with receivers or type parameters can't be previewed.
+func parseTemplDeclSignature(expr parser.Expression) (name string, parameterless bool, ok bool) {
+	f, err := goparser.ParseFile(token.NewFileSet(), "", "package p\nfunc "+expr.Value+" {}", goparser.SkipObjectResolution)
+	if err != nil || len(f.Decls) == 0 {
+		return
+	}
+	fn, isFunc := f.Decls[0].(*goast.FuncDecl)
+	if !isFunc || fn.Recv != nil || fn.Type.TypeParams != nil {
+		return
+	}
+	return fn.Name.Name, fn.Type.Params.NumFields() == 0, true
+}
+
+// examples returns the names of the templ declarations and functions in the package in dir that
+// take no arguments, and start with the example prefix. Code lenses are requested often, so the
+// names are cached until a file in the directory changes.
+func (p *Server) examples(dir string) (names map[string]bool) {
+	p.examplesMu.Lock()
+	defer p.examplesMu.Unlock()
+	if names, ok := p.dirToExamples[dir]; ok {
+		return names
+	}
+	names = p.readExamples(dir)
+	if p.dirToExamples == nil {
+		p.dirToExamples = map[string]map[string]bool{}
+	}
+	p.dirToExamples[dir] = names
+	return names
+}
+
+// invalidateExamples removes the cached examples of the directory that contains the file.
+func (p *Server) invalidateExamples(fileURI lsp.DocumentURI) {
+	p.examplesMu.Lock()
+	defer p.examplesMu.Unlock()
+	delete(p.dirToExamples, filepath.Dir(fileURI.Filename()))
+}
+
+// readExamples reads the examples in dir from the templ and Go files in the directory.
+func (p *Server) readExamples(dir string) (names map[string]bool) {
+	names = map[string]bool{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		p.Log.Warn("preview: failed to read directory", slog.String("dir", dir), slog.Any("error", err))
+		return names
+	}
+	for _, e := range entries {
+		fileName := filepath.Join(dir, e.Name())
+		switch {
+		case e.IsDir():
+			continue
+		case strings.HasSuffix(fileName, ".templ"):
+			var contents string
+			if doc, ok := p.TemplSource.Get(string(uri.URIFromPath(fileName))); ok {
+				contents = doc.String()
+			} else {
+				b, err := os.ReadFile(fileName)
+				if err != nil {
+					continue
+				}
+				contents = string(b)
+			}
+			tf, err := parser.ParseString(contents)
+			if err != nil {
+				continue
+			}
+			for _, n := range tf.Nodes {
+				t, ok := n.(*parser.HTMLTemplate)
+				if !ok {
+					continue
+				}
+				if name, parameterless, ok := parseTemplDeclSignature(t.Expression); ok && parameterless && strings.HasPrefix(name, examplePrefix) {
+					names[name] = true
+				}
+			}
+		case strings.HasSuffix(fileName, ".go") && !strings.HasSuffix(fileName, "_test.go") && !strings.HasSuffix(fileName, "_templ.go"):
+			f, err := goparser.ParseFile(token.NewFileSet(), fileName, nil, goparser.SkipObjectResolution)
+			if err != nil {
+				continue
+			}
+			for _, d := range f.Decls {
+				fn, ok := d.(*goast.FuncDecl)
+				if !ok || fn.Recv != nil || fn.Type.TypeParams != nil || fn.Type.Params.NumFields() != 0 {
+					continue
+				}
+				if strings.HasPrefix(fn.Name.Name, examplePrefix) {
+					names[fn.Name.Name] = true
+				}
+			}
+		}
+	}
+	return names
+}
+
+// preview opens the browser at a page that renders the component.
+func (p *Server) preview(ctx context.Context, args []any) (err error) {
+	if len(args) != 2 {
+		return fmt.Errorf("%s: expected 2 arguments, got %d", previewCommand, len(args))
+	}
+	templURIArg, _ := args[0].(string)
+	component, _ := args[1].(string)
+	templURI, err := uri.ParseDocumentURI(templURIArg)
+	if err != nil {
+		return fmt.Errorf("%s: invalid uri %q: %w", previewCommand, templURIArg, err)
+	}
+	u, err := p.Preview.URL(filepath.Dir(templURI.Filename()), component)
+	if err != nil {
+		return err
+	}
+	client := lsp.ClientFromContext(ctx)
+	if client == nil {
+		return fmt.Errorf("%s: no client", previewCommand)
+	}
+	result, err := client.ShowDocument(ctx, &lsp.ShowDocumentParams{
+		URI:       uri.URI(u),
+		External:  true,
+		TakeFocus: true,
+	})
+	if err != nil {
+		return err
+	}
+	if result != nil && !result.Success {
+		return fmt.Errorf("%s: the editor failed to open %s", previewCommand, u)
+	}
+	return nil
+}
+
+// generatedGoCode returns the Go code generated for templ files in the directory, which may not
+// have been written to disk yet.
+func (p *Server) generatedGoCode(dir string) map[string]string {
+	p.workspaceMu.Lock()
+	defer p.workspaceMu.Unlock()
+	code := map[string]string{}
+	for templURI, goCode := range p.GoSource {
+		fileName := uri.URI(templURI).Filename()
+		if filepath.Dir(fileName) != dir {
+			continue
+		}
+		code[strings.TrimSuffix(fileName, ".templ")+"_templ.go"] = goCode
+	}
+	return code
+}
@@ -0,0 +1,161 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/a-h/templ/cmd/templ/lspcmd/preview"
+	lsp "github.com/a-h/templ/lsp/protocol"
+	"github.com/a-h/templ/lsp/uri"
+	"github.com/a-h/templ/parser/v2"
+)
+
+func TestParseTemplDeclSignature(t *testing.T) {
+	tests := []struct {
+		expr                  string
+		expectedName          string
+		expectedParameterless bool
+		expectedOK            bool
+	}{
+		{expr: "Button()", expectedName: "Button", expectedParameterless: true, expectedOK: true},
+		{expr: "Button(label string)", expectedName: "Button", expectedOK: true},
+		{expr: "(c Comp) Button()"},
+		{expr: "List[T any]()"},
+		{expr: "Button("},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			name, parameterless, ok := parseTemplDeclSignature(parser.Expression{Value: tt.expr})
+			if name != tt.expectedName || parameterless != tt.expectedParameterless || ok != tt.expectedOK {
+				t.Errorf("expected (%q, %v, %v), got (%q, %v, %v)", tt.expectedName, tt.expectedParameterless, tt.expectedOK, name, parameterless, ok)
+			}
+		})
+	}
+}
+
+func TestCodeLensPreview(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, contents string) lsp.DocumentURI {
+		t.Helper()
+		fileName := filepath.Join(dir, name)
+		if err := os.WriteFile(fileName, []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write %q: %v", name, err)
+		}
+		return uri.URIFromPath(fileName)
+	}
+	writeFile("examples.go", "package components\n\nfunc ExampleCard() templ.Component {\n\treturn Card(\"title\")\n}\n")
+	templURI := writeFile("components.templ", `package components
+
+templ Header() {
+	<h1>Header</h1>
+}
+
+templ Button(label string) {
+	<button>{ label }</button>
+}
+
+templ ExampleButton() {
+	@Button("Click")
+}
+
+templ Card(title string) {
+	<div>{ title }</div>
+}
+
+templ Footer(year int) {
+	<footer>{ year }</footer>
+}
+`)
+
+	mock := &mockServer{}
+	s := newTestServer(mock)
+	s.Preview = preview.New(slog.Default(), s.generatedGoCode)
+	b, err := os.ReadFile(templURI.Filename())
+	if err != nil {
+		t.Fatalf("failed to read templ file: %v", err)
+	}
+	s.TemplSource.Set(string(templURI), NewDocument(s.Log, string(b)))
+
+	result, err := s.CodeLens(context.Background(), &lsp.CodeLensParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: templURI},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lens := func(line uint32, component string) lsp.CodeLens {
+		return lsp.CodeLens{
+			Range: lsp.Range{Start: lsp.Position{Line: line}, End: lsp.Position{Line: line}},
+			Command: &lsp.Command{
+				Title:     "Preview",
+				Command:   previewCommand,
+				Arguments: []any{string(templURI), component},
+			},
+		}
+	}
+	expected := []lsp.CodeLens{
+		lens(2, "Header"),
+		lens(6, "ExampleButton"),
+		lens(10, "ExampleButton"),
+		lens(14, "ExampleCard"),
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Run("examples are cached until a file in the directory changes", func(t *testing.T) {
+		exampleURI := writeFile("footer.go", "package components\n\nfunc ExampleFooter() templ.Component {\n\treturn Footer(2025)\n}\n")
+		codeLens := func() []lsp.CodeLens {
+			t.Helper()
+			result, err := s.CodeLens(context.Background(), &lsp.CodeLensParams{
+				TextDocument: lsp.TextDocumentIdentifier{URI: templURI},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return result
+		}
+		if diff := cmp.Diff(expected, codeLens()); diff != "" {
+			t.Error(diff)
+		}
+		err := s.DidChangeWatchedFiles(context.Background(), &lsp.DidChangeWatchedFilesParams{
+			Changes: []*lsp.FileEvent{{URI: exampleURI, Type: lsp.FileChangeTypeCreated}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(append(expected, lens(18, "ExampleFooter")), codeLens()); diff != "" {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("executing the command shows the preview in the browser", func(t *testing.T) {
+		client := &mockClient{}
+		ctx := lsp.WithClient(context.Background(), client)
+		_, err := s.ExecuteCommand(ctx, &lsp.ExecuteCommandParams{
+			Command:   previewCommand,
+			Arguments: []any{string(templURI), "Header"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if client.showDocumentParams == nil {
+			t.Fatal("expected the document to be shown")
+		}
+		if !client.showDocumentParams.External {
+			t.Error("expected the document to be shown in an external program")
+		}
+		u, err := url.Parse(string(client.showDocumentParams.URI))
+		if err != nil {
+			t.Fatalf("invalid URL: %v", err)
+		}
+		if diff := cmp.Diff(url.Values{"dir": {dir}, "component": {"Header"}}, u.Query()); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
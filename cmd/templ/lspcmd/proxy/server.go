@@ -4,12 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/a-h/parse"
+	"github.com/a-h/templ/cmd/templ/lspcmd/preview"
 	"github.com/a-h/templ/internal/format"
 	"github.com/a-h/templ/internal/imports"
 	"github.com/a-h/templ/internal/lazyloader"
@@ -36,22 +36,38 @@ import (
 // inverse operation - to put the file names back, and readjust any
 // character positions.
 type Server struct {
-	Log                *slog.Logger
-	Target             lsp.Server
-	SourceMapCache     *SourceMapCache
-	DiagnosticCache    *DiagnosticCache
-	TemplSource        *DocumentContents
-	GoSource           map[string]string
-	GoplsPath          string
-	GoplsVersion       string
-	NoPreload          bool
-	preLoadURIs        []*lsp.DidOpenTextDocumentParams
+	Log             *slog.Logger
+	Target          lsp.Server
+	SourceMapCache  *SourceMapCache
+	DiagnosticCache *DiagnosticCache
+	TemplSource     *DocumentContents
+	GoSource        map[string]string
+	GoplsPath       string
+	GoplsVersion    string
+	NoPreload       bool
+	// Preview renders components for the Preview code lens. If nil, the code lens isn't shown.
+	Preview *preview.Server
+	// examplesMu guards dirToExamples, which caches the examples in each directory for the
+	// Preview code lens.
+	examplesMu         sync.Mutex
+	dirToExamples      map[string]map[string]bool
 	templDocLazyLoader lazyloader.TemplDocLazyLoader
 	formatConf         format.Config
+	workspaceFolders   []lsp.WorkspaceFolder
+	// watchTemplFiles is true if the client can be asked to watch templ files for changes.
+	watchTemplFiles bool
+	// workspaceMu guards GoSource, and the documents that are open in the editor or loaded from disk,
+	// because the workspace is scanned in the background.
+	workspaceMu sync.Mutex
+	// editorDocuments is the set of templ file URIs that are open in the editor.
+	editorDocuments map[string]bool
+	// diskDocuments maps the URIs of templ files that have been loaded from disk, rather than
+	// opened in the editor, to the version of their generated Go code that was sent to gopls.
+	diskDocuments map[string]int32
 }
 
 func NewServer(log *slog.Logger, target lsp.Server, cache *SourceMapCache, diagnosticCache *DiagnosticCache, noPreload bool, formatConf format.Config) (s *Server) {
-	return &Server{
+	s = &Server{
 		Log:             log,
 		Target:          target,
 		SourceMapCache:  cache,
@@ -60,7 +76,11 @@ func NewServer(log *slog.Logger, target lsp.Server, cache *SourceMapCache, diagn
 		GoSource:        make(map[string]string),
 		NoPreload:       noPreload,
 		formatConf:      formatConf,
+		editorDocuments: make(map[string]bool),
+		diskDocuments:   make(map[string]int32),
 	}
+	s.Preview = preview.New(log, s.generatedGoCode)
+	return s
 }
 
 // updatePosition maps positions and filenames from source templ files into the target *.go files.
@@ -239,6 +259,12 @@ func (p *Server) Initialize(ctx context.Context, params *lsp.InitializeParams) (
 		result.Capabilities.ExecuteCommandProvider = &lsp.ExecuteCommandOptions{}
 	}
 	result.Capabilities.ExecuteCommandProvider.Commands = []string{}
+	if p.Preview != nil {
+		result.Capabilities.ExecuteCommandProvider.Commands = append(result.Capabilities.ExecuteCommandProvider.Commands, previewCommand)
+		if result.Capabilities.CodeLensProvider == nil {
+			result.Capabilities.CodeLensProvider = &lsp.CodeLensOptions{}
+		}
+	}
 	result.Capabilities.DocumentFormattingProvider = true
 	result.Capabilities.SemanticTokensProvider = nil
 	result.Capabilities.DocumentRangeFormattingProvider = false
@@ -255,8 +281,13 @@ func (p *Server) Initialize(ctx context.Context, params *lsp.InitializeParams) (
 			TemplDocHandler: p,
 			OpenDocSources:  p.GoSource,
 		})
-	} else {
-		p.preload(ctx, params.WorkspaceFolders)
+	}
+	p.workspaceFolders = params.WorkspaceFolders
+	if len(p.workspaceFolders) == 0 && params.RootURI != "" {
+		p.workspaceFolders = []lsp.WorkspaceFolder{{URI: string(params.RootURI)}}
+	}
+	if ws := params.Capabilities.Workspace; ws != nil && ws.DidChangeWatchedFiles != nil {
+		p.watchTemplFiles = ws.DidChangeWatchedFiles.DynamicRegistration
 	}
 
 	result.ServerInfo.Name = "templ-lsp"
@@ -272,68 +303,6 @@ func (p *Server) Initialize(ctx context.Context, params *lsp.InitializeParams) (
 	return result, err
 }
 
-func (p *Server) preload(ctx context.Context, workspaceFolders []lsp.WorkspaceFolder) {
-	for _, c := range workspaceFolders {
-		path, err := uri.ParseDocumentURI(c.URI)
-		if err != nil {
-			p.Log.Error("invalid uri", slog.String("uri", c.URI))
-			continue
-		}
-
-		werr := filepath.Walk(path.Filename(), func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			p.Log.Info("found file", slog.String("path", path))
-			uri := uri.URIFromPath(path)
-			isTemplFile, goURI := convertTemplToGoURI(uri)
-
-			if !isTemplFile {
-				return nil
-			}
-
-			b, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
-			p.TemplSource.Set(string(uri), NewDocument(p.Log, string(b)))
-			// Parse the template.
-			template, _, err := p.parseTemplate(ctx, uri, string(b))
-			if err != nil {
-				// It's expected to have some failures while parsing the template, since
-				// you are likely to have invalid docs while you're typing.
-				p.Log.Info("parseTemplate failure", slog.Any("error", err))
-			}
-			w := new(strings.Builder)
-			generatorOutput, err := generator.Generate(template, w)
-			if err != nil {
-				// It's expected to have some failures while generating code from the template, since
-				// you are likely to have invalid docs while you're typing.
-				p.Log.Info("generator failure", slog.Any("error", err))
-			}
-			p.Log.Info("setting source map cache contents", slog.String("uri", string(uri)))
-			p.SourceMapCache.Set(string(uri), generatorOutput.SourceMap)
-			// Set the Go contents.
-			p.GoSource[string(uri)] = w.String()
-
-			didOpenParams := &lsp.DidOpenTextDocumentParams{
-				TextDocument: lsp.TextDocumentItem{
-					URI:        goURI,
-					Text:       w.String(),
-					Version:    1,
-					LanguageID: "go",
-				},
-			}
-
-			p.preLoadURIs = append(p.preLoadURIs, didOpenParams)
-			return nil
-		})
-		if werr != nil {
-			p.Log.Error("walk error", slog.Any("error", werr))
-		}
-	}
-}
-
 func (p *Server) Initialized(ctx context.Context, params *lsp.InitializedParams) (err error) {
 	p.Log.Info("client -> server: Initialized")
 	defer p.Log.Info("client -> server: Initialized end")
@@ -341,12 +310,12 @@ func (p *Server) Initialized(ctx context.Context, params *lsp.InitializedParams)
 
 	p.notifyGoplsVersion(ctx)
 
-	for i, doParams := range p.preLoadURIs {
-		doErr := p.Target.DidOpen(ctx, doParams)
-		if doErr != nil {
-			return doErr
+	if !p.NoPreload {
+		if p.watchTemplFiles {
+			p.registerTemplFileWatcher(ctx)
 		}
-		p.preLoadURIs[i] = nil
+		// Publish diagnostics for templ files that aren't open in the editor.
+		go p.scanWorkspace(ctx)
 	}
 
 	return goInitErr
@@ -375,6 +344,11 @@ func (p *Server) notifyGoplsVersion(ctx context.Context) {
 func (p *Server) Shutdown(ctx context.Context) (err error) {
 	p.Log.Info("client -> server: Shutdown")
 	defer p.Log.Info("client -> server: Shutdown end")
+	if p.Preview != nil {
+		if err := p.Preview.Close(); err != nil {
+			p.Log.Error("failed to stop preview server", slog.Any("error", err))
+		}
+	}
 	return p.Target.Shutdown(ctx)
 }
 
@@ -470,13 +444,13 @@ func (p *Server) CodeLens(ctx context.Context, params *lsp.CodeLensParams) (resu
 	if err != nil {
 		return
 	}
-	if result == nil {
-		return
-	}
 	for i, cl := range result {
 		cl.Range = convertGoRangeToTemplRange(p.SourceMapCache, p.Log, templURI, cl.Range)
 		result[i] = cl
 	}
+	if p.Preview != nil {
+		result = append(result, p.previewCodeLenses(templURI)...)
+	}
 	return
 }
 
@@ -541,7 +515,9 @@ func (p *Server) Completion(ctx context.Context, params *lsp.CompletionParams) (
 	}
 
 	// Ensure that Go source is available.
+	p.workspaceMu.Lock()
 	gosrc := strings.Split(p.GoSource[string(templURI)], "\n")
+	p.workspaceMu.Unlock()
 	if len(gosrc) < int(params.Position.Line) {
 		p.Log.Info("completion: line position out of range")
 		return nil, nil
@@ -714,6 +690,7 @@ func (p *Server) DidChange(ctx context.Context, params *lsp.DidChangeTextDocumen
 		p.Log.Error("invalid uri", slog.String("uri", string(params.TextDocument.URI)))
 		return
 	}
+	p.invalidateExamples(templURI)
 	isTemplFile, goURI := convertTemplToGoURI(templURI)
 	if !isTemplFile {
 		return p.Target.DidChange(ctx, params)
@@ -750,7 +727,9 @@ func (p *Server) DidChange(ctx context.Context, params *lsp.DidChangeTextDocumen
 	// Cache the sourcemap.
 	p.Log.Info("setting cache", slog.String("uri", string(templURI)))
 	p.SourceMapCache.Set(string(templURI), generatorOutput.SourceMap)
+	p.workspaceMu.Lock()
 	p.GoSource[string(templURI)] = w.String()
+	p.workspaceMu.Unlock()
 
 	if p.NoPreload {
 		params.TextDocument.URI = templURI
@@ -777,7 +756,34 @@ func (p *Server) DidChangeConfiguration(ctx context.Context, params *lsp.DidChan
 func (p *Server) DidChangeWatchedFiles(ctx context.Context, params *lsp.DidChangeWatchedFilesParams) (err error) {
 	p.Log.Info("client -> server: DidChangeWatchedFiles")
 	defer p.Log.Info("client -> server: DidChangeWatchedFiles end")
-	return p.Target.DidChangeWatchedFiles(ctx, params)
+	for _, change := range params.Changes {
+		p.invalidateExamples(change.URI)
+	}
+	if p.NoPreload {
+		return p.Target.DidChangeWatchedFiles(ctx, params)
+	}
+	// Refresh the templ files that have changed on disk, and pass the rest on to gopls.
+	goParams := &lsp.DidChangeWatchedFilesParams{}
+	for _, change := range params.Changes {
+		if isTemplFile, _ := convertTemplToGoURI(change.URI); !isTemplFile {
+			goParams.Changes = append(goParams.Changes, change)
+			continue
+		}
+		templURI, err := uri.ParseDocumentURI(string(change.URI))
+		if err != nil {
+			p.Log.Error("invalid uri", slog.String("uri", string(change.URI)))
+			continue
+		}
+		if change.Type == lsp.FileChangeTypeDeleted {
+			p.unloadWorkspaceFile(ctx, templURI)
+			continue
+		}
+		p.loadWorkspaceFile(ctx, templURI)
+	}
+	if len(goParams.Changes) == 0 {
+		return nil
+	}
+	return p.Target.DidChangeWatchedFiles(ctx, goParams)
 }
 
 func (p *Server) DidChangeWorkspaceFolders(ctx context.Context, params *lsp.DidChangeWorkspaceFoldersParams) (err error) {
@@ -803,7 +809,19 @@ func (p *Server) DidClose(ctx context.Context, params *lsp.DidCloseTextDocumentP
 		return p.templDocLazyLoader.Unload(ctx, params)
 	}
 
-	return p.HandleDidClose(ctx, params)
+	templURI, err := uri.ParseDocumentURI(string(params.TextDocument.URI))
+	if err != nil {
+		p.Log.Error("invalid uri", slog.String("uri", string(params.TextDocument.URI)))
+		return err
+	}
+	if err = p.HandleDidClose(ctx, params); err != nil {
+		return err
+	}
+	// Keep publishing diagnostics for the file now that the editor has closed it.
+	if isTemplFile, _ := convertTemplToGoURI(templURI); isTemplFile {
+		p.loadWorkspaceFile(ctx, templURI)
+	}
+	return nil
 }
 
 func (p *Server) HandleDidClose(ctx context.Context, params *lsp.DidCloseTextDocumentParams) (err error) {
@@ -816,6 +834,9 @@ func (p *Server) HandleDidClose(ctx context.Context, params *lsp.DidCloseTextDoc
 	if !isTemplFile {
 		return p.Target.DidClose(ctx, params)
 	}
+	p.workspaceMu.Lock()
+	defer p.workspaceMu.Unlock()
+	p.setEditorDocument(templURI, false)
 	// Delete the template and sourcemaps from caches.
 	p.TemplSource.Delete(string(templURI))
 	p.SourceMapCache.Delete(string(templURI))
@@ -854,6 +875,9 @@ func (p *Server) HandleDidOpen(ctx context.Context, params *lsp.DidOpenTextDocum
 	if !isTemplFile {
 		return p.Target.DidOpen(ctx, params)
 	}
+	p.workspaceMu.Lock()
+	defer p.workspaceMu.Unlock()
+	p.setEditorDocument(templURI, true)
 	// Cache the template doc.
 	p.TemplSource.Set(string(templURI), NewDocument(p.Log, params.TextDocument.Text))
 	// Parse the template.
@@ -1068,6 +1092,9 @@ func isRangeWithin(parent, child lsp.Range) bool {
 func (p *Server) ExecuteCommand(ctx context.Context, params *lsp.ExecuteCommandParams) (result any, err error) {
 	p.Log.Info("client -> server: ExecuteCommand")
 	defer p.Log.Info("client -> server: ExecuteCommand end")
+	if params.Command == previewCommand && p.Preview != nil {
+		return nil, p.preview(ctx, params.Arguments)
+	}
 	return p.Target.ExecuteCommand(ctx, params)
 }
 
@@ -1401,20 +1428,39 @@ func (p *Server) CodeLensRefresh(ctx context.Context) (err error) {
 func (p *Server) PrepareCallHierarchy(ctx context.Context, params *lsp.CallHierarchyPrepareParams) (result []lsp.CallHierarchyItem, err error) {
 	p.Log.Info("client -> server: PrepareCallHierarchy")
 	defer p.Log.Info("client -> server: PrepareCallHierarchy end")
-	isTempl, goURI, goPos, ok := p.proxyPositionRequest(params.TextDocument.URI, params.Position)
-	if !ok {
-		return nil, nil
+	templURI := params.TextDocument.URI
+	var decls []templDecl
+	if isTemplFile, _ := convertTemplToGoURI(templURI); isTemplFile {
+		decls = p.templDecls()
+		// Calls to components, e.g. `@Button()`, and the component declarations themselves are
+		// expressed in templ terms, rather than in terms of the generated Go code.
+		if callee, ok := calledTemplDeclAt(templURI, params.Position, decls); ok {
+			return []lsp.CallHierarchyItem{callee.callHierarchyItem()}, nil
+		}
+		if decl, ok := enclosingTemplDecl(templURI, params.Position, decls); ok && lspRangeContains(templRangeToLSPRange(decl.Template.Expression.Range), params.Position) {
+			return []lsp.CallHierarchyItem{decl.callHierarchyItem()}, nil
+		}
 	}
-	if isTempl {
-		params.TextDocument.URI = goURI
-		params.Position = goPos
+	isTempl, goURI, goPos, ok := p.proxyPositionRequest(params.TextDocument.URI, params.Position)
+	if ok {
+		if isTempl {
+			params.TextDocument.URI = goURI
+			params.Position = goPos
+		}
+		result, err = p.Target.PrepareCallHierarchy(ctx, params)
+		if err != nil {
+			return
+		}
+		for i := range result {
+			convertCallHierarchyItem(p.SourceMapCache, p.Log, &result[i])
+		}
 	}
-	result, err = p.Target.PrepareCallHierarchy(ctx, params)
-	if err != nil || result == nil {
+	if len(result) > 0 || !isTempl {
 		return
 	}
-	for i := range result {
-		convertCallHierarchyItem(p.SourceMapCache, p.Log, &result[i])
+	// Fall back to the component that contains the position, e.g. when the cursor is within HTML.
+	if decl, ok := enclosingTemplDecl(templURI, params.Position, decls); ok {
+		return []lsp.CallHierarchyItem{decl.callHierarchyItem()}, nil
 	}
 	return
 }
@@ -1422,26 +1468,73 @@ func (p *Server) PrepareCallHierarchy(ctx context.Context, params *lsp.CallHiera
 func (p *Server) IncomingCalls(ctx context.Context, params *lsp.CallHierarchyIncomingCallsParams) (result []lsp.CallHierarchyIncomingCall, err error) {
 	p.Log.Info("client -> server: IncomingCalls")
 	defer p.Log.Info("client -> server: IncomingCalls end")
-	result, err = p.Target.IncomingCalls(ctx, params)
-	if err != nil || result == nil {
+	var isTemplItem bool
+	if isTemplFile, _ := convertTemplToGoURI(params.Item.URI); isTemplFile {
+		decls := p.templDecls()
+		if callee, ok := findTemplDecl(decls, params.Item.URI, params.Item.Name, params.Item.Range); ok {
+			isTemplItem = true
+			result = templIncomingCalls(callee, decls)
+		}
+		p.convertCallHierarchyItemToGo(&params.Item)
+	}
+	goResult, err := p.Target.IncomingCalls(ctx, params)
+	if err != nil {
+		if isTemplItem {
+			// The templ callers are still useful, even if gopls can't find the Go callers.
+			p.Log.Warn("IncomingCalls: gopls failure", slog.Any("error", err))
+			return result, nil
+		}
 		return
 	}
-	for i := range result {
+	templResult := result
+	for i := range goResult {
 		// Check the original URI before converting the item.
-		isTemplGoFile, templURI := convertTemplGoToTemplURI(result[i].From.URI)
-		convertCallHierarchyItem(p.SourceMapCache, p.Log, &result[i].From)
+		isTemplGoFile, templURI := convertTemplGoToTemplURI(goResult[i].From.URI)
+		convertCallHierarchyItem(p.SourceMapCache, p.Log, &goResult[i].From)
 		if isTemplGoFile {
-			for j, r := range result[i].FromRanges {
-				result[i].FromRanges[j] = convertGoRangeToTemplRange(p.SourceMapCache, p.Log, templURI, r)
+			for j, r := range goResult[i].FromRanges {
+				goResult[i].FromRanges[j] = convertGoRangeToTemplRange(p.SourceMapCache, p.Log, templURI, r)
+			}
+			if templCallsContain(templResult, goResult[i]) {
+				// The calls have already been found in templ terms.
+				continue
 			}
 		}
+		result = append(result, goResult[i])
 	}
 	return
 }
 
+// templIncomingCalls returns the components that render the callee.
+func templIncomingCalls(callee templDecl, decls []templDecl) (result []lsp.CallHierarchyIncomingCall) {
+	for _, caller := range decls {
+		var fromRanges []lsp.Range
+		for _, call := range templCalls(caller.Template) {
+			if d, ok := resolveTemplCall(caller, call, decls); ok && d.URI == callee.URI && d.Template == callee.Template {
+				fromRanges = append(fromRanges, templRangeToLSPRange(call.Range))
+			}
+		}
+		if len(fromRanges) == 0 {
+			continue
+		}
+		result = append(result, lsp.CallHierarchyIncomingCall{
+			From:       caller.callHierarchyItem(),
+			FromRanges: fromRanges,
+		})
+	}
+	return result
+}
+
 func (p *Server) OutgoingCalls(ctx context.Context, params *lsp.CallHierarchyOutgoingCallsParams) (result []lsp.CallHierarchyOutgoingCall, err error) {
 	p.Log.Info("client -> server: OutgoingCalls")
 	defer p.Log.Info("client -> server: OutgoingCalls end")
+	if isTemplFile, _ := convertTemplToGoURI(params.Item.URI); isTemplFile {
+		decls := p.templDecls()
+		if caller, ok := findTemplDecl(decls, params.Item.URI, params.Item.Name, params.Item.Range); ok {
+			return templOutgoingCalls(caller, decls), nil
+		}
+		p.convertCallHierarchyItemToGo(&params.Item)
+	}
 	result, err = p.Target.OutgoingCalls(ctx, params)
 	if err != nil || result == nil {
 		return
@@ -1452,6 +1545,43 @@ func (p *Server) OutgoingCalls(ctx context.Context, params *lsp.CallHierarchyOut
 	return
 }
 
+// templOutgoingCalls returns the components rendered by the caller, grouped by callee.
+func templOutgoingCalls(caller templDecl, decls []templDecl) (result []lsp.CallHierarchyOutgoingCall) {
+	calleeIndex := map[*parser.HTMLTemplate]int{}
+	for _, call := range templCalls(caller.Template) {
+		callee, ok := resolveTemplCall(caller, call, decls)
+		if !ok {
+			continue
+		}
+		i, seen := calleeIndex[callee.Template]
+		if !seen {
+			i = len(result)
+			calleeIndex[callee.Template] = i
+			result = append(result, lsp.CallHierarchyOutgoingCall{
+				To: callee.callHierarchyItem(),
+			})
+		}
+		result[i].FromRanges = append(result[i].FromRanges, templRangeToLSPRange(call.Range))
+	}
+	return result
+}
+
+// convertCallHierarchyItemToGo converts a .templ call hierarchy item to the generated _templ.go file,
+// so that it can be passed to gopls.
+func (p *Server) convertCallHierarchyItemToGo(item *lsp.CallHierarchyItem) {
+	templURI := item.URI
+	_, goURI := convertTemplToGoURI(templURI)
+	item.URI = goURI
+	item.SelectionRange, _ = p.convertTemplRangeToGoRange(templURI, item.SelectionRange)
+	// gopls locates the function from the start of the range, so fall back to the name
+	// if the range of the whole component can't be mapped.
+	if r, ok := p.convertTemplRangeToGoRange(templURI, item.Range); ok {
+		item.Range = r
+	} else {
+		item.Range = item.SelectionRange
+	}
+}
+
 func (p *Server) SemanticTokensFull(ctx context.Context, params *lsp.SemanticTokensParams) (result *lsp.SemanticTokens, err error) {
 	p.Log.Info("client -> server: SemanticTokensFull")
 	defer p.Log.Info("client -> server: SemanticTokensFull end")
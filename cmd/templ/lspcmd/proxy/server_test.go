@@ -42,6 +42,10 @@ type mockServer struct {
 	codeActionParams           *lsp.CodeActionParams
 	onTypeFormattingParams     *lsp.DocumentOnTypeFormattingParams
 	monikerParams              *lsp.MonikerParams
+	// URIs of every document opened, changed and closed, in order.
+	didOpenURIs   []lsp.DocumentURI
+	didChangeURIs []lsp.DocumentURI
+	didCloseURIs  []lsp.DocumentURI
 
 	// Return values.
 	definitionResult           []lsp.Location
@@ -119,16 +123,19 @@ func (m *mockServer) Completion(ctx context.Context, params *lsp.CompletionParam
 
 func (m *mockServer) DidChange(ctx context.Context, params *lsp.DidChangeTextDocumentParams) error {
 	m.didChangeParams = params
+	m.didChangeURIs = append(m.didChangeURIs, params.TextDocument.URI)
 	return nil
 }
 
 func (m *mockServer) DidOpen(ctx context.Context, params *lsp.DidOpenTextDocumentParams) error {
 	m.didOpenParams = params
+	m.didOpenURIs = append(m.didOpenURIs, params.TextDocument.URI)
 	return nil
 }
 
 func (m *mockServer) DidClose(ctx context.Context, params *lsp.DidCloseTextDocumentParams) error {
 	m.didCloseParams = params
+	m.didCloseURIs = append(m.didCloseURIs, params.TextDocument.URI)
 	return nil
 }
 
@@ -294,6 +301,8 @@ func newTestServer(mock *mockServer) *Server {
 		DiagnosticCache: NewDiagnosticCache(),
 		TemplSource:     newDocumentContents(log),
 		GoSource:        make(map[string]string),
+		editorDocuments: make(map[string]bool),
+		diskDocuments:   make(map[string]int32),
 	}
 }
 
@@ -1029,6 +1038,8 @@ func TestCodeActionConvertsWorkspaceEdit(t *testing.T) {
 		DiagnosticCache: NewDiagnosticCache(),
 		TemplSource:     newDocumentContents(log),
 		GoSource:        make(map[string]string),
+		editorDocuments: make(map[string]bool),
+		diskDocuments:   make(map[string]int32),
 	}
 	result, err := s.CodeAction(context.Background(), &lsp.CodeActionParams{
 		TextDocument: lsp.TextDocumentIdentifier{URI: "file:///project/component.templ"},
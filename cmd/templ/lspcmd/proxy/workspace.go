@@ -0,0 +1,191 @@
+package proxy
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/a-h/templ/generator"
+	"github.com/a-h/templ/internal/skipdir"
+	lsp "github.com/a-h/templ/lsp/protocol"
+	"github.com/a-h/templ/lsp/uri"
+)
+
+// Templ files that aren't open in the editor are loaded from disk, and their generated Go code
+// is sent to gopls. This means that templ and gopls diagnostics are published for every file in
+// the workspace, not just the files that are open, so a template that is broken by a change to
+// another file is reported straight away.
+
+// templFileWatcherID is the ID of the registration that asks the client to notify the server
+// when templ files change on disk.
+const templFileWatcherID = "templ-file-watcher"
+
+// registerTemplFileWatcher asks the client to send DidChangeWatchedFiles notifications for templ
+// files. gopls only registers watchers for Go files.
+func (p *Server) registerTemplFileWatcher(ctx context.Context) {
+	client := lsp.ClientFromContext(ctx)
+	if client == nil {
+		return
+	}
+	err := client.RegisterCapability(ctx, &lsp.RegistrationParams{
+		Registrations: []lsp.Registration{
+			{
+				ID:     templFileWatcherID,
+				Method: "workspace/didChangeWatchedFiles",
+				RegisterOptions: lsp.DidChangeWatchedFilesRegistrationOptions{
+					Watchers: []lsp.FileSystemWatcher{
+						{GlobPattern: "**/*.templ"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		p.Log.Error("failed to register templ file watcher", slog.Any("error", err))
+	}
+}
+
+// scanWorkspace loads every templ file in the workspace folders that isn't open in the editor.
+func (p *Server) scanWorkspace(ctx context.Context) {
+	p.Log.Info("workspace scan: starting")
+	defer p.Log.Info("workspace scan: complete")
+	for _, folder := range p.workspaceFolders {
+		root, err := uri.ParseDocumentURI(folder.URI)
+		if err != nil {
+			p.Log.Error("invalid uri", slog.String("uri", folder.URI))
+			continue
+		}
+		werr := filepath.WalkDir(root.Filename(), func(path string, d fs.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				// Carry on, so that one unreadable directory doesn't prevent the rest of the
+				// workspace from being scanned.
+				p.Log.Warn("workspace scan: failed to read", slog.String("path", path), slog.Any("error", err))
+				return nil
+			}
+			if d.IsDir() {
+				if path != root.Filename() && skipdir.ShouldSkip(path) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".templ") {
+				return nil
+			}
+			p.loadWorkspaceFile(ctx, uri.URIFromPath(path))
+			return nil
+		})
+		if werr != nil {
+			p.Log.Error("walk error", slog.Any("error", werr))
+		}
+	}
+}
+
+// loadWorkspaceFile reads a templ file from disk, publishes its diagnostics, and sends the
+// generated Go code to gopls, so that gopls publishes diagnostics for it too. Files that are
+// open in the editor are skipped, because the editor has the latest contents.
+func (p *Server) loadWorkspaceFile(ctx context.Context, templURI lsp.DocumentURI) {
+	log := p.Log.With(slog.String("uri", string(templURI)))
+	p.workspaceMu.Lock()
+	defer p.workspaceMu.Unlock()
+	if p.editorDocuments[string(templURI)] {
+		return
+	}
+	b, err := os.ReadFile(templURI.Filename())
+	if err != nil {
+		log.Warn("workspace scan: failed to read templ file", slog.Any("error", err))
+		return
+	}
+	p.TemplSource.Set(string(templURI), NewDocument(p.Log, string(b)))
+	template, _, err := p.parseTemplate(ctx, templURI, string(b))
+	if err != nil {
+		// It's expected to have some failures while parsing the template, since
+		// you are likely to have invalid docs while you're typing.
+		log.Info("parseTemplate failure", slog.Any("error", err))
+	}
+	if template == nil {
+		return
+	}
+	w := new(strings.Builder)
+	generatorOutput, err := generator.Generate(template, w)
+	if err != nil {
+		log.Info("generator failure", slog.Any("error", err))
+		return
+	}
+	p.SourceMapCache.Set(string(templURI), generatorOutput.SourceMap)
+	p.GoSource[string(templURI)] = w.String()
+
+	// Send the Go code to gopls as if the file was open, so that gopls type checks it.
+	_, goURI := convertTemplToGoURI(templURI)
+	version, loaded := p.diskDocuments[string(templURI)]
+	version++
+	p.diskDocuments[string(templURI)] = version
+	if !loaded {
+		err = p.Target.DidOpen(ctx, &lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{
+				URI:        goURI,
+				LanguageID: "go",
+				Version:    version,
+				Text:       w.String(),
+			},
+		})
+	} else {
+		err = p.Target.DidChange(ctx, &lsp.DidChangeTextDocumentParams{
+			TextDocument: lsp.VersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: lsp.TextDocumentIdentifier{URI: goURI},
+				Version:                version,
+			},
+			ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: w.String()}},
+		})
+	}
+	if err != nil {
+		log.Error("failed to send generated Go code to gopls", slog.Any("error", err))
+	}
+}
+
+// unloadWorkspaceFile removes a templ file that has been deleted from disk, and clears its
+// diagnostics.
+func (p *Server) unloadWorkspaceFile(ctx context.Context, templURI lsp.DocumentURI) {
+	p.workspaceMu.Lock()
+	defer p.workspaceMu.Unlock()
+	if _, loaded := p.diskDocuments[string(templURI)]; !loaded {
+		return
+	}
+	delete(p.diskDocuments, string(templURI))
+	p.TemplSource.Delete(string(templURI))
+	p.SourceMapCache.Delete(string(templURI))
+	delete(p.GoSource, string(templURI))
+	p.DiagnosticCache.Delete(string(templURI))
+
+	_, goURI := convertTemplToGoURI(templURI)
+	if err := p.Target.DidClose(ctx, &lsp.DidCloseTextDocumentParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: goURI},
+	}); err != nil {
+		p.Log.Error("failed to close generated Go code in gopls", slog.Any("error", err))
+	}
+	if client := lsp.ClientFromContext(ctx); client != nil {
+		err := client.PublishDiagnostics(ctx, &lsp.PublishDiagnosticsParams{
+			URI:         templURI,
+			Diagnostics: []lsp.Diagnostic{},
+		})
+		if err != nil {
+			p.Log.Error("failed to publish diagnostics", slog.Any("error", err))
+		}
+	}
+}
+
+// setEditorDocument records whether a templ file is open in the editor. Files that are open
+// in the editor are no longer loaded from disk. workspaceMu must be held.
+func (p *Server) setEditorDocument(templURI lsp.DocumentURI, open bool) {
+	if open {
+		p.editorDocuments[string(templURI)] = true
+		delete(p.diskDocuments, string(templURI))
+		return
+	}
+	delete(p.editorDocuments, string(templURI))
+}
@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	lsp "github.com/a-h/templ/lsp/protocol"
+	"github.com/a-h/templ/lsp/uri"
+)
+
+func TestWorkspaceDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, contents string) lsp.DocumentURI {
+		t.Helper()
+		fileName := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fileName), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fileName, []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write %q: %v", name, err)
+		}
+		return uri.URIFromPath(fileName)
+	}
+	valid := writeFile("valid.templ", "package main\n\ntempl valid() {\n\t<div></div>\n}\n")
+	deprecated := writeFile("deprecated.templ", "package main\n\ntempl deprecated() {\n\t{! valid() }\n}\n")
+	writeFile("node_modules/skipped.templ", "package main\n\ntempl skipped() {\n}\n")
+	_, validGoURI := convertTemplToGoURI(valid)
+	_, deprecatedGoURI := convertTemplToGoURI(deprecated)
+
+	mock := &mockServer{}
+	client := &mockClient{}
+	ctx := lsp.WithClient(context.Background(), client)
+	s := newTestServer(mock)
+	s.workspaceFolders = []lsp.WorkspaceFolder{{URI: string(uri.URIFromPath(dir))}}
+
+	diagnosticCounts := func() map[lsp.DocumentURI]int {
+		counts := map[lsp.DocumentURI]int{}
+		for _, params := range client.publishedDiagnostics {
+			counts[params.URI] = len(params.Diagnostics)
+		}
+		client.publishedDiagnostics = nil
+		return counts
+	}
+
+	t.Run("the scan publishes diagnostics for every templ file", func(t *testing.T) {
+		s.scanWorkspace(ctx)
+		if diff := cmp.Diff(map[lsp.DocumentURI]int{valid: 0, deprecated: 1}, diagnosticCounts()); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff([]lsp.DocumentURI{deprecatedGoURI, validGoURI}, mock.didOpenURIs); diff != "" {
+			t.Errorf("expected the generated Go code to be sent to gopls:\n%s", diff)
+		}
+		if _, ok := s.SourceMapCache.Get(string(valid)); !ok {
+			t.Error("expected the source map to be cached, so that gopls diagnostics can be mapped")
+		}
+	})
+	t.Run("changes on disk are sent to gopls", func(t *testing.T) {
+		mock.didOpenURIs = nil
+		writeFile("deprecated.templ", "package main\n\ntempl deprecated() {\n\t@valid()\n}\n")
+		err := s.DidChangeWatchedFiles(ctx, &lsp.DidChangeWatchedFilesParams{
+			Changes: []*lsp.FileEvent{{URI: deprecated, Type: lsp.FileChangeTypeChanged}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(map[lsp.DocumentURI]int{deprecated: 0}, diagnosticCounts()); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff([]lsp.DocumentURI{deprecatedGoURI}, mock.didChangeURIs); diff != "" {
+			t.Error(diff)
+		}
+		if mock.didChangeParams.TextDocument.Version != 2 {
+			t.Errorf("expected version 2, got %d", mock.didChangeParams.TextDocument.Version)
+		}
+	})
+	t.Run("files that are open in the editor are not loaded from disk", func(t *testing.T) {
+		mock.didChangeURIs = nil
+		err := s.HandleDidOpen(ctx, &lsp.DidOpenTextDocumentParams{
+			TextDocument: lsp.TextDocumentItem{URI: valid, Text: "package main\n\ntempl valid() {\n\t<span></span>\n}\n"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		diagnosticCounts()
+		s.scanWorkspace(ctx)
+		if diff := cmp.Diff(map[lsp.DocumentURI]int{deprecated: 0}, diagnosticCounts()); diff != "" {
+			t.Error(diff)
+		}
+		doc, _ := s.TemplSource.Get(string(valid))
+		if doc.String() != "package main\n\ntempl valid() {\n\t<span></span>\n}\n" {
+			t.Errorf("expected the editor contents to be kept, got %q", doc.String())
+		}
+	})
+	t.Run("files closed in the editor are loaded from disk", func(t *testing.T) {
+		mock.didOpenURIs = nil
+		err := s.DidClose(ctx, &lsp.DidCloseTextDocumentParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: valid},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]lsp.DocumentURI{validGoURI}, mock.didOpenURIs); diff != "" {
+			t.Error(diff)
+		}
+		doc, _ := s.TemplSource.Get(string(valid))
+		if doc.String() != "package main\n\ntempl valid() {\n\t<div></div>\n}\n" {
+			t.Errorf("expected the disk contents to be loaded, got %q", doc.String())
+		}
+	})
+	t.Run("deleted files are closed, and their diagnostics are cleared", func(t *testing.T) {
+		diagnosticCounts()
+		mock.didCloseURIs = nil
+		err := s.DidChangeWatchedFiles(ctx, &lsp.DidChangeWatchedFilesParams{
+			Changes: []*lsp.FileEvent{{URI: deprecated, Type: lsp.FileChangeTypeDeleted}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(map[lsp.DocumentURI]int{deprecated: 0}, diagnosticCounts()); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff([]lsp.DocumentURI{deprecatedGoURI}, mock.didCloseURIs); diff != "" {
+			t.Error(diff)
+		}
+		if _, ok := s.SourceMapCache.Get(string(deprecated)); ok {
+			t.Error("expected the source map to be removed")
+		}
+	})
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/a-h/templ"
 	"github.com/a-h/templ/cmd/templ/fmtcmd"
 	"github.com/a-h/templ/cmd/templ/generatecmd"
+	"github.com/a-h/templ/cmd/templ/i18ncmd"
 	"github.com/a-h/templ/cmd/templ/infocmd"
 	"github.com/a-h/templ/cmd/templ/lspcmd"
+	"github.com/a-h/templ/cmd/templ/playgroundcmd"
 	"github.com/a-h/templ/cmd/templ/sloghandler"
 	"github.com/a-h/templ/internal/format"
 	"github.com/fatih/color"
@@ -38,6 +40,8 @@ commands:
   fmt        Formats templ files
   lsp        Starts a language server for templ files
   info       Displays information about the templ environment
+  playground Starts a local playground for trying out templ in the browser
+  i18n       Extracts translatable messages from templ files
   version    Prints the version
 `
 
@@ -55,6 +59,10 @@ func run(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int) {
 		return fmtCmd(stdin, stdout, stderr, args[2:])
 	case "lsp":
 		return lspCmd(stdin, stdout, stderr, args[2:])
+	case "playground":
+		return playgroundCmd(stdout, stderr, args[2:])
+	case "i18n":
+		return i18nCmd(stdout, stderr, args[2:])
 	case "version", "--version":
 		_, _ = fmt.Fprintln(stdout, templ.Version())
 		return 0
@@ -177,6 +185,8 @@ Args:
     Set the command to use for formatting HTML, CSS, and JS blocks. Default is "prettier --stdin-filepath $TEMPL_PRETTIER_FILENAME".
   -prettier-required
     Set to true to return an error the prettier command is not available. Default is false.
+  -sort-classes
+    Sort classes in constant class attributes into Tailwind CSS order. Default is false.
   -fail
     Fails with exit code 1 if files are changed. (e.g. in CI)
   -help
@@ -192,6 +202,7 @@ func fmtCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 	failIfChanged := cmd.Bool("fail", false, "")
 	prettierCommand := cmd.String("prettier-command", "", "")
 	prettierRequired := cmd.Bool("prettier-required", false, "")
+	sortClassesFlag := cmd.Bool("sort-classes", false, "")
 	stdoutFlag := cmd.Bool("stdout", false, "")
 	stdinFilepath := cmd.String("stdin-filepath", "", "")
 	err := cmd.Parse(args)
@@ -214,6 +225,7 @@ func fmtCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 		FailIfChanged:    *failIfChanged,
 		PrettierCommand:  *prettierCommand,
 		PrettierRequired: *prettierRequired,
+		SortClasses:      *sortClassesFlag,
 	})
 	if err != nil {
 		return 1
@@ -241,11 +253,15 @@ Args:
   -http string
     Enable http debug server by setting a listen address (e.g. localhost:7474)
   -no-preload
-    Disable preloading of templ files on server startup and use custom GOPACKAGESDRIVER for lazy loading (useful for large monorepos). GOPACKAGESDRIVER environment variable must be set.
+    Disable preloading of templ files on server startup, and workspace diagnostics for templ files that aren't open, and use custom GOPACKAGESDRIVER for lazy loading (useful for large monorepos). GOPACKAGESDRIVER environment variable must be set.
   -prettier-command
     Set the command to use for formatting HTML, CSS, and JS blocks. Default is "prettier --stdin-filepath $TEMPL_PRETTIER_FILENAME".
   -prettier-required
     Set to true to return an error the prettier command is not available. Default is false.
+  -listen string
+    Accept LSP connections on an address instead of using stdin and stdout, e.g. tcp://127.0.0.1:7575 or ws://127.0.0.1:7575/lsp. Each connection is a separate session.
+  -allowed-origin string
+    Web origin allowed to connect to a ws:// listener, e.g. http://localhost:8080. Can be repeated. Clients that don't send an Origin header, and pages served from the listen address, are always allowed.
 `
 
 func lspCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int) {
@@ -260,6 +276,12 @@ func lspCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 	noPreloadFlag := cmd.Bool("no-preload", false, "")
 	prettierCommand := cmd.String("prettier-command", "", "")
 	prettierRequired := cmd.Bool("prettier-required", false, "")
+	listenFlag := cmd.String("listen", "", "")
+	var allowedOrigins []string
+	cmd.Func("allowed-origin", "", func(origin string) error {
+		allowedOrigins = append(allowedOrigins, origin)
+		return nil
+	})
 	err := cmd.Parse(args)
 	if err != nil {
 		_, _ = fmt.Fprint(stderr, lspUsageText)
@@ -282,6 +304,8 @@ func lspCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 			PrettierCommand:  *prettierCommand,
 			PrettierRequired: *prettierRequired,
 		},
+		Listen:         *listenFlag,
+		AllowedOrigins: allowedOrigins,
 	})
 	if err != nil {
 		_, _ = fmt.Fprintln(stderr, err.Error())
@@ -289,3 +313,148 @@ func lspCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 	}
 	return 0
 }
+
+const playgroundUsageText = `usage: templ playground [<args>...]
+
+Starts a local playground for trying out templ in the browser.
+
+The playground shows the generated Go code, the source map, the formatted
+templ code, and any errors or warnings. If Go is installed, components that
+don't take arguments can be rendered. The playground doesn't use the network.
+
+Args:
+  -addr string
+    The address to listen on. (default "127.0.0.1:7332")
+  -open-browser
+    Open the playground in the default browser. (default true)
+  -prettier-command
+    Set the command to use for formatting HTML, CSS, and JS blocks. Default is "prettier --stdin-filepath $TEMPL_PRETTIER_FILENAME".
+  -v
+    Set log verbosity level to "debug". (default "info")
+  -log-level
+    Set log verbosity level. (default "info", options: "debug", "info", "warn", "error")
+  -help
+    Print help and exit.
+`
+
+func playgroundCmd(stdout, stderr io.Writer, args []string) (code int) {
+	cmd := flag.NewFlagSet("playground", flag.ExitOnError)
+	addrFlag := cmd.String("addr", "127.0.0.1:7332", "")
+	openBrowserFlag := cmd.Bool("open-browser", true, "")
+	prettierCommand := cmd.String("prettier-command", "", "")
+	verboseFlag := cmd.Bool("v", false, "")
+	logLevelFlag := cmd.String("log-level", "info", "")
+	helpFlag := cmd.Bool("help", false, "")
+	err := cmd.Parse(args)
+	if err != nil {
+		_, _ = fmt.Fprint(stderr, playgroundUsageText)
+		return 64 // EX_USAGE
+	}
+	if *helpFlag {
+		_, _ = fmt.Fprint(stdout, playgroundUsageText)
+		return
+	}
+
+	log := sloghandler.NewLogger(*logLevelFlag, *verboseFlag, stderr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signalChan
+		_, _ = fmt.Fprintln(stderr, "Stopping...")
+		cancel()
+	}()
+
+	err = playgroundcmd.Run(ctx, log, stdout, playgroundcmd.Arguments{
+		Address: *addrFlag,
+		Open:    *openBrowserFlag,
+		FormatConfig: format.Config{
+			PrettierCommand: *prettierCommand,
+		},
+	})
+	if err != nil {
+		_, _ = color.New(color.FgRed).Fprint(stderr, "(✗) ")
+		_, _ = fmt.Fprintln(stderr, "Command failed: "+err.Error())
+		return 1
+	}
+	return 0
+}
+
+const i18nUsageText = `usage: templ i18n <command> [<args>...]
+
+Extracts translatable messages from templ files.
+
+Mark elements as translatable with the i18n attribute, or mark all of the text
+in a template by adding a //templ:i18n comment on the line before it.
+
+commands:
+  extract    Writes a gettext .pot or XLIFF catalog of the translatable messages
+
+Extract messages from all templ files in the current directory:
+
+  templ i18n extract -o messages.pot
+
+usage: templ i18n extract [<args>...] [<path>...]
+
+Args:
+  -format string
+    Format of the catalog. (default "pot", options: "pot", "xliff")
+  -o string
+    File to write the catalog to, or leave empty to write to stdout.
+  -source-lang string
+    Language of the messages in the templ files, used in XLIFF catalogs. (default "en")
+  -v
+    Set log verbosity level to "debug". (default "info")
+  -log-level
+    Set log verbosity level. (default "info", options: "debug", "info", "warn", "error")
+  -help
+    Print help and exit.
+`
+
+func i18nCmd(stdout, stderr io.Writer, args []string) (code int) {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(stderr, i18nUsageText)
+		return 64 // EX_USAGE
+	}
+	switch args[0] {
+	case "extract":
+	case "help", "-help", "--help", "-h":
+		_, _ = fmt.Fprint(stdout, i18nUsageText)
+		return 0
+	default:
+		_, _ = fmt.Fprint(stderr, i18nUsageText)
+		return 64 // EX_USAGE
+	}
+	cmd := flag.NewFlagSet("extract", flag.ExitOnError)
+	formatFlag := cmd.String("format", "pot", "")
+	outputFlag := cmd.String("o", "", "")
+	sourceLangFlag := cmd.String("source-lang", "en", "")
+	verboseFlag := cmd.Bool("v", false, "")
+	logLevelFlag := cmd.String("log-level", "info", "")
+	helpFlag := cmd.Bool("help", false, "")
+	err := cmd.Parse(args[1:])
+	if err != nil {
+		_, _ = fmt.Fprint(stderr, i18nUsageText)
+		return 64 // EX_USAGE
+	}
+	if *helpFlag {
+		_, _ = fmt.Fprint(stdout, i18nUsageText)
+		return
+	}
+
+	log := sloghandler.NewLogger(*logLevelFlag, *verboseFlag, stderr)
+
+	err = i18ncmd.Extract(log, stdout, i18ncmd.Arguments{
+		Format:         *formatFlag,
+		Output:         *outputFlag,
+		SourceLanguage: *sourceLangFlag,
+		Paths:          cmd.Args(),
+	})
+	if err != nil {
+		_, _ = color.New(color.FgRed).Fprint(stderr, "(✗) ")
+		_, _ = fmt.Fprintln(stderr, "Command failed: "+err.Error())
+		return 1
+	}
+	return 0
+}
@@ -65,6 +65,18 @@ func TestMain(t *testing.T) {
 			expectedStdout: infoUsageText,
 			expectedCode:   0,
 		},
+		{
+			name:           `"templ playground --help" prints usage`,
+			args:           []string{"templ", "playground", "--help"},
+			expectedStdout: playgroundUsageText,
+			expectedCode:   0,
+		},
+		{
+			name:           `"templ i18n --help" prints usage`,
+			args:           []string{"templ", "i18n", "--help"},
+			expectedStdout: i18nUsageText,
+			expectedCode:   0,
+		},
 	}
 
 	for _, test := range tests {
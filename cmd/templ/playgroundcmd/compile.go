@@ -0,0 +1,148 @@
+package playgroundcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go/format"
+	"go/scanner"
+	"strings"
+
+	"github.com/a-h/parse"
+	"github.com/a-h/templ/cmd/templ/visualize"
+	"github.com/a-h/templ/generator"
+	internalformat "github.com/a-h/templ/internal/format"
+	"github.com/a-h/templ/parser/v2"
+)
+
+// fileName is the name of the templ file shown in the playground.
+const fileName = "playground.templ"
+
+// Diagnostic is a problem with the templ source. Line and Col are zero based.
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+}
+
+// CompileResult is the output of compiling templ source in the playground.
+type CompileResult struct {
+	// Go is the generated Go code.
+	Go string `json:"go"`
+	// Formatted is the templ source, formatted by templ fmt.
+	Formatted string `json:"formatted"`
+	// Visualisation is a HTML page that shows how the templ source maps to the generated Go code.
+	Visualisation string `json:"visualisation"`
+	// Components are the names of components that can be rendered without arguments.
+	Components  []string     `json:"components"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// Compile parses, formats and generates Go code from the templ source. Problems with the
+// source are returned as diagnostics rather than as an error.
+func Compile(ctx context.Context, src string, formatConfig internalformat.Config) (result CompileResult, err error) {
+	result.Diagnostics = []Diagnostic{}
+	result.Components = []string{}
+
+	tf, err := parser.ParseString(src)
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, errorDiagnostic(err))
+		return result, nil
+	}
+	tf.Filepath = fileName
+	diagnostics, err := parser.Diagnose(tf)
+	if err != nil {
+		return result, err
+	}
+	for _, d := range diagnostics {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity: "warning",
+			Message:  d.Message,
+			Line:     int(d.Range.From.Line),
+			Col:      int(d.Range.From.Col),
+		})
+	}
+	result.Components = renderableComponents(tf)
+
+	// Generate before formatting, because formatting modifies the parsed template.
+	var b bytes.Buffer
+	output, err := generator.Generate(tf, &b, generator.WithFileName(fileName))
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, errorDiagnostic(err))
+		return result, nil
+	}
+	goCode, err := format.Source(b.Bytes())
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, goErrorDiagnostics(err, output.SourceMap)...)
+		goCode = b.Bytes()
+	}
+	result.Go = string(goCode)
+
+	var vis strings.Builder
+	if err = visualize.HTML(fileName, src, result.Go, output.SourceMap).Render(ctx, &vis); err != nil {
+		return result, err
+	}
+	result.Visualisation = vis.String()
+
+	formatted, _, err := internalformat.Templ([]byte(src), "", formatConfig)
+	if err != nil {
+		result.Diagnostics = append(result.Diagnostics, errorDiagnostic(err))
+		return result, nil
+	}
+	result.Formatted = string(formatted)
+	return result, nil
+}
+
+// renderableComponents returns the names of templ components that don't take arguments,
+// and aren't methods, since these can be rendered without any more information.
+func renderableComponents(tf *parser.TemplateFile) (names []string) {
+	names = []string{}
+	for _, n := range tf.Nodes {
+		t, ok := n.(*parser.HTMLTemplate)
+		if !ok {
+			continue
+		}
+		name, args, ok := strings.Cut(strings.TrimSpace(t.Expression.Value), "(")
+		if !ok || strings.TrimSpace(args) != ")" || strings.ContainsAny(name, " [") {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func errorDiagnostic(err error) Diagnostic {
+	d := Diagnostic{
+		Severity: "error",
+		Message:  err.Error(),
+	}
+	var pe parse.ParseError
+	if errors.As(err, &pe) {
+		d.Message = pe.Msg
+		d.Line = pe.Pos.Line
+		d.Col = pe.Pos.Col
+	}
+	return d
+}
+
+// goErrorDiagnostics maps errors in the generated Go code back to the templ source.
+func goErrorDiagnostics(err error, sourceMap *parser.SourceMap) (diagnostics []Diagnostic) {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return []Diagnostic{errorDiagnostic(err)}
+	}
+	for _, e := range list {
+		d := Diagnostic{
+			Severity: "error",
+			Message:  e.Msg,
+		}
+		// go/scanner positions are one based.
+		if src, ok := sourceMap.SourcePositionFromTarget(uint32(e.Pos.Line-1), uint32(e.Pos.Column-1)); ok {
+			d.Line = int(src.Line)
+			d.Col = int(src.Col)
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
@@ -0,0 +1,170 @@
+package playgroundcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/internal/format"
+)
+
+// maxSourceSize is the maximum size of templ source accepted by the playground.
+const maxSourceSize = 1 << 20
+
+type compileRequest struct {
+	Source string `json:"source"`
+}
+
+type renderRequest struct {
+	Source    string `json:"source"`
+	Component string `json:"component"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the playground page, and the API used by the page.
+type Handler struct {
+	Log *slog.Logger
+	// Addr is the address the server listens on, e.g. 127.0.0.1:7332. Requests must be addressed
+	// to it, or to a loopback name with the same port, so that DNS rebinding can't be used to
+	// reach the playground from other web pages.
+	Addr         string
+	FormatConfig format.Config
+	// Render renders a component to HTML, or is nil if rendering is not available.
+	Render func(ctx context.Context, src, component string) (RenderResult, error)
+	// Example is the templ source shown when the page is first loaded.
+	Example string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.checkHost(r); err != nil {
+		h.Log.Warn("Rejected request", slog.String("path", r.URL.Path), slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	switch r.URL.Path {
+	case "/":
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		templ.Handler(page(h.Example, h.Render != nil)).ServeHTTP(w, r)
+	case "/compile":
+		h.compile(w, r)
+	case "/render":
+		h.render(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) compile(w http.ResponseWriter, r *http.Request) {
+	var req compileRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	result, err := Compile(r.Context(), req.Source, h.FormatConfig)
+	if err != nil {
+		h.Log.Error("Failed to compile", slog.Any("error", err))
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request) {
+	var req renderRequest
+	if !h.decodeRequest(w, r, &req) {
+		return
+	}
+	if h.Render == nil {
+		writeJSON(w, http.StatusNotImplemented, errorResponse{Error: ErrGoNotFound.Error()})
+		return
+	}
+	result, err := h.Render(r.Context(), req.Source, req.Component)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrGoNotFound) {
+			status = http.StatusNotImplemented
+		}
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// decodeRequest reads a JSON request body. Requests must be JSON, and must come from the
+// playground page, so that other web pages can't use the playground to run code.
+func (h *Handler) decodeRequest(w http.ResponseWriter, r *http.Request, v any) (ok bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := checkOrigin(r); err != nil {
+		h.Log.Warn("Rejected request", slog.String("path", r.URL.Path), slog.Any("error", err))
+		writeJSON(w, http.StatusForbidden, errorResponse{Error: err.Error()})
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, errorResponse{Error: "expected application/json"})
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSourceSize)).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request: %v", err)})
+		return false
+	}
+	return true
+}
+
+// loopbackHosts are the names of the loopback interface.
+var loopbackHosts = []string{"127.0.0.1", "localhost", "::1"}
+
+func (h *Handler) checkHost(r *http.Request) error {
+	if r.Host == h.Addr {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		return fmt.Errorf("host %q is not allowed", r.Host)
+	}
+	_, addrPort, err := net.SplitHostPort(h.Addr)
+	if err != nil || port != addrPort || !slices.Contains(loopbackHosts, strings.ToLower(host)) {
+		return fmt.Errorf("host %q is not allowed", r.Host)
+	}
+	return nil
+}
+
+// checkOrigin requires requests to come from the playground page. Browsers send the Origin
+// header with every POST request, so requests without one didn't come from the page.
+func checkOrigin(r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return errors.New("missing origin")
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("invalid origin %q: %w", origin, err)
+	}
+	if u.Scheme != "http" || u.Host != r.Host {
+		return fmt.Errorf("origin %q is not allowed", origin)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,82 @@
+package playgroundcmd
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/a-h/templ/internal/format"
+	"github.com/cli/browser"
+)
+
+//go:embed example.templ.txt
+var example string
+
+type Arguments struct {
+	// Address to listen on, e.g. 127.0.0.1:7331.
+	Address string
+	// Open the playground in the default browser.
+	Open bool
+	// RenderTimeout is the maximum time to wait for a component to be rendered.
+	RenderTimeout time.Duration
+	FormatConfig  format.Config
+}
+
+// Run starts the playground server, and waits for the context to be cancelled.
+func Run(ctx context.Context, log *slog.Logger, stdout io.Writer, args Arguments) (err error) {
+	if args.Address == "" {
+		args.Address = "127.0.0.1:7332"
+	}
+	if args.RenderTimeout == 0 {
+		args.RenderTimeout = 30 * time.Second
+	}
+	h := &Handler{
+		Log:          log,
+		FormatConfig: args.FormatConfig,
+		Example:      example,
+	}
+	if goAvailable() {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working directory: %w", err)
+		}
+		h.Render = renderer{dir: wd, timeout: args.RenderTimeout}.Render
+	} else {
+		log.Warn("Go is not installed, components can't be rendered in the playground")
+	}
+
+	ln, err := net.Listen("tcp", args.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", args.Address, err)
+	}
+	h.Addr = ln.Addr().String()
+	url := "http://" + h.Addr
+	_, _ = fmt.Fprintf(stdout, "templ playground running at %s\n", url)
+	if args.Open {
+		if err := browser.OpenURL(url); err != nil {
+			log.Error("Failed to open browser", slog.Any("error", err))
+		}
+	}
+
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	if err = srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
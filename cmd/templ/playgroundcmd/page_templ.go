@@ -0,0 +1,67 @@
+// Code generated by templ - DO NOT EDIT.
+
+package playgroundcmd
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+type pageConfig struct {
+	Example   string `json:"example"`
+	CanRender bool   `json:"canRender"`
+}
+
+func page(example string, canRender bool) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html lang=\"en\"><head><meta charset=\"utf-8\"><title>templ playground</title><style type=\"text/css\">\n\t\t\t\t* {\n\t\t\t\t\tbox-sizing: border-box;\n\t\t\t\t}\n\t\t\t\tbody {\n\t\t\t\t\tmargin: 0;\n\t\t\t\t\tfont-family: sans-serif;\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tflex-direction: column;\n\t\t\t\t\theight: 100vh;\n\t\t\t\t}\n\t\t\t\theader {\n\t\t\t\t\tpadding: 0.5rem 1rem;\n\t\t\t\t\tborder-bottom: 1px solid #ccc;\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tgap: 1rem;\n\t\t\t\t\talign-items: center;\n\t\t\t\t}\n\t\t\t\tmain {\n\t\t\t\t\tflex: 1;\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tmin-height: 0;\n\t\t\t\t}\n\t\t\t\tmain > section {\n\t\t\t\t\tflex: 1;\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tflex-direction: column;\n\t\t\t\t\tmin-width: 0;\n\t\t\t\t}\n\t\t\t\t#source, pre {\n\t\t\t\t\tflex: 1;\n\t\t\t\t\tmargin: 0;\n\t\t\t\t\tpadding: 0.5rem;\n\t\t\t\t\tfont-family: monospace;\n\t\t\t\t\tfont-size: 0.9rem;\n\t\t\t\t\ttab-size: 4;\n\t\t\t\t\toverflow: auto;\n\t\t\t\t}\n\t\t\t\t#source {\n\t\t\t\t\tborder: none;\n\t\t\t\t\tborder-right: 1px solid #ccc;\n\t\t\t\t\tresize: none;\n\t\t\t\t}\n\t\t\t\tiframe {\n\t\t\t\t\tflex: 1;\n\t\t\t\t\tborder: none;\n\t\t\t\t}\n\t\t\t\tnav button[aria-selected=\"true\"] {\n\t\t\t\t\tfont-weight: bold;\n\t\t\t\t}\n\t\t\t\t.tab {\n\t\t\t\t\tflex: 1;\n\t\t\t\t\tdisplay: flex;\n\t\t\t\t\tflex-direction: column;\n\t\t\t\t\tmin-height: 0;\n\t\t\t\t}\n\t\t\t\t.tab[hidden] {\n\t\t\t\t\tdisplay: none;\n\t\t\t\t}\n\t\t\t\t.error {\n\t\t\t\t\tcolor: #b00;\n\t\t\t\t}\n\t\t\t\t.warning {\n\t\t\t\t\tcolor: #a60;\n\t\t\t\t}\n\t\t\t\t#diagnostics li {\n\t\t\t\t\tfont-family: monospace;\n\t\t\t\t\tcursor: pointer;\n\t\t\t\t}\n\t\t\t</style></head><body><header><strong>templ playground</strong><nav><button type=\"button\" data-tab=\"go\" aria-selected=\"true\">Generated Go</button> <button type=\"button\" data-tab=\"sourcemap\">Source map</button> <button type=\"button\" data-tab=\"formatted\">Formatted</button> <button type=\"button\" data-tab=\"diagnostics\">Diagnostics (<span id=\"diagnostic-count\">0</span>)</button> <button type=\"button\" data-tab=\"rendered\">Rendered</button></nav></header><main><section><textarea id=\"source\" spellcheck=\"false\" aria-label=\"templ source\"></textarea></section><section><div class=\"tab\" id=\"tab-go\"><pre id=\"go\"></pre></div><div class=\"tab\" id=\"tab-sourcemap\" hidden><iframe id=\"sourcemap\" title=\"Source map\" sandbox=\"allow-scripts\"></iframe></div><div class=\"tab\" id=\"tab-formatted\" hidden><div><button type=\"button\" id=\"apply-format\">Apply</button></div><pre id=\"formatted\"></pre></div><div class=\"tab\" id=\"tab-diagnostics\" hidden><ul id=\"diagnostics\"></ul></div><div class=\"tab\" id=\"tab-rendered\" hidden>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if canRender {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div><select id=\"component\" aria-label=\"Component\"></select> <button type=\"button\" id=\"render\">Render</button></div><pre id=\"render-output\" class=\"error\" hidden></pre><iframe id=\"rendered\" title=\"Rendered output\" sandbox=\"\"></iframe>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		} else {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<p>Go is not installed, so components can't be rendered.</p>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div></section></main>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templ.JSONScript("config", pageConfig{Example: example, CanRender: canRender}).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<script type=\"text/javascript\">\n\t\t\t\tconst config = JSON.parse(document.getElementById(\"config\").textContent);\n\t\t\t\tconst source = document.getElementById(\"source\");\n\t\t\t\tsource.value = config.example;\n\n\t\t\t\tdocument.querySelectorAll(\"nav button\").forEach((button) => {\n\t\t\t\t\tbutton.addEventListener(\"click\", () => selectTab(button.dataset.tab));\n\t\t\t\t});\n\t\t\t\tfunction selectTab(name) {\n\t\t\t\t\tdocument.querySelectorAll(\"nav button\").forEach((b) => b.setAttribute(\"aria-selected\", b.dataset.tab === name));\n\t\t\t\t\tdocument.querySelectorAll(\".tab\").forEach((t) => t.hidden = t.id !== \"tab-\" + name);\n\t\t\t\t}\n\n\t\t\t\t// Allow tabs to be entered in the editor.\n\t\t\t\tsource.addEventListener(\"keydown\", (e) => {\n\t\t\t\t\tif (e.key !== \"Tab\") {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\te.preventDefault();\n\t\t\t\t\tsource.setRangeText(\"\\t\", source.selectionStart, source.selectionEnd, \"end\");\n\t\t\t\t\tscheduleCompile();\n\t\t\t\t});\n\n\t\t\t\tasync function post(path, body) {\n\t\t\t\t\tconst resp = await fetch(path, {\n\t\t\t\t\t\tmethod: \"POST\",\n\t\t\t\t\t\theaders: { \"Content-Type\": \"application/json\" },\n\t\t\t\t\t\tbody: JSON.stringify(body),\n\t\t\t\t\t});\n\t\t\t\t\tconst result = await resp.json();\n\t\t\t\t\tif (!resp.ok) {\n\t\t\t\t\t\tthrow new Error(result.error);\n\t\t\t\t\t}\n\t\t\t\t\treturn result;\n\t\t\t\t}\n\n\t\t\t\tlet formatted = \"\";\n\t\t\t\tasync function compile() {\n\t\t\t\t\tlet result;\n\t\t\t\t\ttry {\n\t\t\t\t\t\tresult = await post(\"/compile\", { source: source.value });\n\t\t\t\t\t} catch (e) {\n\t\t\t\t\t\tresult = { diagnostics: [{ severity: \"error\", message: e.message, line: 0, col: 0 }], components: [] };\n\t\t\t\t\t}\n\t\t\t\t\tdocument.getElementById(\"go\").textContent = result.go || \"\";\n\t\t\t\t\tdocument.getElementById(\"sourcemap\").srcdoc = result.visualisation || \"\";\n\t\t\t\t\tformatted = result.formatted || \"\";\n\t\t\t\t\tdocument.getElementById(\"formatted\").textContent = formatted;\n\t\t\t\t\tshowDiagnostics(result.diagnostics);\n\t\t\t\t\tupdateComponents(result.components);\n\t\t\t\t}\n\n\t\t\t\tfunction showDiagnostics(diagnostics) {\n\t\t\t\t\tdocument.getElementById(\"diagnostic-count\").textContent = diagnostics.length;\n\t\t\t\t\tconst list = document.getElementById(\"diagnostics\");\n\t\t\t\t\tlist.replaceChildren(...diagnostics.map((d) => {\n\t\t\t\t\t\tconst li = document.createElement(\"li\");\n\t\t\t\t\t\tli.className = d.severity;\n\t\t\t\t\t\tli.textContent = `${d.line + 1}:${d.col + 1}: ${d.message}`;\n\t\t\t\t\t\tli.addEventListener(\"click\", () => moveCursor(d.line, d.col));\n\t\t\t\t\t\treturn li;\n\t\t\t\t\t}));\n\t\t\t\t}\n\n\t\t\t\tfunction moveCursor(line, col) {\n\t\t\t\t\tconst lines = source.value.split(\"\\n\");\n\t\t\t\t\tlet index = 0;\n\t\t\t\t\tfor (let i = 0; i < line && i < lines.length; i++) {\n\t\t\t\t\t\tindex += lines[i].length + 1;\n\t\t\t\t\t}\n\t\t\t\t\tsource.focus();\n\t\t\t\t\tsource.setSelectionRange(index + col, index + col);\n\t\t\t\t}\n\n\t\t\t\tfunction updateComponents(components) {\n\t\t\t\t\tconst select = document.getElementById(\"component\");\n\t\t\t\t\tif (!select) {\n\t\t\t\t\t\treturn;\n\t\t\t\t\t}\n\t\t\t\t\tconst selected = select.value;\n\t\t\t\t\tselect.replaceChildren(...components.map((name) => new Option(name, name, false, name === selected)));\n\t\t\t\t}\n\n\t\t\t\tlet timer;\n\t\t\t\tfunction scheduleCompile() {\n\t\t\t\t\tclearTimeout(timer);\n\t\t\t\t\ttimer = setTimeout(compile, 300);\n\t\t\t\t}\n\t\t\t\tsource.addEventListener(\"input\", scheduleCompile);\n\n\t\t\t\tdocument.getElementById(\"apply-format\").addEventListener(\"click\", () => {\n\t\t\t\t\tif (formatted) {\n\t\t\t\t\t\tsource.value = formatted;\n\t\t\t\t\t\tscheduleCompile();\n\t\t\t\t\t}\n\t\t\t\t});\n\n\t\t\t\tif (config.canRender) {\n\t\t\t\t\tdocument.getElementById(\"render\").addEventListener(\"click\", async () => {\n\t\t\t\t\t\tconst output = document.getElementById(\"render-output\");\n\t\t\t\t\t\tconst frame = document.getElementById(\"rendered\");\n\t\t\t\t\t\toutput.hidden = true;\n\t\t\t\t\t\ttry {\n\t\t\t\t\t\t\tconst result = await post(\"/render\", { source: source.value, component: document.getElementById(\"component\").value });\n\t\t\t\t\t\t\tframe.srcdoc = result.html;\n\t\t\t\t\t\t\tif (result.output) {\n\t\t\t\t\t\t\t\toutput.textContent = result.output;\n\t\t\t\t\t\t\t\toutput.hidden = false;\n\t\t\t\t\t\t\t}\n\t\t\t\t\t\t} catch (e) {\n\t\t\t\t\t\t\toutput.textContent = e.message;\n\t\t\t\t\t\t\toutput.hidden = false;\n\t\t\t\t\t\t}\n\t\t\t\t\t});\n\t\t\t\t}\n\n\t\t\t\tcompile();\n\t\t\t</script></body></html>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
@@ -0,0 +1,230 @@
+package playgroundcmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ/internal/format"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompile(t *testing.T) {
+	t.Run("valid templates are generated and formatted", func(t *testing.T) {
+		src := "package main\n\ntempl Hello(name string) {\n<p>{ name }</p>\n}\n\ntempl Page() {\n@Hello(\"World\")\n}\n"
+		result, err := Compile(context.Background(), src, format.Config{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Diagnostics) != 0 {
+			t.Errorf("expected no diagnostics, got %v", result.Diagnostics)
+		}
+		if !strings.Contains(result.Go, "func Hello(name string) templ.Component {") {
+			t.Errorf("expected generated Go code, got %q", result.Go)
+		}
+		if !strings.Contains(result.Formatted, "\t<p>{ name }</p>\n") {
+			t.Errorf("expected formatted templ code, got %q", result.Formatted)
+		}
+		if !strings.Contains(result.Visualisation, "Source Map Visualisation") {
+			t.Error("expected source map visualisation")
+		}
+		if diff := cmp.Diff([]string{"Page"}, result.Components); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("parse errors are returned as diagnostics", func(t *testing.T) {
+		src := "package main\n\ntempl Hello() {\n\t<p>\n}\n"
+		result, err := Compile(context.Background(), src, format.Config{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Diagnostics) != 1 {
+			t.Fatalf("expected 1 diagnostic, got %v", result.Diagnostics)
+		}
+		d := result.Diagnostics[0]
+		if d.Severity != "error" || !strings.Contains(d.Message, "close tag not found") {
+			t.Errorf("expected a missing close tag error, got %+v", d)
+		}
+	})
+	t.Run("invalid Go expressions are mapped to the templ source", func(t *testing.T) {
+		src := "package main\n\ntempl Hello() {\n\t<p>{ 1 + }</p>\n}\n"
+		result, err := Compile(context.Background(), src, format.Config{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Diagnostics) == 0 {
+			t.Fatal("expected diagnostics")
+		}
+		if result.Diagnostics[0].Line != 3 {
+			t.Errorf("expected the error to be on line 3, got %+v", result.Diagnostics[0])
+		}
+	})
+}
+
+func TestRenderableComponents(t *testing.T) {
+	src := `package main
+
+templ A() {
+}
+
+templ B(name string) {
+}
+
+templ (c C) D() {
+}
+
+templ E[T any]() {
+}
+`
+	result, err := Compile(context.Background(), src, format.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"A"}, result.Components); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h := &Handler{
+		Log:     slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		Addr:    "127.0.0.1:7332",
+		Example: "package main\n",
+	}
+	tests := []struct {
+		name           string
+		method         string
+		host           string
+		path           string
+		contentType    string
+		origin         string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "the page is served",
+			method:         http.MethodGet,
+			path:           "/",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "the page is served on loopback names",
+			method:         http.MethodGet,
+			host:           "localhost:7332",
+			path:           "/",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "requests to other hosts are rejected",
+			method:         http.MethodGet,
+			host:           "attacker.example.com:7332",
+			path:           "/",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "requests to loopback names on other ports are rejected",
+			method:         http.MethodGet,
+			host:           "localhost:8080",
+			path:           "/",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "templates can be compiled",
+			method:         http.MethodPost,
+			path:           "/compile",
+			contentType:    "application/json",
+			origin:         "http://127.0.0.1:7332",
+			body:           `{"source":"package main\n"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rebound hosts are rejected, even from the same origin",
+			method:         http.MethodPost,
+			host:           "attacker.example.com:7332",
+			path:           "/compile",
+			contentType:    "application/json",
+			origin:         "http://attacker.example.com:7332",
+			body:           `{"source":"package main\n"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "requests from other origins are rejected",
+			method:         http.MethodPost,
+			path:           "/compile",
+			contentType:    "application/json",
+			origin:         "http://attacker.example.com",
+			body:           `{"source":"package main\n"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "requests without an origin are rejected",
+			method:         http.MethodPost,
+			path:           "/render",
+			contentType:    "application/json",
+			body:           `{"source":"package main\n","component":"A"}`,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "form posts are rejected",
+			method:         http.MethodPost,
+			path:           "/compile",
+			contentType:    "application/x-www-form-urlencoded",
+			origin:         "http://127.0.0.1:7332",
+			body:           `source=x`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "rendering is not available without Go",
+			method:         http.MethodPost,
+			path:           "/render",
+			contentType:    "application/json",
+			origin:         "http://127.0.0.1:7332",
+			body:           `{"source":"package main\n","component":"A"}`,
+			expectedStatus: http.StatusNotImplemented,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "http://127.0.0.1:7332"+tt.path, strings.NewReader(tt.body))
+			if tt.host != "" {
+				r.Host = tt.host
+			}
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that runs the Go toolchain in short mode")
+	}
+	if !goAvailable() {
+		t.Skip("go is not installed")
+	}
+	r := renderer{dir: ".", timeout: time.Minute}
+	src := "package components\n\ntempl Hello() {\n\t<p>Hello</p>\n}\n"
+	result, err := r.Render(context.Background(), src, "Hello")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.HTML != "<p>Hello</p>" {
+		data, _ := json.Marshal(result)
+		t.Errorf("expected rendered HTML, got %s", data)
+	}
+}
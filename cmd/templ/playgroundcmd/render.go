@@ -0,0 +1,181 @@
+package playgroundcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/cmd/templ/generatecmd/modcheck"
+	"github.com/a-h/templ/generator"
+	"github.com/a-h/templ/parser/v2"
+	"golang.org/x/mod/modfile"
+)
+
+// ErrGoNotFound is returned when a component is rendered, but the Go toolchain is not installed.
+var ErrGoNotFound = errors.New("go is not installed, components can't be rendered")
+
+// RenderResult is the output of rendering a component in the playground.
+type RenderResult struct {
+	// HTML is the output of the component.
+	HTML string `json:"html"`
+	// Output is the output of the Go toolchain, if the component could not be rendered.
+	Output string `json:"output"`
+}
+
+// renderer renders components by running the generated code with `go run`.
+type renderer struct {
+	// dir is the directory to start searching for a go.mod file that requires templ.
+	dir     string
+	timeout time.Duration
+}
+
+// Render generates the templ source as a main package, and renders the component to HTML.
+//
+// If the go.mod file in the working directory requires templ, the program is built within
+// that module, so that the same version of templ is used. Otherwise, a temporary module is
+// created that requires the version of templ that matches the CLI. The network is never
+// used, so the templ module must already be in the module cache.
+func (r renderer) Render(ctx context.Context, src, component string) (result RenderResult, err error) {
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		return result, ErrGoNotFound
+	}
+	if !isRenderable(src, component) {
+		return result, fmt.Errorf("component %q not found, only components without arguments can be rendered", component)
+	}
+
+	tf, err := parser.ParseString(src)
+	if err != nil {
+		return result, fmt.Errorf("failed to parse templ source: %w", err)
+	}
+	tf.Package.Expression.Value = "package main"
+	var code bytes.Buffer
+	if _, err = generator.Generate(tf, &code, generator.WithFileName(fileName)); err != nil {
+		return result, fmt.Errorf("failed to generate Go code: %w", err)
+	}
+
+	dir, cleanup, env, err := r.createModule()
+	if err != nil {
+		return result, err
+	}
+	defer cleanup()
+	if err = os.WriteFile(filepath.Join(dir, "playground_templ.go"), code.Bytes(), 0644); err != nil {
+		return result, fmt.Errorf("failed to write generated code: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "main.go"), []byte(fmt.Sprintf(mainTemplate, component)), 0644); err != nil {
+		return result, fmt.Errorf("failed to write main.go: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, goPath, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return result, fmt.Errorf("rendering timed out after %v", r.timeout)
+		}
+		result.Output = stderr.String()
+		return result, nil
+	}
+	result.HTML = stdout.String()
+	return result, nil
+}
+
+const mainTemplate = `package main
+
+import (
+	"context"
+	"os"
+)
+
+func main() {
+	if err := %s().Render(context.Background(), os.Stdout); err != nil {
+		os.Stderr.WriteString(err.Error())
+		os.Exit(1)
+	}
+}
+`
+
+func isRenderable(src, component string) bool {
+	tf, err := parser.ParseString(src)
+	if err != nil {
+		return false
+	}
+	for _, name := range renderableComponents(tf) {
+		if name == component {
+			return true
+		}
+	}
+	return false
+}
+
+// createModule returns a directory to build the playground program in, and the environment
+// variables to build it with.
+func (r renderer) createModule() (dir string, cleanup func(), env []string, err error) {
+	env = []string{"GOPROXY=off"}
+	if modDir, ok := findTemplModule(r.dir); ok {
+		// The directory name starts with a dot, so it's ignored by `go build ./...`.
+		dir, err = os.MkdirTemp(modDir, ".templ-playground-")
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		return dir, func() { _ = os.RemoveAll(dir) }, env, nil
+	}
+	dir, err = os.MkdirTemp("", "templ-playground-")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(dir) }
+	goMod := fmt.Sprintf("module playground\n\ngo 1.23\n\nrequire github.com/a-h/templ %s\n", templ.Version())
+	if err = os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		cleanup()
+		return "", nil, nil, fmt.Errorf("failed to write go.mod: %w", err)
+	}
+	// There's no go.sum, and the checksum database can't be reached without the network, so
+	// the go.sum is populated from the module cache.
+	env = append(env, "GOFLAGS=-mod=mod", "GOSUMDB=off", "GOWORK=off")
+	return dir, cleanup, env, nil
+}
+
+// findTemplModule returns the directory of the module that contains dir, if the module is
+// templ itself, or requires templ.
+func findTemplModule(dir string) (modDir string, ok bool) {
+	modDir, err := modcheck.WalkUp(dir)
+	if err != nil {
+		return "", false
+	}
+	fileName := filepath.Join(modDir, "go.mod")
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return "", false
+	}
+	mf, err := modfile.Parse(fileName, data, nil)
+	if err != nil || mf.Module == nil {
+		return "", false
+	}
+	if mf.Module.Mod.Path == "github.com/a-h/templ" {
+		return modDir, true
+	}
+	for _, r := range mf.Require {
+		if r.Mod.Path == "github.com/a-h/templ" {
+			return modDir, true
+		}
+	}
+	return "", false
+}
+
+// goAvailable returns true if the Go toolchain is installed.
+func goAvailable() bool {
+	_, err := exec.LookPath("go")
+	return err == nil
+}
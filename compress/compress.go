@@ -0,0 +1,127 @@
+// Package compress provides a templ.Compressor that compresses the responses of a
+// templ.ComponentHandler with zstd, brotli or gzip.
+//
+// It's a separate package to templ, so that the compression libraries are only linked into
+// programs that use it.
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/a-h/templ"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encodings are the supported content encodings, in order of preference.
+var encodings = []string{"zstd", "br", "gzip"}
+
+// Compressor compresses responses with zstd, brotli or gzip.
+type Compressor struct{}
+
+// New returns a Compressor, for use with templ.WithCompression.
+//
+//	templ.Handler(c, templ.WithCompression(compress.New()))
+func New() *Compressor {
+	return &Compressor{}
+}
+
+var _ templ.Compressor = (*Compressor)(nil)
+
+// Encoding returns the content encoding to use for a request with the Accept-Encoding header,
+// or an empty string if the client doesn't accept zstd, brotli or gzip. Encodings with a higher
+// quality value are preferred. If the quality values are equal, zstd is preferred, then brotli,
+// then gzip.
+func (c *Compressor) Encoding(acceptEncoding string) (encoding string) {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qualities := map[string]float64{}
+	for part := range strings.SplitSeq(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(k), "q") {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				parsed = 0
+			}
+			q = parsed
+		}
+		qualities[name] = q
+	}
+	var best float64
+	for _, candidate := range encodings {
+		q, ok := qualities[candidate]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > best {
+			encoding, best = candidate, q
+		}
+	}
+	return encoding
+}
+
+// encoder is implemented by the gzip, brotli and zstd writers.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var pools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		return gzip.NewWriter(nil)
+	}},
+	"br": {New: func() any {
+		// Level 5 compresses HTML better than gzip, while being fast enough for dynamic content.
+		return brotli.NewWriterLevel(nil, 5)
+	}},
+	"zstd": {New: func() any {
+		// Browsers don't support windows larger than 8MB.
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithWindowSize(8<<20))
+		return w
+	}},
+}
+
+// NewWriter returns a writer that compresses its output with the encoding, which must be one of
+// the encodings returned by Encoding, and writes it to w. The writer is reused once it's closed.
+func (c *Compressor) NewWriter(w io.Writer, encoding string) templ.CompressWriter {
+	e := pools[encoding].Get().(encoder)
+	e.Reset(w)
+	return &writer{encoding: encoding, e: e}
+}
+
+// writer returns the encoder to its pool when it's closed.
+type writer struct {
+	encoding string
+	e        encoder
+}
+
+func (w *writer) Write(p []byte) (n int, err error) {
+	return w.e.Write(p)
+}
+
+func (w *writer) Flush() error {
+	return w.e.Flush()
+}
+
+func (w *writer) Close() (err error) {
+	if w.e == nil {
+		return nil
+	}
+	err = w.e.Close()
+	w.e.Reset(nil)
+	pools[w.encoding].Put(w.e)
+	w.e = nil
+	return err
+}
@@ -0,0 +1,288 @@
+package compress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/compress"
+	"github.com/andybalholm/brotli"
+	"github.com/google/go-cmp/cmp"
+	"github.com/klauspost/compress/zstd"
+)
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	if len(body) == 0 {
+		return ""
+	}
+	var r io.Reader
+	switch encoding {
+	case "":
+		return string(body)
+	case "gzip":
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to create gzip reader: %v", err)
+		}
+		r = gr
+	case "br":
+		r = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("failed to create zstd reader: %v", err)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		t.Fatalf("unexpected encoding %q", encoding)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("failed to decompress %s: %v", encoding, err)
+	}
+	return string(b)
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(gw, s); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("failed to compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestHandler(t *testing.T) {
+	large := strings.Repeat("<p>Hello</p>", 100)
+	largeComponent := templ.Raw(large)
+	// The ETag of the uncompressed body.
+	etagRecorder := httptest.NewRecorder()
+	templ.Handler(largeComponent, templ.WithETag()).ServeHTTP(etagRecorder, httptest.NewRequest(http.MethodGet, "/test", nil))
+	etag := etagRecorder.Header().Get("ETag")
+	gzipETag := strings.TrimSuffix(etag, `"`) + `-gzip"`
+
+	tests := []struct {
+		name             string
+		input            *templ.ComponentHandler
+		method           string
+		acceptEncoding   string
+		headers          map[string]string
+		expectedStatus   int
+		expectedEncoding string
+		expectedHeaders  map[string]string
+		expectedBody     string
+	}{
+		{
+			name:           "responses are not compressed by default",
+			input:          templ.Handler(largeComponent),
+			acceptEncoding: "gzip",
+			expectedHeaders: map[string]string{
+				"Vary": "",
+			},
+			expectedBody: large,
+		},
+		{
+			name:             "gzip is used if it's the only accepted encoding",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New())),
+			acceptEncoding:   "gzip",
+			expectedEncoding: "gzip",
+			expectedHeaders: map[string]string{
+				"Vary":         "Accept-Encoding",
+				"Content-Type": "text/html; charset=utf-8",
+			},
+			expectedBody: large,
+		},
+		{
+			name:             "zstd is preferred when quality values are equal",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New())),
+			acceptEncoding:   "gzip, deflate, br, zstd",
+			expectedEncoding: "zstd",
+			expectedBody:     large,
+		},
+		{
+			name:             "brotli is preferred to gzip",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New())),
+			acceptEncoding:   "gzip, br",
+			expectedEncoding: "br",
+			expectedBody:     large,
+		},
+		{
+			name:             "quality values are respected",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New())),
+			acceptEncoding:   "zstd;q=0.5, br;q=0.8, gzip",
+			expectedEncoding: "gzip",
+			expectedBody:     large,
+		},
+		{
+			name:             "encodings with a quality of zero are not used",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New())),
+			acceptEncoding:   "*, zstd;q=0",
+			expectedEncoding: "br",
+			expectedBody:     large,
+		},
+		{
+			name:           "responses are not compressed if no encoding is acceptable",
+			input:          templ.Handler(largeComponent, templ.WithCompression(compress.New())),
+			acceptEncoding: "deflate, identity",
+			expectedHeaders: map[string]string{
+				"Vary": "Accept-Encoding",
+			},
+			expectedBody: large,
+		},
+		{
+			name:           "responses below the threshold are not compressed",
+			input:          templ.Handler(templ.Raw("Hello"), templ.WithCompression(compress.New())),
+			acceptEncoding: "gzip",
+			expectedHeaders: map[string]string{
+				"Vary": "Accept-Encoding",
+			},
+			expectedBody: "Hello",
+		},
+		{
+			name:             "the threshold can be changed",
+			input:            templ.Handler(templ.Raw("Hello"), templ.WithCompression(compress.New()), templ.WithCompressionThreshold(5)),
+			acceptEncoding:   "gzip",
+			expectedEncoding: "gzip",
+			expectedBody:     "Hello",
+		},
+		{
+			name:             "strong ETags include the encoding",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New()), templ.WithETag()),
+			acceptEncoding:   "gzip",
+			expectedEncoding: "gzip",
+			expectedHeaders: map[string]string{
+				"ETag": gzipETag,
+			},
+			expectedBody: large,
+		},
+		{
+			name:           "ETags that include the encoding match If-None-Match",
+			input:          templ.Handler(largeComponent, templ.WithCompression(compress.New()), templ.WithETag()),
+			acceptEncoding: "gzip",
+			headers:        map[string]string{"If-None-Match": gzipETag},
+			expectedStatus: http.StatusNotModified,
+			expectedHeaders: map[string]string{
+				"Vary": "Accept-Encoding",
+			},
+		},
+		{
+			name:             "HEAD requests have the Content-Length of the compressed body",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New())),
+			method:           http.MethodHead,
+			acceptEncoding:   "gzip",
+			expectedEncoding: "gzip",
+			expectedHeaders: map[string]string{
+				"Content-Length": strconv.Itoa(len(gzipped(t, large))),
+			},
+		},
+		{
+			name:             "weak ETags don't include the encoding",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New()), templ.WithWeakETag()),
+			acceptEncoding:   "gzip",
+			expectedEncoding: "gzip",
+			expectedHeaders: map[string]string{
+				"ETag": "W/" + etag,
+			},
+			expectedBody: large,
+		},
+		{
+			name:             "streamed responses are compressed",
+			input:            templ.Handler(largeComponent, templ.WithCompression(compress.New()), templ.WithStreaming()),
+			acceptEncoding:   "br",
+			expectedEncoding: "br",
+			expectedHeaders: map[string]string{
+				"Vary": "Accept-Encoding",
+			},
+			expectedBody: large,
+		},
+		{
+			name:           "streamed responses below the threshold are not compressed",
+			input:          templ.Handler(templ.Raw("Hello"), templ.WithCompression(compress.New()), templ.WithStreaming(), templ.WithStatus(http.StatusAccepted)),
+			acceptEncoding: "br",
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   "Hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			expectedStatus := tt.expectedStatus
+			if expectedStatus == 0 {
+				expectedStatus = http.StatusOK
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(method, "/test", nil)
+			r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			tt.input.ServeHTTP(w, r)
+			if actual := w.Result().StatusCode; actual != expectedStatus {
+				t.Errorf("expected status %d, got %d", expectedStatus, actual)
+			}
+			if actual := w.Result().Header.Get("Content-Encoding"); actual != tt.expectedEncoding {
+				t.Errorf("expected Content-Encoding %q, got %q", tt.expectedEncoding, actual)
+			}
+			for k, expected := range tt.expectedHeaders {
+				if actual := w.Result().Header.Get(k); actual != expected {
+					t.Errorf("expected %s header %q, got %q", k, expected, actual)
+				}
+			}
+			if diff := cmp.Diff(tt.expectedBody, decompress(t, tt.expectedEncoding, w.Body.Bytes())); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestHandlerFlush(t *testing.T) {
+	w := httptest.NewRecorder()
+	var flushed string
+	component := templ.ComponentFunc(func(ctx context.Context, tw io.Writer) error {
+		if _, err := io.WriteString(tw, "<p>Loading</p>"); err != nil {
+			return err
+		}
+		if err := templ.Flush().Render(ctx, tw); err != nil {
+			return err
+		}
+		// The output written before the flush must reach the client, even though the
+		// response is below the threshold, and the compressed stream isn't finished.
+		flushed = decompress(t, "gzip", w.Body.Bytes())
+		_, err := io.WriteString(tw, "<p>Loaded</p>")
+		return err
+	})
+	h := templ.Handler(component, templ.WithCompression(compress.New()), templ.WithStreaming())
+	r := httptest.NewRequest(http.MethodGet, "/test", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	h.ServeHTTP(w, r)
+
+	if !w.Flushed {
+		t.Error("expected the response to be flushed")
+	}
+	if diff := cmp.Diff("<p>Loading</p>", flushed); diff != "" {
+		t.Error(diff)
+	}
+	if actual := w.Result().Header.Get("Content-Encoding"); actual != "gzip" {
+		t.Errorf("expected gzip encoding, got %q", actual)
+	}
+	if diff := cmp.Diff("<p>Loading</p><p>Loaded</p>", decompress(t, "gzip", w.Body.Bytes())); diff != "" {
+		t.Error(diff)
+	}
+}
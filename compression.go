@@ -0,0 +1,168 @@
+package templ
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// DefaultCompressionThreshold is the minimum size of a response, in bytes, that the
+// ComponentHandler compresses. Smaller responses aren't worth compressing, because the
+// compressed output can be larger than the input.
+const DefaultCompressionThreshold = 1024
+
+// Compressor compresses the responses of a ComponentHandler. The
+// github.com/a-h/templ/compress package provides a Compressor that supports zstd, brotli and gzip.
+type Compressor interface {
+	// Encoding returns the content encoding to use for a request with the Accept-Encoding
+	// header, or an empty string if the client doesn't accept a supported encoding.
+	Encoding(acceptEncoding string) string
+	// NewWriter returns a writer that compresses the output written to it with the encoding,
+	// and writes it to w.
+	NewWriter(w io.Writer, encoding string) CompressWriter
+}
+
+// CompressWriter compresses the output written to it.
+type CompressWriter interface {
+	io.Writer
+	// Flush writes the output compressed so far.
+	Flush() error
+	// Close finishes the compressed stream. The writer can't be used after it's closed.
+	Close() error
+}
+
+// compress returns the body compressed with the encoding.
+func compress(c Compressor, encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	cw := c.NewWriter(&buf, encoding)
+	if _, err := cw.Write(body); err != nil {
+		_ = cw.Close()
+		return nil, err
+	}
+	if err := cw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressResponseWriter compresses a streamed response. Output is held back until it reaches
+// the threshold, or is flushed by the Flush component, so that small responses can be sent
+// uncompressed.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	compressor Compressor
+	encoding   string
+	threshold  int
+	fs         *flushState
+	status     int
+	pending    []byte
+	started    bool
+	c          CompressWriter
+}
+
+func newCompressResponseWriter(w http.ResponseWriter, compressor Compressor, encoding string, threshold int, fs *flushState) *compressResponseWriter {
+	return &compressResponseWriter{
+		ResponseWriter: w,
+		compressor:     compressor,
+		encoding:       encoding,
+		threshold:      threshold,
+		fs:             fs,
+	}
+}
+
+// WriteHeader holds back the status until the response starts, because the Content-Encoding
+// header isn't known until then.
+func (cw *compressResponseWriter) WriteHeader(status int) {
+	if cw.started {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressResponseWriter) Write(p []byte) (n int, err error) {
+	if cw.started {
+		if cw.c != nil {
+			return cw.c.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+	cw.pending = append(cw.pending, p...)
+	if len(cw.pending) >= cw.threshold {
+		if err = cw.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// start writes the headers, and the output held back so far.
+func (cw *compressResponseWriter) start(compressed bool) (err error) {
+	cw.started = true
+	if compressed {
+		h := cw.ResponseWriter.Header()
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		cw.c = cw.compressor.NewWriter(cw.ResponseWriter, cw.encoding)
+	}
+	if cw.status != 0 {
+		cw.ResponseWriter.WriteHeader(cw.status)
+	}
+	pending := cw.pending
+	cw.pending = nil
+	if len(pending) == 0 {
+		return nil
+	}
+	if cw.c != nil {
+		_, err = cw.c.Write(pending)
+		return err
+	}
+	_, err = cw.ResponseWriter.Write(pending)
+	return err
+}
+
+// Flush sends the output written so far to the client. Generated code flushes the output when
+// a component has been rendered, so output that's held back is only sent when the Flush
+// component is used. Once flushed, the rest of the response is compressed, because the final
+// size isn't known.
+func (cw *compressResponseWriter) Flush() {
+	if !cw.started {
+		if !cw.fs.explicit {
+			return
+		}
+		if err := cw.start(true); err != nil {
+			return
+		}
+	}
+	if cw.c != nil {
+		if err := cw.c.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes any output held back, and finishes the compressed stream.
+func (cw *compressResponseWriter) Close() (err error) {
+	if !cw.started {
+		// The response didn't reach the threshold, so it's sent uncompressed.
+		if err = cw.start(false); err != nil {
+			return err
+		}
+	}
+	if cw.c == nil {
+		return nil
+	}
+	err = cw.c.Close()
+	cw.c = nil
+	return err
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by http.ResponseController.
+func (cw *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
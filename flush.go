@@ -25,6 +25,10 @@ func (f FlushComponent) Render(ctx context.Context, w io.Writer) (err error) {
 	if err = GetChildren(ctx).Render(ctx, w); err != nil {
 		return err
 	}
+	if fs := getFlushState(ctx); fs != nil {
+		fs.explicit = true
+		defer func() { fs.explicit = false }()
+	}
 	switch w := w.(type) {
 	case flusher:
 		w.Flush()
@@ -34,3 +38,30 @@ func (f FlushComponent) Render(ctx context.Context, w io.Writer) (err error) {
 	}
 	return nil
 }
+
+type flushContextKeyType int
+
+const flushContextKey flushContextKeyType = iota
+
+// flushState records whether a flush was requested by the Flush component. Generated code also
+// flushes the output when a component has been rendered, which writers that hold back output can
+// ignore.
+type flushState struct {
+	// explicit is true while the Flush component is flushing the output.
+	explicit bool
+}
+
+// withFlushState returns a context that records flushes requested by the Flush component, and
+// the flush state. If the context already has a flush state, it's reused.
+func withFlushState(ctx context.Context) (context.Context, *flushState) {
+	if fs := getFlushState(ctx); fs != nil {
+		return ctx, fs
+	}
+	fs := &flushState{}
+	return context.WithValue(ctx, flushContextKey, fs), fs
+}
+
+func getFlushState(ctx context.Context) *flushState {
+	fs, _ := ctx.Value(flushContextKey).(*flushState)
+	return fs
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 
 	_ "embed"
 
+	"github.com/a-h/templ"
 	"github.com/a-h/templ/parser/v2"
 )
 
@@ -59,10 +61,33 @@ func WithSkipCodeGeneratedComment() GenerateOpt {
 	}
 }
 
+// WithCSSBundle writes the CSS of constant css templates to GeneratorOutput.CSSBundle, instead
+// of the Go code. The generated code references the class by name, so the bundled stylesheet
+// must be served. css templates that use Go expressions are still rendered inline.
+func WithCSSBundle() GenerateOpt {
+	return func(g *generator) error {
+		g.options.CSSBundle = true
+		return nil
+	}
+}
+
 type GeneratorOutput struct {
 	Options   GeneratorOptions  `json:"meta"`
 	SourceMap *parser.SourceMap `json:"sourceMap"`
 	Literals  []string          `json:"literals"`
+	// Messages are the translatable messages, which are part of the Go code.
+	Messages []parser.Message `json:"messages"`
+	// CSSBundle is the CSS of the constant css templates, if the WithCSSBundle option is used.
+	CSSBundle []CSSBundleClass `json:"cssBundle"`
+}
+
+// CSSBundleClass is a constant css template, which is written to a stylesheet instead of being
+// rendered inline.
+type CSSBundleClass struct {
+	// ID is the name of the class, e.g. "red_f2c5a4b1".
+	ID string `json:"id"`
+	// Class is the CSS of the class, e.g. ".red_f2c5a4b1{color:red;}".
+	Class string `json:"class"`
 }
 
 type GeneratorOptions struct {
@@ -74,6 +99,8 @@ type GeneratorOptions struct {
 	SkipCodeGeneratedComment bool
 	// GeneratedDate to include as a comment.
 	GeneratedDate string
+	// CSSBundle writes constant css templates to the output, instead of the Go code.
+	CSSBundle bool
 }
 
 // HasGoChanged returns true if the Go code has changed between the previous and updated GeneratorOutput.
@@ -88,11 +115,25 @@ func HasGoChanged(previous, updated GeneratorOutput) bool {
 	if previous.Options.SkipCodeGeneratedComment != updated.Options.SkipCodeGeneratedComment {
 		return true
 	}
+	// The IDs of bundled classes are part of the Go code.
+	if !slices.Equal(previous.CSSBundle, updated.CSSBundle) {
+		return true
+	}
 	// We don't check the generated date as it's not used for determining if the file has changed.
 	// If the number of literals has changed, we need to recompile.
 	if len(previous.Literals) != len(updated.Literals) {
 		return true
 	}
+	// Translatable messages are written to the Go code, not the literals.
+	if len(previous.Messages) != len(updated.Messages) {
+		return true
+	}
+	for i, prev := range previous.Messages {
+		updated := updated.Messages[i]
+		if prev.ID != updated.ID || prev.Plural != updated.Plural || prev.Context != updated.Context {
+			return true
+		}
+	}
 	// If the Go code has changed, we need to recompile.
 	if len(previous.SourceMap.Expressions) != len(updated.SourceMap.Expressions) {
 		return true
@@ -138,6 +179,8 @@ func Generate(template *parser.TemplateFile, w io.Writer, opts ...GenerateOpt) (
 	op.Options = g.options
 	op.SourceMap = g.sourceMap
 	op.Literals = g.w.Literals
+	op.Messages = g.messages
+	op.CSSBundle = g.cssBundle
 	return op, nil
 }
 
@@ -147,6 +190,13 @@ type generator struct {
 	sourceMap   *parser.SourceMap
 	variableID  int
 	childrenVar string
+	// translation is the translation state of the element being written.
+	translation parser.Translation
+	messages    []parser.Message
+	// cssBundle is the CSS of constant css templates, if options.CSSBundle is set.
+	cssBundle []CSSBundleClass
+	// scope is the attribute added to elements, if the template has <style scoped> elements.
+	scope string
 
 	options GeneratorOptions
 }
@@ -295,34 +345,22 @@ func (g *generator) writeCSS(n *parser.CSSTemplate) error {
 	if _, err = g.w.Write(" templ.CSSClass {\n"); err != nil {
 		return err
 	}
-	{
+	if css, ok := constantCSS(n); ok && g.options.CSSBundle {
+		// The CSS is in the bundle, so only the class name is needed.
+		id := templ.CSSID(n.Name, css)
+		g.cssBundle = append(g.cssBundle, CSSBundleClass{ID: id, Class: "." + id + "{" + css + "}"})
+		// return templ.ConstantCSSClass(`name_1234`)
+		if _, err = g.w.WriteIndent(indentLevel+1, "return templ.ConstantCSSClass("+createGoString(id)+")\n"); err != nil {
+			return err
+		}
+	} else {
 		indentLevel++
 		// templ_7745c5c3_CSSBuilder := templruntim.GetBuilder()
 		if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSBuilder := templruntime.GetBuilder()\n"); err != nil {
 			return err
 		}
-		for _, p := range n.Properties {
-			switch p := p.(type) {
-			case *parser.ConstantCSSProperty:
-				// Constant CSS property values are not sanitized.
-				if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSBuilder.WriteString("+createGoString(p.String(true))+")\n"); err != nil {
-					return err
-				}
-			case *parser.ExpressionCSSProperty:
-				// templ_7745c5c3_CSSBuilder.WriteString(templ.SanitizeCSS('name', p.Expression()))
-				if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_CSSBuilder.WriteString(string(templ.SanitizeCSS(`%s`, ", p.Name)); err != nil {
-					return err
-				}
-				if r, err = g.w.Write(p.Value.Expression.Value); err != nil {
-					return err
-				}
-				g.sourceMap.Add(p.Value.Expression, r)
-				if _, err = g.w.Write(")))\n"); err != nil {
-					return err
-				}
-			default:
-				return fmt.Errorf("unknown CSS property type: %v", reflect.TypeOf(p))
-			}
+		if err = g.writeCSSProperties(indentLevel, n.Properties); err != nil {
+			return err
 		}
 		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_CSSID := templ.CSSID(`%s`, templ_7745c5c3_CSSBuilder.String())\n", n.Name)); err != nil {
 			return err
@@ -360,6 +398,74 @@ func (g *generator) writeCSS(n *parser.CSSTemplate) error {
 	return nil
 }
 
+// writeCSSProperties writes the properties to the CSS builder. Nested rules are written using CSS
+// nesting, within the rule of the class.
+func (g *generator) writeCSSProperties(indentLevel int, properties []parser.CSSProperty) (err error) {
+	var r parser.Range
+	for _, p := range properties {
+		switch p := p.(type) {
+		case *parser.ConstantCSSProperty:
+			// Constant CSS property values are not sanitized.
+			if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSBuilder.WriteString("+createGoString(p.String(true))+")\n"); err != nil {
+				return err
+			}
+		case *parser.ExpressionCSSProperty:
+			// templ_7745c5c3_CSSBuilder.WriteString(templ.SanitizeCSS('name', p.Expression()))
+			if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_CSSBuilder.WriteString(string(templ.SanitizeCSS(`%s`, ", p.Name)); err != nil {
+				return err
+			}
+			if r, err = g.w.Write(p.Value.Expression.Value); err != nil {
+				return err
+			}
+			g.sourceMap.Add(p.Value.Expression, r)
+			if _, err = g.w.Write(")))\n"); err != nil {
+				return err
+			}
+		case *parser.CSSRule:
+			// templ_7745c5c3_CSSBuilder.WriteString(`&:hover{`)
+			if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSBuilder.WriteString("+createGoString(p.Prelude+"{")+")\n"); err != nil {
+				return err
+			}
+			if err = g.writeCSSProperties(indentLevel, p.Properties); err != nil {
+				return err
+			}
+			if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSBuilder.WriteString(`}`)\n"); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown CSS property type: %v", reflect.TypeOf(p))
+		}
+	}
+	return nil
+}
+
+// constantCSS returns the CSS of the template, if none of its properties are Go expressions.
+func constantCSS(n *parser.CSSTemplate) (css string, ok bool) {
+	var sb strings.Builder
+	if !writeConstantCSS(&sb, n.Properties) {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+func writeConstantCSS(sb *strings.Builder, properties []parser.CSSProperty) (ok bool) {
+	for _, p := range properties {
+		switch p := p.(type) {
+		case *parser.ConstantCSSProperty:
+			sb.WriteString(p.String(true))
+		case *parser.CSSRule:
+			sb.WriteString(p.Prelude + "{")
+			if !writeConstantCSS(sb, p.Properties) {
+				return false
+			}
+			sb.WriteString("}")
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 func (g *generator) writeGoExpression(n *parser.TemplateFileGoExpression) (err error) {
 	if n == nil {
 		return errors.New("go expression is nil")
@@ -523,9 +629,15 @@ func (g *generator) writeTemplate(nodeIdx int, t *parser.HTMLTemplate) error {
 			return err
 		}
 		// Nodes.
+		g.translation = parser.Translation{Translatable: t.Translatable}
+		if css := scopedStyles(t); len(css) > 0 {
+			g.scope = scopeAttribute(t, css)
+		}
 		if err = g.writeNodes(indentLevel, stripWhitespace(t.Children), nil); err != nil {
 			return err
 		}
+		g.translation = parser.Translation{}
+		g.scope = ""
 		// return nil
 		if _, err = g.w.WriteIndent(indentLevel, "return nil\n"); err != nil {
 			return err
@@ -590,7 +702,28 @@ func stripLeadingAndTrailingWhitespace(nodes []parser.Node) []parser.Node {
 }
 
 func (g *generator) writeNodes(indentLevel int, nodes []parser.Node, next parser.Node) error {
-	for i, curr := range nodes {
+	var runs map[int][]parser.Node
+	if g.translation.Translatable {
+		runs = parser.TextRuns(nodes)
+	}
+	for i := 0; i < len(nodes); i++ {
+		// Runs of text and string expressions are translated as a single message.
+		if msg, ok := g.translation.TextMessage(runs[i]); ok {
+			last := i + len(runs[i]) - 1
+			var nextNode parser.Node
+			if last+1 < len(nodes) {
+				nextNode = nodes[last+1]
+			}
+			if nextNode == nil {
+				nextNode = next
+			}
+			if err := g.writeTranslatedText(indentLevel, msg, nodes[last], nextNode); err != nil {
+				return err
+			}
+			i = last
+			continue
+		}
+		curr := nodes[i]
 		var nextNode parser.Node
 		if i+1 < len(nodes) {
 			nextNode = nodes[i+1]
@@ -1014,13 +1147,23 @@ func (g *generator) writeExpressionErrorHandler(indentLevel int, expression pars
 }
 
 func (g *generator) writeElement(indentLevel int, n *parser.Element) (err error) {
+	parentTranslation := g.translation
+	g.translation = parentTranslation.Element(n)
+	defer func() {
+		g.translation = parentTranslation
+	}()
 	if len(n.Attributes) == 0 {
 		// <div>
-		if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf(`<%s>`, html.EscapeString(n.Name))); err != nil {
+		if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf(`<%s%s>`, html.EscapeString(n.Name), g.scopeAttribute())); err != nil {
 			return err
 		}
 	} else {
-		attrs := parser.CopyAttributes(n.Attributes)
+		attrs := n.Attributes
+		if g.translation.Translatable {
+			// The attributes that mark the element as translatable are not rendered.
+			attrs = slices.DeleteFunc(slices.Clone(attrs), parser.IsTranslationAttribute)
+		}
+		attrs = parser.CopyAttributes(attrs)
 		// <style type="text/css"></style>
 		if err = g.writeElementCSS(indentLevel, attrs); err != nil {
 			return err
@@ -1037,7 +1180,7 @@ func (g *generator) writeElement(indentLevel int, n *parser.Element) (err error)
 			return err
 		}
 		// >
-		if _, err = g.w.WriteStringLiteral(indentLevel, `>`); err != nil {
+		if _, err = g.w.WriteStringLiteral(indentLevel, g.scopeAttribute()+`>`); err != nil {
 			return err
 		}
 	}
@@ -1494,6 +1637,10 @@ func (g *generator) writeElementAttributes(indentLevel int, name string, attrs [
 		case *parser.BoolConstantAttribute:
 			err = g.writeBoolConstantAttribute(indentLevel, attr)
 		case *parser.ConstantAttribute:
+			if msg, ok := g.translation.AttributeMessage(attr); ok && g.translation.Translatable {
+				err = g.writeTranslatedAttribute(indentLevel, attr, msg)
+				continue
+			}
 			err = g.writeConstantAttribute(indentLevel, attr)
 		case *parser.BoolExpressionAttribute:
 			err = g.writeBoolExpressionAttribute(indentLevel, attr)
@@ -1512,7 +1659,19 @@ func (g *generator) writeElementAttributes(indentLevel int, name string, attrs [
 	return
 }
 
+// scopeAttribute returns the attribute matched by the selectors of <style scoped> elements, with
+// a leading space, or an empty string if the template doesn't have scoped styles.
+func (g *generator) scopeAttribute() string {
+	if g.scope == "" {
+		return ""
+	}
+	return " " + g.scope
+}
+
 func (g *generator) writeRawElement(indentLevel int, n *parser.RawElement) (err error) {
+	if g.scope != "" && isScopedStyle(n) {
+		return g.writeScopedStyle(indentLevel, n)
+	}
 	if len(n.Attributes) == 0 {
 		// <div>
 		if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf(`<%s>`, html.EscapeString(n.Name))); err != nil {
@@ -1546,6 +1705,20 @@ func (g *generator) writeRawElement(indentLevel int, n *parser.RawElement) (err
 	return err
 }
 
+// writeScopedStyle renders the CSS of a <style scoped> element, if it hasn't already been rendered.
+func (g *generator) writeScopedStyle(indentLevel int, n *parser.RawElement) (err error) {
+	css, err := scopeCSS(n.Contents, g.scope)
+	if err != nil {
+		return fmt.Errorf("<style scoped> at line %d: %w", n.Range.From.Line+1, err)
+	}
+	// templ_7745c5c3_Err = templ.RenderCSSItems(ctx, templ_7745c5c3_Buffer, templ.ComponentCSSClass{ID: `data-templ-3f2a1b4c_1a2b3c4d`, Class: templ.SafeCSS(`...`)})
+	class := "templ.ComponentCSSClass{ID: " + createGoString(templ.CSSID(g.scope, css)) + ", Class: templ.SafeCSS(" + createGoString(css) + ")}"
+	if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_Err = templ.RenderCSSItems(ctx, templ_7745c5c3_Buffer, "+class+")\n"); err != nil {
+		return err
+	}
+	return g.writeErrorHandler(indentLevel)
+}
+
 func (g *generator) writeScriptElement(indentLevel int, n *parser.ScriptElement) (err error) {
 	if len(n.Attributes) == 0 {
 		// <div>
@@ -1729,6 +1902,119 @@ func (g *generator) writeText(indentLevel int, n *parser.Text) (err error) {
 	return err
 }
 
+// writeTranslatedText writes a run of text and string expressions as a single translated message.
+func (g *generator) writeTranslatedText(indentLevel int, msg parser.Message, last, next parser.Node) (err error) {
+	if msg.LeadingSpace {
+		if _, err = g.w.WriteStringLiteral(indentLevel, " "); err != nil {
+			return err
+		}
+	}
+	if err = g.writeTranslation(indentLevel, msg); err != nil {
+		return err
+	}
+	if msg.TrailingSpace {
+		if _, err = g.w.WriteStringLiteral(indentLevel, " "); err != nil {
+			return err
+		}
+	}
+	// Write trailing whitespace, if there is a next node that might need the space.
+	if ws, ok := last.(parser.WhitespaceTrailer); ok && isTrailingSpaceNeeded(last, next) {
+		if err := g.writeWhitespaceTrailer(indentLevel, ws.Trailing()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTranslatedAttribute writes a constant attribute with a translated value.
+func (g *generator) writeTranslatedAttribute(indentLevel int, attr *parser.ConstantAttribute, msg parser.Message) (err error) {
+	if err = g.writeAttributeKey(indentLevel, attr.Key); err != nil {
+		return err
+	}
+	if _, err = g.w.WriteStringLiteral(indentLevel, `=\"`); err != nil {
+		return err
+	}
+	if err = g.writeTranslation(indentLevel, msg); err != nil {
+		return err
+	}
+	if _, err = g.w.WriteStringLiteral(indentLevel, `\"`); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeTranslation writes code that looks up the translation of the message at runtime.
+func (g *generator) writeTranslation(indentLevel int, msg parser.Message) (err error) {
+	g.messages = append(g.messages, msg)
+	var r parser.Range
+	args := make([]string, len(msg.Args))
+	for i, arg := range msg.Args {
+		args[i] = g.createVariableName()
+		// var vn string
+		if _, err = g.w.WriteIndent(indentLevel, "var "+args[i]+" string\n"); err != nil {
+			return err
+		}
+		// vn, templ_7745c5c3_Err = templ.JoinStringErrs(
+		if _, err = g.w.WriteIndent(indentLevel, args[i]+", templ_7745c5c3_Err = templ.JoinStringErrs("); err != nil {
+			return err
+		}
+		// name
+		if r, err = g.w.Write(arg.Value); err != nil {
+			return err
+		}
+		g.sourceMap.Add(arg, r)
+		// )
+		if _, err = g.w.Write(")\n"); err != nil {
+			return err
+		}
+		if err = g.writeExpressionErrorHandler(indentLevel, arg); err != nil {
+			return err
+		}
+	}
+	// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templruntime.Translation(ctx, templ.Message{ID: "Hello, { name }"}, 0, "name", vn))
+	if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templruntime.Translation(ctx, templ.Message{ID: "+strconv.Quote(msg.ID)); err != nil {
+		return err
+	}
+	if msg.Plural != "" {
+		if _, err = g.w.Write(", Plural: " + strconv.Quote(msg.Plural)); err != nil {
+			return err
+		}
+	}
+	if msg.Context != "" {
+		if _, err = g.w.Write(", Context: " + strconv.Quote(msg.Context)); err != nil {
+			return err
+		}
+	}
+	if _, err = g.w.Write("}, "); err != nil {
+		return err
+	}
+	if msg.Count != nil {
+		if _, err = g.w.Write("int("); err != nil {
+			return err
+		}
+		if r, err = g.w.Write(msg.Count.Value); err != nil {
+			return err
+		}
+		g.sourceMap.Add(*msg.Count, r)
+		if _, err = g.w.Write(")"); err != nil {
+			return err
+		}
+	} else {
+		if _, err = g.w.Write("0"); err != nil {
+			return err
+		}
+	}
+	for i, arg := range msg.Args {
+		if _, err = g.w.Write(", " + strconv.Quote(strings.TrimSpace(arg.Value)) + ", " + args[i]); err != nil {
+			return err
+		}
+	}
+	if _, err = g.w.Write("))\n"); err != nil {
+		return err
+	}
+	return g.writeErrorHandler(indentLevel)
+}
+
 func createGoString(s string) string {
 	var sb strings.Builder
 	sb.WriteRune('`')
@@ -2,8 +2,10 @@ package generator
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
+	"github.com/a-h/templ"
 	"github.com/a-h/templ/parser/v2"
 	"github.com/google/go-cmp/cmp"
 )
@@ -244,3 +246,57 @@ func TestIsExpressionAttributeValueURL(t *testing.T) {
 		}
 	}
 }
+
+func TestGeneratorCSSBundle(t *testing.T) {
+	input := `package main
+
+css red() {
+	color: red;
+	font-weight: bold;
+}
+
+css colour(c string) {
+	color: { c };
+}
+`
+	tf, err := parser.ParseString(input)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	t.Run("constant css is written to the bundle", func(t *testing.T) {
+		w := new(bytes.Buffer)
+		op, err := Generate(tf, w, WithCSSBundle())
+		if err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		css := "color:red;font-weight:bold;"
+		id := templ.CSSID("red", css)
+		expected := []CSSBundleClass{{ID: id, Class: "." + id + "{" + css + "}"}}
+		if diff := cmp.Diff(expected, op.CSSBundle); diff != "" {
+			t.Error(diff)
+		}
+		if !strings.Contains(w.String(), "return templ.ConstantCSSClass(`"+id+"`)") {
+			t.Errorf("expected the class to be referenced by name, got:\n%s", w.String())
+		}
+		if strings.Contains(w.String(), "color:red;") {
+			t.Errorf("expected the bundled CSS not to be in the Go code, got:\n%s", w.String())
+		}
+		if !strings.Contains(w.String(), "templ.SanitizeCSS(`color`, c)") {
+			t.Errorf("expected css with expressions to be rendered inline, got:\n%s", w.String())
+		}
+	})
+	t.Run("without the option, css is rendered inline", func(t *testing.T) {
+		w := new(bytes.Buffer)
+		op, err := Generate(tf, w)
+		if err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		if len(op.CSSBundle) != 0 {
+			t.Errorf("expected no bundled CSS, got %#v", op.CSSBundle)
+		}
+		if strings.Contains(w.String(), "templ.ConstantCSSClass") {
+			t.Errorf("expected the CSS to be rendered inline, got:\n%s", w.String())
+		}
+	})
+}
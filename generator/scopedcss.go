@@ -0,0 +1,362 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/a-h/templ/parser/v2"
+)
+
+// isScopedStyle returns true if the element is a <style scoped> element.
+func isScopedStyle(n *parser.RawElement) bool {
+	if n.Name != "style" {
+		return false
+	}
+	for _, attr := range n.Attributes {
+		if attr, ok := attr.(*parser.BoolConstantAttribute); ok && attr.Key.String() == "scoped" {
+			return true
+		}
+	}
+	return false
+}
+
+// scopedStyles returns the contents of the <style scoped> elements in the template.
+func scopedStyles(t *parser.HTMLTemplate) (css []string) {
+	var walk func(nodes []parser.Node)
+	walk = func(nodes []parser.Node) {
+		for _, n := range nodes {
+			switch n := n.(type) {
+			case *parser.RawElement:
+				if isScopedStyle(n) {
+					css = append(css, n.Contents)
+				}
+			case parser.CompositeNode:
+				walk(n.ChildNodes())
+			}
+		}
+	}
+	walk(t.Children)
+	return css
+}
+
+// scopeAttribute returns the name of the attribute added to the elements of a template with
+// scoped styles, e.g. "data-templ-3f2a1b4c". It's a hash of the template and its CSS, so that the
+// styles of different templates don't affect each other.
+func scopeAttribute(t *parser.HTMLTemplate, css []string) string {
+	h := sha256.New()
+	h.Write([]byte(t.Expression.Value))
+	for _, c := range css {
+		h.Write([]byte{0})
+		h.Write([]byte(c))
+	}
+	return "data-templ-" + hex.EncodeToString(h.Sum(nil))[:8]
+}
+
+// scopeCSS rewrites the selectors of the style rules in the CSS, so that they only match
+// elements that have the attribute. The attribute is added to the last compound selector of
+// each selector, before any pseudo-element, e.g. ".a .b::before" becomes
+// ".a .b[data-templ-3f2a1b4c]::before", so that ancestors outside the template can still be
+// used, e.g. to apply a theme.
+//
+// The rules of grouping at-rules, such as @media, @supports and @container, are scoped, while
+// the contents of other at-rules, such as @keyframes and @font-face, are left unchanged.
+// Comments are removed, and whitespace is collapsed.
+func scopeCSS(css, attr string) (string, error) {
+	s := &cssScoper{s: css, attr: "[" + attr + "]"}
+	if err := s.rules(false, false); err != nil {
+		return "", err
+	}
+	return s.sb.String(), nil
+}
+
+type cssScoper struct {
+	s    string
+	i    int
+	attr string
+	sb   strings.Builder
+}
+
+// groupingAtRules contain rules, which are scoped.
+var groupingAtRules = []string{"@media", "@supports", "@container", "@layer", "@scope", "@starting-style", "@document"}
+
+// rules scopes the rules until the end of the input, or the end of the block.
+func (s *cssScoper) rules(inBlock, nested bool) error {
+	for {
+		prelude, end := s.next()
+		switch end {
+		case 0, '}':
+			if prelude != "" {
+				if !nested {
+					return errors.New("scoped style: expected { after " + prelude)
+				}
+				// The last declaration in a block doesn't need a semicolon.
+				s.sb.WriteString(prelude + ";")
+			}
+			if end == 0 && inBlock {
+				return errors.New("scoped style: expected } at the end of the block")
+			}
+			if end == '}' && !inBlock {
+				return errors.New("scoped style: unexpected }")
+			}
+			return nil
+		case ';':
+			if prelude != "" {
+				// A declaration, or a statement at-rule, e.g. @import.
+				s.sb.WriteString(prelude + ";")
+			}
+		case '{':
+			if !strings.HasPrefix(prelude, "@") {
+				s.sb.WriteString(s.scopeSelectorList(prelude) + "{")
+				if err := s.rules(true, true); err != nil {
+					return err
+				}
+				s.sb.WriteString("}")
+				continue
+			}
+			name, _, _ := strings.Cut(prelude, " ")
+			name = strings.ToLower(name)
+			if strings.HasPrefix(name, "@-") || !isGroupingAtRule(name) {
+				// e.g. @keyframes, @font-face, which contain no selectors.
+				contents, err := s.block()
+				if err != nil {
+					return err
+				}
+				s.sb.WriteString(prelude + "{" + contents + "}")
+				continue
+			}
+			s.sb.WriteString(prelude + "{")
+			if err := s.rules(true, nested); err != nil {
+				return err
+			}
+			s.sb.WriteString("}")
+		}
+	}
+}
+
+func isGroupingAtRule(name string) bool {
+	for _, r := range groupingAtRules {
+		if name == r {
+			return true
+		}
+	}
+	return false
+}
+
+// next reads until a ;, { or } that isn't in a string, or brackets. The ; and { are consumed.
+// Comments are removed, and whitespace is collapsed.
+func (s *cssScoper) next() (text string, end byte) {
+	var sb strings.Builder
+	var depth int
+	var space bool
+	for s.i < len(s.s) {
+		c := s.s[s.i]
+		switch {
+		case c == '/' && strings.HasPrefix(s.s[s.i:], "/*"):
+			s.skipComment()
+			space = true
+			continue
+		case isCSSSpace(c):
+			space = true
+			s.i++
+			continue
+		}
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+		switch c {
+		case '"', '\'':
+			sb.WriteString(s.readString())
+			continue
+		case '\\':
+			sb.WriteString(s.s[s.i:min(s.i+2, len(s.s))])
+			s.i += 2
+			continue
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ';', '{', '}':
+			if depth <= 0 {
+				s.i++
+				return strings.TrimSpace(sb.String()), c
+			}
+		}
+		sb.WriteByte(c)
+		s.i++
+	}
+	return strings.TrimSpace(sb.String()), 0
+}
+
+// block reads the contents of a block, until the matching }, which is consumed.
+func (s *cssScoper) block() (string, error) {
+	var sb strings.Builder
+	depth := 1
+	for s.i < len(s.s) {
+		c := s.s[s.i]
+		switch c {
+		case '/':
+			if strings.HasPrefix(s.s[s.i:], "/*") {
+				s.skipComment()
+				continue
+			}
+		case '"', '\'':
+			sb.WriteString(s.readString())
+			continue
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				s.i++
+				return strings.TrimSpace(sb.String()), nil
+			}
+		}
+		sb.WriteByte(c)
+		s.i++
+	}
+	return "", errors.New("scoped style: expected } at the end of the block")
+}
+
+func (s *cssScoper) skipComment() {
+	end := strings.Index(s.s[s.i+2:], "*/")
+	if end < 0 {
+		s.i = len(s.s)
+		return
+	}
+	s.i += 2 + end + 2
+}
+
+// readString reads a quoted string, including the quotes.
+func (s *cssScoper) readString() string {
+	start := s.i
+	quote := s.s[s.i]
+	s.i++
+	for s.i < len(s.s) {
+		switch s.s[s.i] {
+		case '\\':
+			s.i += 2
+			continue
+		case quote:
+			s.i++
+			return s.s[start:s.i]
+		}
+		s.i++
+	}
+	s.i = len(s.s)
+	return s.s[start:]
+}
+
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// scopeSelectorList scopes each selector in a comma separated list.
+func (s *cssScoper) scopeSelectorList(list string) string {
+	selectors := splitTopLevel(list, ',')
+	for i, sel := range selectors {
+		selectors[i] = s.scopeSelector(strings.TrimSpace(sel))
+	}
+	return strings.Join(selectors, ",")
+}
+
+// scopeSelector adds the attribute to the last compound selector, before any pseudo-element.
+func (s *cssScoper) scopeSelector(sel string) string {
+	// Find the start of the last compound selector.
+	var start, depth int
+	var quote byte
+	for i := 0; i < len(sel); i++ {
+		c := sel[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && (c == ' ' || c == '>' || c == '+' || c == '~'):
+			start = i + 1
+		}
+	}
+	insertAt := len(sel)
+	if i := pseudoElementIndex(sel[start:]); i >= 0 {
+		insertAt = start + i
+	}
+	return sel[:insertAt] + s.attr + sel[insertAt:]
+}
+
+// legacyPseudoElements can be written with a single colon.
+var legacyPseudoElements = []string{":before", ":after", ":first-line", ":first-letter"}
+
+// pseudoElementIndex returns the index of the first pseudo-element in the compound selector,
+// or -1.
+func pseudoElementIndex(compound string) int {
+	var depth int
+	for i := 0; i < len(compound); i++ {
+		switch c := compound[i]; c {
+		case '\\':
+			i++
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ':':
+			if depth > 0 {
+				continue
+			}
+			if strings.HasPrefix(compound[i:], "::") {
+				return i
+			}
+			for _, pe := range legacyPseudoElements {
+				rest := compound[i:]
+				if len(rest) >= len(pe) && strings.EqualFold(rest[:len(pe)], pe) && (len(rest) == len(pe) || !isCSSNameChar(rest[len(pe)])) {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+func isCSSNameChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// splitTopLevel splits the string at the separator, unless it's in brackets, or a string.
+func splitTopLevel(s string, sep byte) (parts []string) {
+	var start, depth int
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScopeCSS(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "class selectors are scoped",
+			input:    `.a { color: red; }`,
+			expected: `.a[s]{color: red;}`,
+		},
+		{
+			name:     "each selector in a list is scoped",
+			input:    `.a, .b { color: red }`,
+			expected: `.a[s],.b[s]{color: red;}`,
+		},
+		{
+			name:     "the last compound selector is scoped",
+			input:    ".dark  .a > p + span ~ em,\n.x>.y { color: red; }",
+			expected: `.dark .a > p + span ~ em[s],.x>.y[s]{color: red;}`,
+		},
+		{
+			name:     "pseudo-classes are kept",
+			input:    `a:hover, li:not(.a, .b) { color: red; }`,
+			expected: `a:hover[s],li:not(.a, .b)[s]{color: red;}`,
+		},
+		{
+			name:     "the attribute is added before pseudo-elements",
+			input:    `.a::before, .b:after, p::first-line { content: ""; }`,
+			expected: `.a[s]::before,.b[s]:after,p[s]::first-line{content: "";}`,
+		},
+		{
+			name:     "spaces in attribute selectors aren't combinators",
+			input:    `[title="a b"] { color: red; }`,
+			expected: `[title="a b"][s]{color: red;}`,
+		},
+		{
+			name:     "strings can contain braces and semicolons",
+			input:    `.a { content: "{;}"; }`,
+			expected: `.a[s]{content: "{;}";}`,
+		},
+		{
+			name:     "nested rules are scoped",
+			input:    `.a { color: red; &:hover { color: blue; } .b { color: green; } }`,
+			expected: `.a[s]{color: red;&:hover[s]{color: blue;}.b[s]{color: green;}}`,
+		},
+		{
+			name:     "rules in media and container queries are scoped",
+			input:    `@media (max-width: 600px) { .a { color: red; } } @container card (min-width: 400px) { .b { color: blue; } }`,
+			expected: `@media (max-width: 600px){.a[s]{color: red;}}@container card (min-width: 400px){.b[s]{color: blue;}}`,
+		},
+		{
+			name:     "keyframes aren't scoped",
+			input:    `@keyframes spin { from { rotate: 0deg; } to { rotate: 360deg; } }`,
+			expected: `@keyframes spin{from { rotate: 0deg; } to { rotate: 360deg; }}`,
+		},
+		{
+			name:     "statement at-rules are kept",
+			input:    `@import url("a.css"); .a { color: red; }`,
+			expected: `@import url("a.css");.a[s]{color: red;}`,
+		},
+		{
+			name:     "comments are removed",
+			input:    "/* Title. */\n.a /* comment */ { color: /* red */ red; }",
+			expected: `.a[s]{color: red;}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := scopeCSS(tt.input, "s")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestScopeCSSErrors(t *testing.T) {
+	for _, input := range []string{
+		`.a { color: red;`,
+		`.a { color: red; } }`,
+		`.a`,
+	} {
+		if _, err := scopeCSS(input, "s"); err == nil {
+			t.Errorf("expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package testcompression
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/compress"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test(t *testing.T) {
+	tests := []struct {
+		name             string
+		component        templ.Component
+		expectedEncoding string
+		expectedFlushed  bool
+		expected         string
+	}{
+		{
+			name:      "streamed components below the threshold are not compressed",
+			component: small(),
+			expected:  "<p>Hello</p>",
+		},
+		{
+			name:             "streamed components are compressed when flushed",
+			component:        flushed(),
+			expectedEncoding: "gzip",
+			expectedFlushed:  true,
+			expected:         "<p>Loading</p><p>Loaded</p>",
+		},
+		{
+			name:      "sibling components below the threshold are not compressed",
+			component: templ.Join(small(), small()),
+			expected:  "<p>Hello</p><p>Hello</p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Encoding", "gzip")
+			templ.Handler(tt.component, templ.WithStreaming(), templ.WithCompression(compress.New())).ServeHTTP(w, r)
+
+			if actual := w.Result().Header.Get("Content-Encoding"); actual != tt.expectedEncoding {
+				t.Errorf("expected Content-Encoding %q, got %q", tt.expectedEncoding, actual)
+			}
+			if w.Flushed != tt.expectedFlushed {
+				t.Errorf("expected flushed to be %v, got %v", tt.expectedFlushed, w.Flushed)
+			}
+			body := io.Reader(w.Body)
+			if tt.expectedEncoding == "gzip" {
+				gr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("failed to create gzip reader: %v", err)
+				}
+				body = gr
+			}
+			actual, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("failed to read body: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, string(actual)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package testcssnested
+
+import (
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSSNested(t *testing.T) {
+	tests := []struct {
+		name     string
+		hover    string
+		expected templ.CSSClass
+	}{
+		{
+			name:  "nested rules are rendered using CSS nesting",
+			hover: "#ff0000",
+			expected: templ.ComponentCSSClass{
+				ID:    "button_27a78a27",
+				Class: templ.SafeCSS(`.button_27a78a27{color:#000000;&:hover{color:#ff0000;}&::before{content:"> ";}@media (max-width: 600px){font-size:12px;}}`),
+			},
+		},
+		{
+			name:  "expression values in nested rules are sanitized",
+			hover: "red;}body{color:red",
+			expected: templ.ComponentCSSClass{
+				ID:    "button_ca854ef2",
+				Class: templ.SafeCSS(`.button_ca854ef2{color:#000000;&:hover{color:zTemplUnsafeCSSPropertyValue;}&::before{content:"> ";}@media (max-width: 600px){font-size:12px;}}`),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, button(tt.hover)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
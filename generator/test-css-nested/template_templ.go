@@ -0,0 +1,29 @@
+// Code generated by templ - DO NOT EDIT.
+
+package testcssnested
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+func button(hover string) templ.CSSClass {
+	templ_7745c5c3_CSSBuilder := templruntime.GetBuilder()
+	templ_7745c5c3_CSSBuilder.WriteString(`color:#000000;`)
+	templ_7745c5c3_CSSBuilder.WriteString(`&:hover{`)
+	templ_7745c5c3_CSSBuilder.WriteString(string(templ.SanitizeCSS(`color`, hover)))
+	templ_7745c5c3_CSSBuilder.WriteString(`}`)
+	templ_7745c5c3_CSSBuilder.WriteString(`&::before{`)
+	templ_7745c5c3_CSSBuilder.WriteString(`content:"> ";`)
+	templ_7745c5c3_CSSBuilder.WriteString(`}`)
+	templ_7745c5c3_CSSBuilder.WriteString(`@media (max-width: 600px){`)
+	templ_7745c5c3_CSSBuilder.WriteString(`font-size:12px;`)
+	templ_7745c5c3_CSSBuilder.WriteString(`}`)
+	templ_7745c5c3_CSSID := templ.CSSID(`button`, templ_7745c5c3_CSSBuilder.String())
+	return templ.ComponentCSSClass{
+		ID:    templ_7745c5c3_CSSID,
+		Class: templ.SafeCSS(`.` + templ_7745c5c3_CSSID + `{` + templ_7745c5c3_CSSBuilder.String() + `}`),
+	}
+}
+
+var _ = templruntime.GeneratedTemplate
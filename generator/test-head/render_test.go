@@ -0,0 +1,59 @@
+package testhead
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test(t *testing.T) {
+	tests := []struct {
+		name            string
+		component       templ.Component
+		expectedFlushed string
+		expected        string
+	}{
+		{
+			name:      "tags added by sibling components are rendered in the head",
+			component: templ.CollectHead(templ.Join(head(), body())),
+			expected:  `<head><title>Page</title></head><body><h1>Page</h1></body>`,
+		},
+		{
+			name:            "the head is written when the response is flushed",
+			component:       templ.CollectHead(templ.Join(head(), streamed())),
+			expectedFlushed: `<head><title>Page</title></head><body><p>Loading</p>`,
+			expected:        `<head><title>Page</title></head><body><p>Loading</p><p>Loaded</p></body>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+			templ.Handler(tt.component, templ.WithStreaming()).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			if diff := cmp.Diff(tt.expectedFlushed, w.flushed); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.expected, w.Body.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+// flushRecorder records the output written before the first flush that wrote output.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed string
+}
+
+func (w *flushRecorder) Flush() {
+	if w.flushed == "" {
+		w.flushed = w.Body.String()
+	}
+	w.ResponseRecorder.Flush()
+}
@@ -0,0 +1,108 @@
+package testi18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/i18n"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newCatalog() *i18n.Catalog {
+	c := i18n.NewCatalog()
+	c.Add("fr", templ.Message{ID: "Hello, { name }!"}, "Bonjour, { name } !")
+	c.Add("fr", templ.Message{ID: "Not translated"}, "Pas traduit")
+	c.Add("fr", templ.Message{ID: "One item", Plural: "{ count } items"}, "Un article", "{ count } articles")
+	c.Add("fr", templ.Message{ID: "Home", Context: "menu"}, "Accueil")
+	c.Add("fr", templ.Message{ID: "Home page", Context: "menu"}, "Page d'accueil")
+	c.Add("fr", templ.Message{ID: "Welcome"}, "Bienvenue")
+	c.Add("fr", templ.Message{ID: "Search"}, "Rechercher")
+	c.Add("fr", templ.Message{ID: "Signed in as"}, "Connecté en tant que")
+	return c
+}
+
+func TestTranslation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    templ.Component
+		locale   string
+		expected string
+	}{
+		{
+			name:     "text is rendered untranslated without a translator",
+			input:    greeting("<Alice>"),
+			expected: `<p>Hello, &lt;Alice&gt;!</p><p>Not translated</p>`,
+		},
+		{
+			name:     "expressions are interpolated into translations",
+			input:    greeting("<Alice>"),
+			locale:   "fr",
+			expected: `<p>Bonjour, &lt;Alice&gt; !</p><p>Not translated</p>`,
+		},
+		{
+			name:     "regional locales fall back to the language",
+			input:    greeting("Alice"),
+			locale:   "fr-CA",
+			expected: `<p>Bonjour, Alice !</p><p>Not translated</p>`,
+		},
+		{
+			name:     "unknown locales are rendered untranslated",
+			input:    greeting("Alice"),
+			locale:   "de",
+			expected: `<p>Hello, Alice!</p><p>Not translated</p>`,
+		},
+		{
+			name:     "the singular form is selected by the count",
+			input:    items(1),
+			locale:   "fr",
+			expected: `<p>Un article</p>`,
+		},
+		{
+			name:     "the plural form is selected by the count",
+			input:    items(3),
+			locale:   "fr",
+			expected: `<p>3 articles</p>`,
+		},
+		{
+			name:     "the plural form is used without a translation",
+			input:    items(3),
+			expected: `<p>3 items</p>`,
+		},
+		{
+			name:     "translatable attributes are translated with the context",
+			input:    menu(),
+			locale:   "fr",
+			expected: `<nav><a href="/" title="Page d&#39;accueil">Accueil</a></nav>`,
+		},
+		{
+			name:     "templates marked with the directive are translated",
+			input:    page("Alice"),
+			locale:   "fr",
+			expected: `<h1>Bienvenue</h1><input type="text" placeholder="Rechercher" value="unchanged"><p>Connecté en tant que <strong>Alice</strong>.</p>`,
+		},
+		{
+			name:     "i18n attributes are rendered outside translatable elements",
+			input:    custom(),
+			locale:   "fr",
+			expected: `<div i18n-context="custom">Not translatable</div>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.locale != "" {
+				ctx = templ.WithTranslator(ctx, newCatalog())
+				ctx = templ.WithLocale(ctx, tt.locale)
+			}
+			w := new(strings.Builder)
+			if err := tt.input.Render(ctx, w); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package testscopedcss
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const expected = `<style type="text/css">` +
+	`.title[data-templ-ec80a55d],h2 > span[data-templ-ec80a55d]{color: red;}` +
+	`.title:hover[data-templ-ec80a55d]::before{content: "> ";}` +
+	`@media (max-width: 600px){.title[data-templ-ec80a55d]{font-size: 12px;}}` +
+	`</style>` +
+	`<div class="card" data-templ-ec80a55d><h2 class="title" data-templ-ec80a55d>First</h2><hr data-templ-ec80a55d><p>Not in the card's scope.</p></div>` +
+	`<div class="card" data-templ-ec80a55d><h2 class="title" data-templ-ec80a55d>Second</h2><hr data-templ-ec80a55d></div>`
+
+func Test(t *testing.T) {
+	var sb strings.Builder
+	if err := Cards().Render(context.Background(), &sb); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(expected, sb.String()); diff != "" {
+		t.Error(diff)
+	}
+}
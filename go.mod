@@ -10,6 +10,7 @@ require (
 	github.com/fatih/color v1.16.0
 	github.com/fsnotify/fsnotify v1.7.0
 	github.com/google/go-cmp v0.6.0
+	github.com/klauspost/compress v1.20.1
 	github.com/natefinch/atomic v1.0.1
 	github.com/rs/cors v1.11.0
 	github.com/stretchr/testify v1.10.0
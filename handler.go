@@ -1,7 +1,12 @@
 package templ
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ComponentHandler is a http.Handler that renders components.
@@ -12,8 +17,33 @@ type ComponentHandler struct {
 	ErrorHandler   func(r *http.Request, err error) http.Handler
 	StreamResponse bool
 	FragmentIDs    []any
+	// ETag is set to calculate an ETag from the rendered output of buffered responses.
+	ETag ETagMode
+	// CacheControl is the value of the Cache-Control header. If not set, and ETag is set,
+	// "no-cache" is used, so that clients revalidate the response before using it.
+	CacheControl string
+	// LastModified is the value of the Last-Modified header, if not zero.
+	LastModified time.Time
+	// Compressor compresses responses, depending on the Accept-Encoding header of the request,
+	// if set.
+	Compressor Compressor
+	// CompressionThreshold is the minimum size of a response, in bytes, that is compressed.
+	// If zero, DefaultCompressionThreshold is used.
+	CompressionThreshold int
 }
 
+// ETagMode controls whether the ComponentHandler calculates an ETag.
+type ETagMode int
+
+const (
+	// ETagNone doesn't calculate an ETag.
+	ETagNone ETagMode = iota
+	// ETagStrong calculates a strong ETag, which means that the output is byte-for-byte identical.
+	ETagStrong
+	// ETagWeak calculates a weak ETag, which allows proxies to modify the output, e.g. to compress it.
+	ETagWeak
+)
+
 const componentHandlerErrorMessage = "templ: failed to render template"
 
 func (ch *ComponentHandler) handleRenderErr(w http.ResponseWriter, r *http.Request, err error) {
@@ -37,14 +67,8 @@ func (ch *ComponentHandler) ServeHTTPBufferedFragment(w http.ResponseWriter, r *
 		return
 	}
 
-	// The component rendered successfully, we can write the Content-Type and Status.
-	w.Header().Set("Content-Type", ch.ContentType)
-	if ch.Status != 0 {
-		w.WriteHeader(ch.Status)
-	}
-	// Ignore write error like http.Error() does, because there is
-	// no way to recover at this point.
-	_, _ = w.Write(buf.Bytes())
+	// The component rendered successfully, we can write the response.
+	ch.writeBuffered(w, r, buf.Bytes())
 }
 
 func (ch *ComponentHandler) ServeHTTPBufferedComplete(w http.ResponseWriter, r *http.Request) {
@@ -59,14 +83,129 @@ func (ch *ComponentHandler) ServeHTTPBufferedComplete(w http.ResponseWriter, r *
 		return
 	}
 
-	// The component rendered successfully, we can write the Content-Type and Status.
-	w.Header().Set("Content-Type", ch.ContentType)
+	// The component rendered successfully, we can write the response.
+	ch.writeBuffered(w, r, buf.Bytes())
+}
+
+// writeBuffered writes a complete response, answering conditional requests with 304 Not Modified,
+// and HEAD requests without the body.
+func (ch *ComponentHandler) writeBuffered(w http.ResponseWriter, r *http.Request, body []byte) {
+	h := w.Header()
+	h.Set("Content-Type", ch.ContentType)
+	var encoding string
+	if ch.Compressor != nil {
+		h.Add("Vary", "Accept-Encoding")
+		if len(body) >= ch.compressionThreshold() {
+			encoding = ch.Compressor.Encoding(r.Header.Get("Accept-Encoding"))
+		}
+	}
+	var etag string
+	if ch.ETag != ETagNone {
+		etag = calculateETag(body, ch.ETag == ETagWeak)
+		if encoding != "" && ch.ETag == ETagStrong {
+			// A strong ETag identifies the exact bytes sent, so it must change with the encoding.
+			etag = strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+		}
+		h.Set("ETag", etag)
+	}
+	ch.setCacheHeaders(h, etag != "")
+	if ch.isNotModified(r, etag) {
+		// A 304 response has no content, so content headers aren't sent.
+		h.Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if encoding != "" {
+		// HEAD requests are compressed too, so that the Content-Length is accurate.
+		compressed, err := compress(ch.Compressor, encoding, body)
+		if err != nil {
+			h.Del("ETag")
+			ch.handleRenderErr(w, r, err)
+			return
+		}
+		h.Set("Content-Encoding", encoding)
+		body = compressed
+	}
+	if r.Method == http.MethodHead {
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+	}
 	if ch.Status != 0 {
 		w.WriteHeader(ch.Status)
 	}
+	if r.Method == http.MethodHead {
+		return
+	}
 	// Ignore write error like http.Error() does, because there is
 	// no way to recover at this point.
-	_, _ = w.Write(buf.Bytes())
+	_, _ = w.Write(body)
+}
+
+func (ch *ComponentHandler) compressionThreshold() int {
+	if ch.CompressionThreshold == 0 {
+		return DefaultCompressionThreshold
+	}
+	return ch.CompressionThreshold
+}
+
+// setCacheHeaders sets the Cache-Control and Last-Modified headers. If the response has an ETag,
+// Cache-Control defaults to "no-cache".
+func (ch *ComponentHandler) setCacheHeaders(h http.Header, hasETag bool) {
+	cacheControl := ch.CacheControl
+	if cacheControl == "" && hasETag {
+		cacheControl = "no-cache"
+	}
+	if cacheControl != "" {
+		h.Set("Cache-Control", cacheControl)
+	}
+	if !ch.LastModified.IsZero() {
+		h.Set("Last-Modified", ch.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// calculateETag returns a quoted ETag that's derived from the body.
+func calculateETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// isNotModified returns true if the conditional headers of a GET or HEAD request show that the
+// client already has the response. If-Modified-Since is ignored if If-None-Match is present, as
+// per RFC 9110.
+func (ch *ComponentHandler) isNotModified(r *http.Request, etag string) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if ch.Status != 0 && ch.Status != http.StatusOK {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etag != "" && etagMatches(inm, etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !ch.LastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		// HTTP dates have a resolution of one second.
+		return !ch.LastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
+
+// etagMatches uses weak comparison to check whether the etag is in the If-None-Match header.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
 }
 
 func (ch *ComponentHandler) ServeHTTPBuffered(w http.ResponseWriter, r *http.Request) {
@@ -83,6 +222,20 @@ func (ch *ComponentHandler) ServeHTTPBuffered(w http.ResponseWriter, r *http.Req
 func (ch *ComponentHandler) ServeHTTPStreamed(w http.ResponseWriter, r *http.Request) {
 	// If streaming, we do not buffer the response, so set the headers immediately.
 	w.Header().Set("Content-Type", ch.ContentType)
+	ch.setCacheHeaders(w.Header(), false)
+	ctx := r.Context()
+	if ch.Compressor != nil {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if encoding := ch.Compressor.Encoding(r.Header.Get("Accept-Encoding")); encoding != "" && r.Method != http.MethodHead {
+			var fs *flushState
+			ctx, fs = withFlushState(ctx)
+			cw := newCompressResponseWriter(w, ch.Compressor, encoding, ch.compressionThreshold(), fs)
+			defer func() {
+				_ = cw.Close()
+			}()
+			w = cw
+		}
+	}
 	if ch.Status != 0 {
 		w.WriteHeader(ch.Status)
 	}
@@ -91,7 +244,7 @@ func (ch *ComponentHandler) ServeHTTPStreamed(w http.ResponseWriter, r *http.Req
 	if len(ch.FragmentIDs) > 0 {
 
 		// Render the component into io.Discard, but use the buffer for fragments.
-		if err := RenderFragments(r.Context(), w, ch.Component, ch.FragmentIDs...); err != nil {
+		if err := RenderFragments(ctx, w, ch.Component, ch.FragmentIDs...); err != nil {
 			ch.handleRenderErr(w, r, err)
 			return
 		}
@@ -99,7 +252,7 @@ func (ch *ComponentHandler) ServeHTTPStreamed(w http.ResponseWriter, r *http.Req
 	}
 
 	// Render the component into the buffer.
-	if err := ch.Component.Render(r.Context(), w); err != nil {
+	if err := ch.Component.Render(ctx, w); err != nil {
 		ch.handleRenderErr(w, r, err)
 		return
 	}
@@ -161,3 +314,64 @@ func WithFragments(ids ...any) func(*ComponentHandler) {
 		ch.FragmentIDs = ids
 	}
 }
+
+// WithETag sets the ComponentHandler to calculate a strong ETag from the rendered output, and
+// respond to requests with a matching If-None-Match header with 304 Not Modified. Unless
+// WithCacheControl is used, the Cache-Control header is set to "no-cache", so that clients
+// revalidate the response before using it.
+//
+// ETags are not calculated for streamed responses.
+func WithETag() func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.ETag = ETagStrong
+	}
+}
+
+// WithWeakETag is the same as WithETag, but calculates a weak ETag.
+func WithWeakETag() func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.ETag = ETagWeak
+	}
+}
+
+// WithCacheControl sets the Cache-Control header returned by the ComponentHandler.
+func WithCacheControl(cacheControl string) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.CacheControl = cacheControl
+	}
+}
+
+// WithLastModified sets the Last-Modified header returned by the ComponentHandler, and responds
+// to requests with an If-Modified-Since header that isn't older with 304 Not Modified.
+//
+// Requests are only checked for buffered responses.
+func WithLastModified(t time.Time) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.LastModified = t
+	}
+}
+
+// WithCompression sets the ComponentHandler to compress responses with the compressor, depending
+// on the Accept-Encoding header of the request. Responses smaller than the compression threshold
+// are sent uncompressed.
+//
+// Streamed responses are held back until they reach the threshold, or are flushed with
+// templ.Flush, which flushes the output through the compressor to the client.
+//
+// The github.com/a-h/templ/compress package provides a compressor that supports zstd, brotli and
+// gzip:
+//
+//	templ.Handler(c, templ.WithCompression(compress.New()))
+func WithCompression(c Compressor) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.Compressor = c
+	}
+}
+
+// WithCompressionThreshold sets the minimum size of a response, in bytes, that is compressed by
+// the compressor set by WithCompression.
+func WithCompressionThreshold(threshold int) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.CompressionThreshold = threshold
+	}
+}
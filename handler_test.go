@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/google/go-cmp/cmp"
@@ -257,3 +258,197 @@ func TestHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestHandlerConditionalRequests(t *testing.T) {
+	hello := templ.Raw("Hello")
+	// The ETag of "Hello".
+	const etag = `"GF-NsyJx_iX1Yab8k4suJg"`
+	lastModified := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		input           *templ.ComponentHandler
+		method          string
+		headers         map[string]string
+		expectedStatus  int
+		expectedHeaders map[string]string
+		expectedBody    string
+	}{
+		{
+			name:           "ETags are not set by default",
+			input:          templ.Handler(hello),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"ETag":          "",
+				"Cache-Control": "",
+			},
+			expectedBody: "Hello",
+		},
+		{
+			name:           "ETags are calculated from the body, and clients are asked to revalidate",
+			input:          templ.Handler(hello, templ.WithETag()),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"ETag":          etag,
+				"Cache-Control": "no-cache",
+				"Content-Type":  "text/html; charset=utf-8",
+			},
+			expectedBody: "Hello",
+		},
+		{
+			name:           "weak ETags can be used",
+			input:          templ.Handler(hello, templ.WithWeakETag()),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"ETag": "W/" + etag,
+			},
+			expectedBody: "Hello",
+		},
+		{
+			name:           "the Cache-Control header can be set",
+			input:          templ.Handler(hello, templ.WithETag(), templ.WithCacheControl("public, max-age=60")),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Cache-Control": "public, max-age=60",
+			},
+			expectedBody: "Hello",
+		},
+		{
+			name:           "a matching If-None-Match header returns 304 without content",
+			input:          templ.Handler(hello, templ.WithETag()),
+			headers:        map[string]string{"If-None-Match": etag},
+			expectedStatus: http.StatusNotModified,
+			expectedHeaders: map[string]string{
+				"ETag":          etag,
+				"Cache-Control": "no-cache",
+				"Content-Type":  "",
+			},
+		},
+		{
+			name:           "If-None-Match uses weak comparison, and can contain a list of ETags",
+			input:          templ.Handler(hello, templ.WithETag()),
+			headers:        map[string]string{"If-None-Match": `"other", W/` + etag},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "If-None-Match matches any ETag with *",
+			input:          templ.Handler(hello, templ.WithETag()),
+			headers:        map[string]string{"If-None-Match": "*"},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "a different ETag returns the body",
+			input:          templ.Handler(hello, templ.WithETag()),
+			headers:        map[string]string{"If-None-Match": `"other"`},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Hello",
+		},
+		{
+			name:           "responses with a status other than 200 are not conditional",
+			input:          templ.Handler(hello, templ.WithETag(), templ.WithStatus(http.StatusNotFound)),
+			headers:        map[string]string{"If-None-Match": etag},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Hello",
+		},
+		{
+			name:           "POST requests are not conditional",
+			input:          templ.Handler(hello, templ.WithETag()),
+			method:         http.MethodPost,
+			headers:        map[string]string{"If-None-Match": etag},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Hello",
+		},
+		{
+			name:           "HEAD requests return the headers without the body",
+			input:          templ.Handler(hello, templ.WithETag()),
+			method:         http.MethodHead,
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"ETag":           etag,
+				"Content-Length": "5",
+			},
+		},
+		{
+			name:           "HEAD requests can be conditional",
+			input:          templ.Handler(hello, templ.WithETag()),
+			method:         http.MethodHead,
+			headers:        map[string]string{"If-None-Match": etag},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "the Last-Modified header can be set",
+			input:          templ.Handler(hello, templ.WithLastModified(lastModified)),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Last-Modified": "Thu, 02 Jan 2025 03:04:05 GMT",
+				"Cache-Control": "",
+			},
+			expectedBody: "Hello",
+		},
+		{
+			name:           "If-Modified-Since returns 304 if the content hasn't been modified since",
+			input:          templ.Handler(hello, templ.WithLastModified(lastModified.Add(time.Millisecond))),
+			headers:        map[string]string{"If-Modified-Since": "Thu, 02 Jan 2025 03:04:05 GMT"},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:           "If-Modified-Since returns the body if the content has been modified since",
+			input:          templ.Handler(hello, templ.WithLastModified(lastModified)),
+			headers:        map[string]string{"If-Modified-Since": "Thu, 02 Jan 2025 03:04:04 GMT"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Hello",
+		},
+		{
+			name:           "If-Modified-Since is ignored if If-None-Match is present",
+			input:          templ.Handler(hello, templ.WithETag(), templ.WithLastModified(lastModified)),
+			headers:        map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Thu, 02 Jan 2025 03:04:05 GMT"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Hello",
+		},
+		{
+			name:           "fragments have ETags",
+			input:          templ.Handler(hello, templ.WithETag(), templ.WithFragments("missing")),
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				// The ETag of an empty body.
+				"ETag": `"47DEQpj8HBSa-_TImW-5JA"`,
+			},
+		},
+		{
+			name:           "streamed responses don't have ETags",
+			input:          templ.Handler(hello, templ.WithETag(), templ.WithStreaming(), templ.WithCacheControl("no-store")),
+			headers:        map[string]string{"If-None-Match": etag},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"ETag":          "",
+				"Cache-Control": "no-store",
+			},
+			expectedBody: "Hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(method, "/test", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			tt.input.ServeHTTP(w, r)
+			if got := w.Result().StatusCode; tt.expectedStatus != got {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, got)
+			}
+			for k, expected := range tt.expectedHeaders {
+				if actual := w.Result().Header.Get(k); actual != expected {
+					t.Errorf("expected %s header %q, got %q", k, expected, actual)
+				}
+			}
+			if diff := cmp.Diff(tt.expectedBody, w.Body.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
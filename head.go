@@ -0,0 +1,260 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrHeadWritten is returned when a component contributes to the head after it has been written,
+// e.g. after the first flush of a streamed response.
+var ErrHeadWritten = errors.New("templ: head tags must be added before the head is written, or the response is flushed")
+
+// HeadTag is a tag in the document head.
+type HeadTag struct {
+	// Key identifies the tag. If more than one tag has the same key, the last one is used.
+	Key string
+	// Component renders the tag.
+	Component Component
+}
+
+// Title sets the <title> of the document.
+func Title(title string) HeadTag {
+	return HeadTag{
+		Key:       "title",
+		Component: Raw("<title>" + EscapeString(title) + "</title>"),
+	}
+}
+
+// Meta sets a <meta name="..."> tag, e.g. the description of the document.
+func Meta(name, content string) HeadTag {
+	return HeadTag{
+		Key:       "meta:name:" + name,
+		Component: Raw(`<meta name="` + EscapeString(name) + `" content="` + EscapeString(content) + `">`),
+	}
+}
+
+// MetaProperty sets a <meta property="..."> tag, e.g. an Open Graph tag such as og:title.
+func MetaProperty(property, content string) HeadTag {
+	return HeadTag{
+		Key:       "meta:property:" + property,
+		Component: Raw(`<meta property="` + EscapeString(property) + `" content="` + EscapeString(content) + `">`),
+	}
+}
+
+// Canonical sets the <link rel="canonical"> tag.
+func Canonical(href string) HeadTag {
+	return HeadTag{
+		Key:       "link:canonical",
+		Component: Raw(`<link rel="canonical" href="` + EscapeString(URL(href)) + `">`),
+	}
+}
+
+// HeadComponent adds a component to the head, e.g. a stylesheet needed by a nested component.
+func HeadComponent(key string, c Component) HeadTag {
+	return HeadTag{
+		Key:       key,
+		Component: c,
+	}
+}
+
+// CollectHead renders the component, replacing the output of CollectedHead with the tags added
+// by Head, including those added by components rendered after the head, e.g. in the body.
+//
+// The output is buffered until the component has been rendered, or until it's flushed by the
+// Flush component. After the first flush, the head is written, and adding tags returns
+// ErrHeadWritten. Generated code also flushes the output when a component has been rendered,
+// which doesn't write the head.
+func CollectHead(c Component) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		if getHeadCollector(ctx) != nil {
+			return c.Render(ctx, w)
+		}
+		hc := &headCollector{
+			marker: newHeadMarker(),
+			tags:   map[string]Component{},
+		}
+		ctx = context.WithValue(ctx, headContextKey, hc)
+		ctx, fs := withFlushState(ctx)
+		hw := &headWriter{ctx: ctx, w: w, hc: hc, fs: fs}
+		if err = c.Render(ctx, hw); err != nil {
+			return err
+		}
+		if hw.err != nil {
+			return hw.err
+		}
+		return hw.writeBuffered()
+	})
+}
+
+// Head adds tags to the head of the document. Nested components can use Head to set the title,
+// description and other tags, which are rendered by CollectedHead in the layout.
+//
+// Head renders nothing if it's not within CollectHead.
+func Head(tags ...HeadTag) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		hc := getHeadCollector(ctx)
+		if hc == nil {
+			return nil
+		}
+		return hc.add(tags)
+	})
+}
+
+// CollectedHead renders the tags added by Head, including those added after it's rendered.
+//
+// CollectedHead renders nothing if it's not within CollectHead.
+func CollectedHead() Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		hc := getHeadCollector(ctx)
+		if hc == nil {
+			return nil
+		}
+		hc.mu.Lock()
+		flushed := hc.flushed
+		if flushed {
+			// The response has been flushed, so the tags must be written now.
+			hc.written = true
+		}
+		hc.mu.Unlock()
+		if flushed {
+			return hc.render(ctx, w)
+		}
+		_, err = io.WriteString(w, hc.marker)
+		return err
+	})
+}
+
+type headContextKeyType int
+
+const headContextKey headContextKeyType = iota
+
+func getHeadCollector(ctx context.Context) *headCollector {
+	hc, _ := ctx.Value(headContextKey).(*headCollector)
+	return hc
+}
+
+func newHeadMarker() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "<!--templ-head-" + hex.EncodeToString(b) + "-->"
+}
+
+type headCollector struct {
+	mu sync.Mutex
+	// marker is written by CollectedHead, and replaced by the tags.
+	marker string
+	// keys are in the order that the tags were first added.
+	keys []string
+	tags map[string]Component
+	// flushed is true when the buffer has been flushed, so output is no longer buffered.
+	flushed bool
+	// written is true when the tags have been written, so more can't be added.
+	written bool
+}
+
+func (hc *headCollector) add(tags []HeadTag) error {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.written {
+		return ErrHeadWritten
+	}
+	for _, tag := range tags {
+		if _, ok := hc.tags[tag.Key]; !ok {
+			hc.keys = append(hc.keys, tag.Key)
+		}
+		hc.tags[tag.Key] = tag.Component
+	}
+	return nil
+}
+
+func (hc *headCollector) render(ctx context.Context, w io.Writer) (err error) {
+	hc.mu.Lock()
+	tags := make([]Component, len(hc.keys))
+	for i, key := range hc.keys {
+		tags[i] = hc.tags[key]
+	}
+	hc.mu.Unlock()
+	for _, tag := range tags {
+		if err = tag.Render(ctx, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// headWriter buffers output until the head tags are known.
+type headWriter struct {
+	ctx context.Context
+	w   io.Writer
+	hc  *headCollector
+	fs  *flushState
+	buf bytes.Buffer
+	// err is the error from writing the buffered output during a flush.
+	err error
+}
+
+func (hw *headWriter) Write(p []byte) (n int, err error) {
+	if hw.err != nil {
+		return 0, hw.err
+	}
+	hw.hc.mu.Lock()
+	flushed := hw.hc.flushed
+	hw.hc.mu.Unlock()
+	if flushed {
+		return hw.w.Write(p)
+	}
+	return hw.buf.Write(p)
+}
+
+// writeBuffered replaces the marker with the tags, and writes the buffered output.
+func (hw *headWriter) writeBuffered() (err error) {
+	hw.hc.mu.Lock()
+	if hw.hc.flushed {
+		hw.hc.mu.Unlock()
+		return nil
+	}
+	hw.hc.flushed = true
+	out := hw.buf.Bytes()
+	before, after, found := bytes.Cut(out, []byte(hw.hc.marker))
+	if found {
+		hw.hc.written = true
+	}
+	hw.hc.mu.Unlock()
+	if found {
+		if _, err = hw.w.Write(before); err != nil {
+			return err
+		}
+		if err = hw.hc.render(hw.ctx, hw.w); err != nil {
+			return err
+		}
+		out = after
+	}
+	_, err = hw.w.Write(out)
+	return err
+}
+
+// Flush writes the head and the buffered output, and flushes the underlying writer. After the
+// first flush, output is no longer buffered. Until then, only the Flush component writes the
+// head, because generated code flushes the output whenever a component has been rendered.
+func (hw *headWriter) Flush() {
+	hw.hc.mu.Lock()
+	flushed := hw.hc.flushed
+	hw.hc.mu.Unlock()
+	if !flushed && !hw.fs.explicit {
+		return
+	}
+	if hw.err = hw.writeBuffered(); hw.err != nil {
+		return
+	}
+	switch w := hw.w.(type) {
+	case flusher:
+		w.Flush()
+	case flusherError:
+		hw.err = w.Flush()
+	}
+}
@@ -0,0 +1,156 @@
+package templ_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHead(t *testing.T) {
+	layout := func(body templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			if err := templ.Head(templ.Title("Site"), templ.Meta("description", "A site")).Render(ctx, w); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, "<html><head>"); err != nil {
+				return err
+			}
+			if err := templ.CollectedHead().Render(ctx, w); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, "</head><body>"); err != nil {
+				return err
+			}
+			if err := body.Render(ctx, w); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "</body></html>")
+			return err
+		})
+	}
+	page := func(tags ...templ.HeadTag) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			if _, err := io.WriteString(w, "<p>Page</p>"); err != nil {
+				return err
+			}
+			return templ.Head(tags...).Render(ctx, w)
+		})
+	}
+
+	tests := []struct {
+		name     string
+		input    templ.Component
+		expected string
+	}{
+		{
+			name:     "tags added by the layout are rendered in the head",
+			input:    templ.CollectHead(layout(page())),
+			expected: `<html><head><title>Site</title><meta name="description" content="A site"></head><body><p>Page</p></body></html>`,
+		},
+		{
+			name:     "tags added after the head is rendered replace tags with the same key",
+			input:    templ.CollectHead(layout(page(templ.Title("Page"), templ.Meta("description", "A page")))),
+			expected: `<html><head><title>Page</title><meta name="description" content="A page"></head><body><p>Page</p></body></html>`,
+		},
+		{
+			name: "tags with different keys are added in order",
+			input: templ.CollectHead(layout(page(
+				templ.Canonical("https://example.com/page"),
+				templ.MetaProperty("og:title", "Page"),
+				templ.Meta("author", "Example"),
+				templ.HeadComponent("stylesheet", templ.Raw(`<link rel="stylesheet" href="/page.css">`)),
+			))),
+			expected: `<html><head><title>Site</title><meta name="description" content="A site">` +
+				`<link rel="canonical" href="https://example.com/page"><meta property="og:title" content="Page">` +
+				`<meta name="author" content="Example"><link rel="stylesheet" href="/page.css">` +
+				`</head><body><p>Page</p></body></html>`,
+		},
+		{
+			name:     "values are escaped",
+			input:    templ.CollectHead(layout(page(templ.Title("</title><script>"), templ.Canonical("javascript:alert(1)")))),
+			expected: `<html><head><title>&lt;/title&gt;&lt;script&gt;</title><meta name="description" content="A site"><link rel="canonical" href="about:invalid#TemplFailedSanitizationURL"></head><body><p>Page</p></body></html>`,
+		},
+		{
+			name:     "nested collectors use the outer collector",
+			input:    templ.CollectHead(layout(templ.CollectHead(page(templ.Title("Page"))))),
+			expected: `<html><head><title>Page</title><meta name="description" content="A site"></head><body><p>Page</p></body></html>`,
+		},
+		{
+			name:     "without a collector, nothing is rendered",
+			input:    layout(page(templ.Title("Page"))),
+			expected: `<html><head></head><body><p>Page</p></body></html>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tt.input.Render(context.Background(), &sb); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, sb.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+
+	t.Run("when streaming, the head is written at the first flush", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		var flushed string
+		body := templ.ComponentFunc(func(ctx context.Context, tw io.Writer) error {
+			if err := templ.Head(templ.Title("Page")).Render(ctx, tw); err != nil {
+				return err
+			}
+			if err := templ.Flush().Render(ctx, tw); err != nil {
+				return err
+			}
+			flushed = w.Body.String()
+			return templ.Head(templ.Title("Too late")).Render(ctx, tw)
+		})
+		h := templ.Handler(templ.CollectHead(layout(body)), templ.WithStreaming())
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if diff := cmp.Diff(`<html><head><title>Page</title><meta name="description" content="A site"></head><body>`, flushed); diff != "" {
+			t.Error(diff)
+		}
+		if !w.Flushed {
+			t.Error("expected the response to be flushed")
+		}
+	})
+	t.Run("when streaming, tags added after the head is written return an error", func(t *testing.T) {
+		body := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			if err := templ.Flush().Render(ctx, w); err != nil {
+				return err
+			}
+			return templ.Head(templ.Title("Too late")).Render(ctx, w)
+		})
+		err := templ.CollectHead(layout(body)).Render(context.Background(), httptest.NewRecorder())
+		if !errors.Is(err, templ.ErrHeadWritten) {
+			t.Errorf("expected ErrHeadWritten, got %v", err)
+		}
+	})
+	t.Run("when streaming, a head rendered after the first flush includes the tags added so far", func(t *testing.T) {
+		c := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			if err := templ.Head(templ.Title("Page")).Render(ctx, w); err != nil {
+				return err
+			}
+			if err := templ.Flush().Render(ctx, w); err != nil {
+				return err
+			}
+			return templ.CollectedHead().Render(ctx, w)
+		})
+		w := httptest.NewRecorder()
+		if err := templ.CollectHead(c).Render(context.Background(), w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(`<title>Page</title>`, w.Body.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
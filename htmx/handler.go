@@ -0,0 +1,62 @@
+package htmx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/a-h/templ"
+)
+
+// Handler creates a http.Handler that renders the component. When htmx makes a request with a
+// target element, only the templ.Fragment with the same ID as the target is rendered, e.g.
+// templ.Fragment("counts") for hx-target="#counts". If no fragment matches the target, or the
+// fragment renders nothing, the complete component is rendered. Other requests, including
+// boosted requests and history restoration, also receive the complete component.
+//
+// The component is only rendered once for each request.
+//
+// The options are the same as for templ.Handler.
+func Handler(c templ.Component, options ...func(*templ.ComponentHandler)) http.Handler {
+	ch := templ.Handler(c, options...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Caches must store responses for each target separately.
+		w.Header().Add("Vary", "HX-Request, HX-Target")
+		target := fragmentID(r)
+		if target == "" {
+			ch.ServeHTTP(w, r)
+			return
+		}
+		// The fragment that matches the target is written to fragmentBuf, and the rest of the
+		// component to buf. If no fragment matches, or the fragment renders nothing, buf contains
+		// the complete component.
+		var buf, fragmentBuf bytes.Buffer
+		err := templ.RenderFragments(r.Context(), &fragmentBuf, templ.ComponentFunc(func(ctx context.Context, _ io.Writer) error {
+			return ch.Component.Render(ctx, &buf)
+		}), target)
+		body := fragmentBuf.String()
+		if body == "" {
+			body = buf.String()
+		}
+		// The output is written by the handler, so that the handler options are applied.
+		rendered := *ch
+		rendered.FragmentIDs = nil
+		rendered.Component = templ.Raw(body)
+		if err != nil {
+			rendered.Component = templ.ComponentFunc(func(context.Context, io.Writer) error {
+				return err
+			})
+		}
+		rendered.ServeHTTP(w, r)
+	})
+}
+
+// fragmentID returns the ID of the fragment to render, or an empty string if the complete
+// component should be rendered.
+func fragmentID(r *http.Request) string {
+	if !IsRequest(r) || IsBoosted(r) || IsHistoryRestoreRequest(r) {
+		return ""
+	}
+	return Target(r)
+}
@@ -0,0 +1,134 @@
+package htmx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHandler(t *testing.T) {
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err := io.WriteString(w, "<h1>Page</h1>"); err != nil {
+			return err
+		}
+		return templ.Fragment("counts").Render(templ.WithChildren(ctx, templ.Raw("<div id=\"counts\">1</div>")), w)
+	})
+	h := Handler(page, templ.WithStatus(http.StatusAccepted))
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "requests not made by htmx receive the page",
+			headers:  map[string]string{"HX-Target": "counts"},
+			expected: `<h1>Page</h1><div id="counts">1</div>`,
+		},
+		{
+			name:     "htmx requests without a target receive the page",
+			headers:  map[string]string{"HX-Request": "true"},
+			expected: `<h1>Page</h1><div id="counts">1</div>`,
+		},
+		{
+			name:     "htmx requests receive the fragment that matches the target",
+			headers:  map[string]string{"HX-Request": "true", "HX-Target": "counts"},
+			expected: `<div id="counts">1</div>`,
+		},
+		{
+			name:     "htmx requests receive the page if no fragment matches the target",
+			headers:  map[string]string{"HX-Request": "true", "HX-Target": "other"},
+			expected: `<h1>Page</h1><div id="counts">1</div>`,
+		},
+		{
+			name:     "boosted requests receive the page",
+			headers:  map[string]string{"HX-Request": "true", "HX-Boosted": "true", "HX-Target": "counts"},
+			expected: `<h1>Page</h1><div id="counts">1</div>`,
+		},
+		{
+			name:     "history restore requests receive the page",
+			headers:  map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true", "HX-Target": "counts"},
+			expected: `<h1>Page</h1><div id="counts">1</div>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			h.ServeHTTP(w, r)
+			if w.Code != http.StatusAccepted {
+				t.Errorf("expected the handler options to be used, got status %d", w.Code)
+			}
+			if diff := cmp.Diff("HX-Request, HX-Target", w.Header().Get("Vary")); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.expected, w.Body.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestHandlerRendersOnce(t *testing.T) {
+	var renders int
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		renders++
+		if _, err := io.WriteString(w, "<h1>Page</h1>"); err != nil {
+			return err
+		}
+		return templ.Fragment("counts").Render(templ.WithChildren(ctx, templ.Raw("<div id=\"counts\">1</div>")), w)
+	})
+	h := Handler(page)
+
+	for _, target := range []string{"counts", "other"} {
+		t.Run(target, func(t *testing.T) {
+			renders = 0
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("HX-Request", "true")
+			r.Header.Set("HX-Target", target)
+			h.ServeHTTP(w, r)
+			if renders != 1 {
+				t.Errorf("expected the component to be rendered once, got %d", renders)
+			}
+		})
+	}
+}
+
+func TestHandlerError(t *testing.T) {
+	var renders int
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		renders++
+		return errors.New("render failed")
+	})
+	h := Handler(page, templ.WithErrorHandler(func(r *http.Request, err error) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = io.WriteString(w, err.Error())
+		})
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("HX-Request", "true")
+	r.Header.Set("HX-Target", "counts")
+	h.ServeHTTP(w, r)
+	if renders != 1 {
+		t.Errorf("expected the component to be rendered once, got %d", renders)
+	}
+	if w.Code != http.StatusTeapot {
+		t.Errorf("expected the error handler to be used, got status %d", w.Code)
+	}
+	if diff := cmp.Diff("render failed", w.Body.String()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,35 @@
+package htmx
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/a-h/templ"
+)
+
+// ErrOOBOuterHTML is returned when rendering an out-of-band swap with the outerHTML strategy.
+var ErrOOBOuterHTML = errors.New("htmx: out-of-band swaps of components can't use outerHTML, use innerHTML, or add hx-swap-oob=\"true\" to the element")
+
+// OOB returns a component that swaps the output of the component into the elements matching the
+// CSS selector, out-of-band. The output is wrapped in an element with the hx-swap-oob attribute,
+// which htmx removes before the swap.
+//
+// Use templ.Join to render several out-of-band swaps in a single response, alongside the main
+// content:
+//
+//	templ.Join(
+//		todoList(todos),
+//		htmx.OOB(htmx.SwapInnerHTML, "#todo-count", todoCount(len(todos))),
+//	)
+//
+// Because the contents of the wrapper element are swapped, the outerHTML strategy isn't supported.
+// To replace an element, add hx-swap-oob="true" to the element in the component instead.
+func OOB(swap Swap, selector string, c templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if swap == SwapOuterHTML {
+			return ErrOOBOuterHTML
+		}
+		return oobTemplate(string(swap)+":"+selector).Render(templ.WithChildren(ctx, c), w)
+	})
+}
@@ -0,0 +1,37 @@
+package htmx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOOB(t *testing.T) {
+	t.Run("components are swapped into the selector", func(t *testing.T) {
+		c := templ.Join(
+			templ.Raw("<ul><li>Todo</li></ul>"),
+			OOB(SwapInnerHTML, "#count", templ.Raw("1")),
+			OOB(SwapBeforeEnd, "#log", templ.Raw("<p>Added</p>")),
+		)
+		var sb strings.Builder
+		if err := c.Render(context.Background(), &sb); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := `<ul><li>Todo</li></ul>` +
+			`<div hx-swap-oob="innerHTML:#count">1</div>` +
+			`<div hx-swap-oob="beforeend:#log"><p>Added</p></div>`
+		if diff := cmp.Diff(expected, sb.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("outerHTML is not supported", func(t *testing.T) {
+		err := OOB(SwapOuterHTML, "#count", templ.Raw("1")).Render(context.Background(), &strings.Builder{})
+		if !errors.Is(err, ErrOOBOuterHTML) {
+			t.Errorf("expected ErrOOBOuterHTML, got %v", err)
+		}
+	})
+}
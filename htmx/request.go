@@ -0,0 +1,42 @@
+// Package htmx contains helpers for responding to requests made by htmx.
+package htmx
+
+import (
+	"net/http"
+)
+
+// IsRequest returns true if the request was made by htmx.
+func IsRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// IsBoosted returns true if the request was made by an element with the hx-boost attribute.
+func IsBoosted(r *http.Request) bool {
+	return r.Header.Get("HX-Boosted") == "true"
+}
+
+// IsHistoryRestoreRequest returns true if the request is for history restoration, after a miss
+// in the local history cache.
+func IsHistoryRestoreRequest(r *http.Request) bool {
+	return r.Header.Get("HX-History-Restore-Request") == "true"
+}
+
+// Target returns the id of the target element, if it has one.
+func Target(r *http.Request) string {
+	return r.Header.Get("HX-Target")
+}
+
+// Trigger returns the id of the element that triggered the request, if it has one.
+func Trigger(r *http.Request) string {
+	return r.Header.Get("HX-Trigger")
+}
+
+// TriggerName returns the name of the element that triggered the request, if it has one.
+func TriggerName(r *http.Request) string {
+	return r.Header.Get("HX-Trigger-Name")
+}
+
+// CurrentURL returns the URL of the page that made the request.
+func CurrentURL(r *http.Request) string {
+	return r.Header.Get("HX-Current-URL")
+}
@@ -0,0 +1,42 @@
+package htmx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if IsRequest(r) || IsBoosted(r) || IsHistoryRestoreRequest(r) {
+		t.Error("request was incorrectly recognised as a htmx request")
+	}
+	r.Header.Set("HX-Request", "true")
+	r.Header.Set("HX-Boosted", "true")
+	r.Header.Set("HX-History-Restore-Request", "true")
+	r.Header.Set("HX-Target", "counts")
+	r.Header.Set("HX-Trigger", "increment")
+	r.Header.Set("HX-Trigger-Name", "global")
+	r.Header.Set("HX-Current-URL", "https://example.com/counts")
+	if !IsRequest(r) {
+		t.Error("expected a htmx request")
+	}
+	if !IsBoosted(r) {
+		t.Error("expected a boosted request")
+	}
+	if !IsHistoryRestoreRequest(r) {
+		t.Error("expected a history restore request")
+	}
+	for name, test := range map[string]struct {
+		actual, expected string
+	}{
+		"Target":      {Target(r), "counts"},
+		"Trigger":     {Trigger(r), "increment"},
+		"TriggerName": {TriggerName(r), "global"},
+		"CurrentURL":  {CurrentURL(r), "https://example.com/counts"},
+	} {
+		if test.actual != test.expected {
+			t.Errorf("%s: expected %q, got %q", name, test.expected, test.actual)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package htmx
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Swap is a swap strategy, as used by the hx-swap attribute. Modifiers can be added after the
+// strategy, e.g. Swap("innerHTML show:top").
+type Swap string
+
+const (
+	// SwapInnerHTML replaces the inner HTML of the target element.
+	SwapInnerHTML Swap = "innerHTML"
+	// SwapOuterHTML replaces the entire target element.
+	SwapOuterHTML Swap = "outerHTML"
+	// SwapTextContent replaces the text content of the target element, without parsing the
+	// response as HTML.
+	SwapTextContent Swap = "textContent"
+	// SwapBeforeBegin inserts the response before the target element.
+	SwapBeforeBegin Swap = "beforebegin"
+	// SwapAfterBegin inserts the response before the first child of the target element.
+	SwapAfterBegin Swap = "afterbegin"
+	// SwapBeforeEnd inserts the response after the last child of the target element.
+	SwapBeforeEnd Swap = "beforeend"
+	// SwapAfterEnd inserts the response after the target element.
+	SwapAfterEnd Swap = "afterend"
+	// SwapDelete deletes the target element.
+	SwapDelete Swap = "delete"
+	// SwapNone doesn't swap the response, but out-of-band swaps are still processed.
+	SwapNone Swap = "none"
+)
+
+// Redirect instructs htmx to redirect the browser to the URL, with a full page reload.
+func Redirect(w http.ResponseWriter, url string) {
+	w.Header().Set("HX-Redirect", url)
+}
+
+// Refresh instructs htmx to reload the page.
+func Refresh(w http.ResponseWriter) {
+	w.Header().Set("HX-Refresh", "true")
+}
+
+// Reswap overrides the swap strategy set by the hx-swap attribute.
+func Reswap(w http.ResponseWriter, swap Swap) {
+	w.Header().Set("HX-Reswap", string(swap))
+}
+
+// Retarget overrides the target element with the elements matching the CSS selector.
+func Retarget(w http.ResponseWriter, selector string) {
+	w.Header().Set("HX-Retarget", selector)
+}
+
+// Reselect sets the CSS selector used to choose the part of the response that is swapped,
+// overriding the hx-select attribute.
+func Reselect(w http.ResponseWriter, selector string) {
+	w.Header().Set("HX-Reselect", selector)
+}
+
+// PushURL pushes the URL into the browser history.
+func PushURL(w http.ResponseWriter, url string) {
+	w.Header().Set("HX-Push-Url", url)
+}
+
+// PreventPushURL prevents the browser history from being updated, overriding the
+// hx-push-url attribute.
+func PreventPushURL(w http.ResponseWriter) {
+	w.Header().Set("HX-Push-Url", "false")
+}
+
+// ReplaceURL replaces the current URL in the browser location bar.
+func ReplaceURL(w http.ResponseWriter, url string) {
+	w.Header().Set("HX-Replace-Url", url)
+}
+
+// TriggerEvent triggers a client-side event as soon as the response is received. The detail is
+// marshalled to JSON, and is available to event listeners in event.detail, or event.detail.value
+// if it isn't a JSON object.
+//
+// TriggerEvent can be called more than once to trigger multiple events.
+func TriggerEvent(w http.ResponseWriter, name string, detail any) error {
+	return addTrigger(w, "HX-Trigger", name, detail)
+}
+
+// TriggerEventAfterSwap is the same as TriggerEvent, but the event is triggered after the swap.
+func TriggerEventAfterSwap(w http.ResponseWriter, name string, detail any) error {
+	return addTrigger(w, "HX-Trigger-After-Swap", name, detail)
+}
+
+// TriggerEventAfterSettle is the same as TriggerEvent, but the event is triggered after the
+// settle step.
+func TriggerEventAfterSettle(w http.ResponseWriter, name string, detail any) error {
+	return addTrigger(w, "HX-Trigger-After-Settle", name, detail)
+}
+
+// addTrigger adds the event to the JSON object in the header, keeping events that have already
+// been set.
+func addTrigger(w http.ResponseWriter, header, name string, detail any) error {
+	events := map[string]any{}
+	if existing := w.Header().Get(header); existing != "" {
+		if err := json.Unmarshal([]byte(existing), &events); err != nil {
+			// The header is a comma separated list of event names.
+			for event := range strings.SplitSeq(existing, ",") {
+				if event = strings.TrimSpace(event); event != "" {
+					events[event] = nil
+				}
+			}
+		}
+	}
+	events[name] = detail
+	b, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	w.Header().Set(header, string(b))
+	return nil
+}
@@ -0,0 +1,119 @@
+package htmx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResponseHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		set      func(w http.ResponseWriter)
+		expected http.Header
+	}{
+		{
+			name:     "Redirect",
+			set:      func(w http.ResponseWriter) { Redirect(w, "/login") },
+			expected: http.Header{"Hx-Redirect": {"/login"}},
+		},
+		{
+			name:     "Refresh",
+			set:      Refresh,
+			expected: http.Header{"Hx-Refresh": {"true"}},
+		},
+		{
+			name:     "Reswap",
+			set:      func(w http.ResponseWriter) { Reswap(w, SwapBeforeEnd+" scroll:bottom") },
+			expected: http.Header{"Hx-Reswap": {"beforeend scroll:bottom"}},
+		},
+		{
+			name:     "Retarget",
+			set:      func(w http.ResponseWriter) { Retarget(w, "#errors") },
+			expected: http.Header{"Hx-Retarget": {"#errors"}},
+		},
+		{
+			name:     "Reselect",
+			set:      func(w http.ResponseWriter) { Reselect(w, "#content") },
+			expected: http.Header{"Hx-Reselect": {"#content"}},
+		},
+		{
+			name:     "PushURL",
+			set:      func(w http.ResponseWriter) { PushURL(w, "/todos/1") },
+			expected: http.Header{"Hx-Push-Url": {"/todos/1"}},
+		},
+		{
+			name:     "PreventPushURL",
+			set:      PreventPushURL,
+			expected: http.Header{"Hx-Push-Url": {"false"}},
+		},
+		{
+			name:     "ReplaceURL",
+			set:      func(w http.ResponseWriter) { ReplaceURL(w, "/todos") },
+			expected: http.Header{"Hx-Replace-Url": {"/todos"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.set(w)
+			if diff := cmp.Diff(tt.expected, w.Header()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestTriggerEvent(t *testing.T) {
+	t.Run("events are encoded as a JSON object", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := TriggerEvent(w, "showMessage", map[string]string{"level": "info", "message": "Saved"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(`{"showMessage":{"level":"info","message":"Saved"}}`, w.Header().Get("HX-Trigger")); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("multiple events can be triggered", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := TriggerEvent(w, "itemAdded", 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := TriggerEvent(w, "cartUpdated", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(`{"cartUpdated":null,"itemAdded":1}`, w.Header().Get("HX-Trigger")); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("events set as a list of names are kept", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		w.Header().Set("HX-Trigger-After-Swap", "first, second")
+		if err := TriggerEventAfterSwap(w, "third", "value"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(`{"first":null,"second":null,"third":"value"}`, w.Header().Get("HX-Trigger-After-Swap")); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("events can be triggered after the settle step", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := TriggerEventAfterSettle(w, "settled", true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(`{"settled":true}`, w.Header().Get("HX-Trigger-After-Settle")); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("details that can't be encoded return an error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := TriggerEvent(w, "invalid", func() {}); err == nil {
+			t.Error("expected an error")
+		}
+		if w.Header().Get("HX-Trigger") != "" {
+			t.Error("expected the header not to be set")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package templ
+
+import "context"
+
+// Message is text marked as translatable within a template, using the i18n attribute or
+// the //templ:i18n directive.
+type Message struct {
+	// ID is the text of the message in the source language. Expressions are included as
+	// placeholders, e.g. "Hello, { name }!".
+	ID string
+	// Plural is the plural form of the message in the source language, if it has one.
+	Plural string
+	// Context is used to distinguish between messages with the same ID.
+	Context string
+}
+
+// Translator provides translations of messages, e.g. the i18n.Catalog type.
+type Translator interface {
+	// Translate returns the translation of the message for the locale. The count is used to
+	// select the plural form of messages that have one. Placeholders in the translation are
+	// replaced by the values of the expressions within the template.
+	Translate(locale string, msg Message, count int) (translation string, ok bool)
+}
+
+type i18nContextKeyType int
+
+const (
+	localeContextKey i18nContextKeyType = iota
+	translatorContextKey
+)
+
+// WithLocale sets the locale used to translate messages, e.g. "en-GB" or "fr".
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey, locale)
+}
+
+// GetLocale returns the locale set with WithLocale, or an empty string if no locale is set.
+func GetLocale(ctx context.Context) string {
+	locale, _ := ctx.Value(localeContextKey).(string)
+	return locale
+}
+
+// WithTranslator sets the Translator used to translate messages.
+func WithTranslator(ctx context.Context, t Translator) context.Context {
+	return context.WithValue(ctx, translatorContextKey, t)
+}
+
+// GetTranslator returns the Translator set with WithTranslator, or nil.
+func GetTranslator(ctx context.Context) Translator {
+	t, _ := ctx.Value(translatorContextKey).(Translator)
+	return t
+}
+
+// Translate returns the translation of the message for the locale in the context.
+//
+// If there is no translation, the message is returned untranslated, using the plural form
+// if the message has one, and the count is not 1.
+func Translate(ctx context.Context, msg Message, count int) string {
+	if t := GetTranslator(ctx); t != nil {
+		if translation, ok := t.Translate(GetLocale(ctx), msg, count); ok {
+			return translation
+		}
+	}
+	if msg.Plural != "" && count != 1 {
+		return msg.Plural
+	}
+	return msg.ID
+}
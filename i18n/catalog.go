@@ -0,0 +1,112 @@
+// Package i18n provides a catalog of translations for templates that contain text marked as
+// translatable with the i18n attribute, or the //templ:i18n directive.
+//
+// Use the `templ i18n extract` command to create a gettext .pot or XLIFF file containing the
+// messages to translate, load the translated .po files into a Catalog, and set the Catalog and
+// the locale on the context used to render templates.
+//
+//	catalog := i18n.NewCatalog()
+//	if err := catalog.ReadPO("fr", f); err != nil {
+//		return err
+//	}
+//	ctx = templ.WithTranslator(ctx, catalog)
+//	ctx = templ.WithLocale(ctx, "fr")
+package i18n
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/a-h/templ"
+)
+
+// Catalog contains the translations of messages for one or more locales.
+type Catalog struct {
+	m            sync.RWMutex
+	translations map[string]map[messageKey][]string
+	pluralRules  map[string]PluralRule
+}
+
+var _ templ.Translator = (*Catalog)(nil)
+
+type messageKey struct {
+	Context string
+	ID      string
+}
+
+// NewCatalog creates an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{
+		translations: make(map[string]map[messageKey][]string),
+		pluralRules:  make(map[string]PluralRule),
+	}
+}
+
+// Add a translation of the message to the catalog. Messages with a plural form take a
+// translation for each of the plural forms of the locale, in the order used by gettext.
+func (c *Catalog) Add(locale string, msg templ.Message, translations ...string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	locale = normalizeLocale(locale)
+	if c.translations[locale] == nil {
+		c.translations[locale] = make(map[messageKey][]string)
+	}
+	c.translations[locale][messageKey{Context: msg.Context, ID: msg.ID}] = translations
+}
+
+// SetPluralRule sets the rule used to select the plural form of messages in the locale,
+// replacing the built-in rule for the language.
+func (c *Catalog) SetPluralRule(locale string, rule PluralRule) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.pluralRules[normalizeLocale(locale)] = rule
+}
+
+// Translate returns the translation of the message. If there is no translation for a regional
+// locale, such as "fr-CA", the translation for the language, "fr", is used.
+func (c *Catalog) Translate(locale string, msg templ.Message, count int) (translation string, ok bool) {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	for _, l := range candidateLocales(locale) {
+		translations, found := c.translations[l][messageKey{Context: msg.Context, ID: msg.ID}]
+		if !found || len(translations) == 0 {
+			continue
+		}
+		index := 0
+		if msg.Plural != "" {
+			index = c.pluralRule(locale)(count)
+		}
+		if index < 0 || index >= len(translations) {
+			index = len(translations) - 1
+		}
+		if translations[index] == "" {
+			continue
+		}
+		return translations[index], true
+	}
+	return "", false
+}
+
+func (c *Catalog) pluralRule(locale string) PluralRule {
+	for _, l := range candidateLocales(locale) {
+		if rule, ok := c.pluralRules[l]; ok {
+			return rule
+		}
+	}
+	return PluralRuleFor(locale)
+}
+
+// normalizeLocale converts locales such as "en_GB" to "en-GB".
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.TrimSpace(locale), "_", "-")
+}
+
+// candidateLocales returns the locale, followed by its language.
+func candidateLocales(locale string) []string {
+	locale = normalizeLocale(locale)
+	language, _, hasRegion := strings.Cut(locale, "-")
+	if hasRegion {
+		return []string{locale, language}
+	}
+	return []string{locale}
+}
@@ -0,0 +1,164 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestCatalogTranslate(t *testing.T) {
+	c := NewCatalog()
+	c.Add("fr", templ.Message{ID: "Hello"}, "Bonjour")
+	c.Add("fr_CA", templ.Message{ID: "Hello"}, "Allô")
+	c.Add("fr", templ.Message{ID: "Home", Context: "menu"}, "Accueil")
+	c.Add("fr", templ.Message{ID: "One item", Plural: "{ n } items"}, "Un article", "{ n } articles")
+	c.Add("pl", templ.Message{ID: "One file", Plural: "{ n } files"}, "Jeden plik", "{ n } pliki", "{ n } plików")
+
+	tests := []struct {
+		name     string
+		locale   string
+		msg      templ.Message
+		count    int
+		expected string
+		ok       bool
+	}{
+		{name: "exact locale", locale: "fr", msg: templ.Message{ID: "Hello"}, expected: "Bonjour", ok: true},
+		{name: "regional locale", locale: "fr-CA", msg: templ.Message{ID: "Hello"}, expected: "Allô", ok: true},
+		{name: "regional locale falls back to language", locale: "fr-BE", msg: templ.Message{ID: "Hello"}, expected: "Bonjour", ok: true},
+		{name: "missing locale", locale: "de", msg: templ.Message{ID: "Hello"}},
+		{name: "context", locale: "fr", msg: templ.Message{ID: "Home", Context: "menu"}, expected: "Accueil", ok: true},
+		{name: "missing context", locale: "fr", msg: templ.Message{ID: "Home"}},
+		{name: "french singular", locale: "fr", msg: templ.Message{ID: "One item", Plural: "{ n } items"}, count: 0, expected: "Un article", ok: true},
+		{name: "french plural", locale: "fr", msg: templ.Message{ID: "One item", Plural: "{ n } items"}, count: 2, expected: "{ n } articles", ok: true},
+		{name: "polish few", locale: "pl", msg: templ.Message{ID: "One file", Plural: "{ n } files"}, count: 22, expected: "{ n } pliki", ok: true},
+		{name: "polish many", locale: "pl", msg: templ.Message{ID: "One file", Plural: "{ n } files"}, count: 12, expected: "{ n } plików", ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := c.Translate(tt.locale, tt.msg, tt.count)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCatalogSetPluralRule(t *testing.T) {
+	c := NewCatalog()
+	c.Add("en", templ.Message{ID: "One item", Plural: "{ n } items"}, "No items", "One item", "{ n } items")
+	c.SetPluralRule("en", func(n int) int {
+		return min(n, 2)
+	})
+	actual, _ := c.Translate("en", templ.Message{ID: "One item", Plural: "{ n } items"}, 0)
+	if actual != "No items" {
+		t.Errorf("expected the custom plural rule to be used, got %q", actual)
+	}
+}
+
+func TestPluralRuleFor(t *testing.T) {
+	tests := []struct {
+		locale   string
+		counts   []int
+		expected []int
+	}{
+		{locale: "en", counts: []int{0, 1, 2}, expected: []int{1, 0, 1}},
+		{locale: "fr-FR", counts: []int{0, 1, 2}, expected: []int{0, 0, 1}},
+		{locale: "ja", counts: []int{0, 1, 2}, expected: []int{0, 0, 0}},
+		{locale: "ru", counts: []int{1, 2, 5, 11, 21, 22}, expected: []int{0, 1, 2, 2, 0, 1}},
+		{locale: "cs", counts: []int{1, 3, 5}, expected: []int{0, 1, 2}},
+		{locale: "ar", counts: []int{0, 1, 2, 3, 11, 100}, expected: []int{0, 1, 2, 3, 4, 5}},
+		{locale: "xx", counts: []int{0, 1, 2}, expected: []int{1, 0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			rule := PluralRuleFor(tt.locale)
+			for i, n := range tt.counts {
+				if actual := rule(n); actual != tt.expected[i] {
+					t.Errorf("n=%d: expected %d, got %d", n, tt.expected[i], actual)
+				}
+			}
+		})
+	}
+}
+
+func TestReadPO(t *testing.T) {
+	po := `# French translations.
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=2; plural=(n > 1);\n"
+
+#: pages/page.templ:4
+msgid "Hello, { name }!"
+msgstr "Bonjour, { name } !"
+
+msgctxt "menu"
+msgid "Home"
+msgstr "Accueil"
+
+msgid "A long message "
+"over two lines"
+msgstr ""
+"Un long message "
+"sur deux lignes"
+
+msgid "One item"
+msgid_plural "{ n } items"
+msgstr[0] "Un article"
+msgstr[1] "{ n } articles"
+
+#, fuzzy
+msgid "Fuzzy"
+msgstr "Flou"
+
+msgid "Untranslated"
+msgstr ""
+`
+	c := NewCatalog()
+	if err := c.ReadPO("fr", strings.NewReader(po)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		msg      templ.Message
+		count    int
+		expected string
+		ok       bool
+	}{
+		{msg: templ.Message{ID: "Hello, { name }!"}, expected: "Bonjour, { name } !", ok: true},
+		{msg: templ.Message{ID: "Home", Context: "menu"}, expected: "Accueil", ok: true},
+		{msg: templ.Message{ID: "A long message over two lines"}, expected: "Un long message sur deux lignes", ok: true},
+		{msg: templ.Message{ID: "One item", Plural: "{ n } items"}, count: 5, expected: "{ n } articles", ok: true},
+		{msg: templ.Message{ID: "Fuzzy"}},
+		{msg: templ.Message{ID: "Untranslated"}},
+		{msg: templ.Message{ID: ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg.ID, func(t *testing.T) {
+			actual, ok := c.Translate("fr", tt.msg, tt.count)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadPOErrors(t *testing.T) {
+	tests := []string{
+		"msgid \"unterminated\n",
+		"msgid \"a\"\nmsgstr[1] \"b\"\n",
+		"unknown \"a\"\n",
+		"\"continuation without keyword\"\n",
+	}
+	for _, po := range tests {
+		if err := NewCatalog().ReadPO("fr", strings.NewReader(po)); err == nil {
+			t.Errorf("expected error for %q", po)
+		}
+	}
+}
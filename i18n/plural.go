@@ -0,0 +1,107 @@
+package i18n
+
+import "strings"
+
+// PluralRule returns the index of the plural form to use for the count.
+type PluralRule func(n int) int
+
+// PluralRuleFor returns the gettext plural rule for the locale's language. Languages that are not
+// known use the English rule, which has a singular and a plural form.
+func PluralRuleFor(locale string) PluralRule {
+	for _, l := range candidateLocales(locale) {
+		if rule, ok := pluralRules[strings.ToLower(l)]; ok {
+			return rule
+		}
+	}
+	return pluralOne
+}
+
+// nplurals=2; plural=(n != 1);
+func pluralOne(n int) int {
+	if n != 1 {
+		return 1
+	}
+	return 0
+}
+
+// nplurals=1; plural=0;
+func pluralNone(n int) int {
+	return 0
+}
+
+// nplurals=2; plural=(n > 1);
+func pluralZeroOne(n int) int {
+	if n > 1 {
+		return 1
+	}
+	return 0
+}
+
+// nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);
+func pluralEastSlavic(n int) int {
+	n = abs(n)
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+		return 1
+	}
+	return 2
+}
+
+// nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);
+func pluralPolish(n int) int {
+	n = abs(n)
+	switch {
+	case n == 1:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 10 || n%100 >= 20):
+		return 1
+	}
+	return 2
+}
+
+// nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;
+func pluralWestSlavic(n int) int {
+	switch {
+	case n == 1:
+		return 0
+	case n >= 2 && n <= 4:
+		return 1
+	}
+	return 2
+}
+
+// nplurals=6; plural=(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5);
+func pluralArabic(n int) int {
+	n = abs(n)
+	switch {
+	case n == 0:
+		return 0
+	case n == 1:
+		return 1
+	case n == 2:
+		return 2
+	case n%100 >= 3 && n%100 <= 10:
+		return 3
+	case n%100 >= 11:
+		return 4
+	}
+	return 5
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+var pluralRules = map[string]PluralRule{
+	"ja": pluralNone, "ko": pluralNone, "zh": pluralNone, "vi": pluralNone, "th": pluralNone, "id": pluralNone, "ms": pluralNone,
+	"fr": pluralZeroOne, "pt-br": pluralZeroOne, "tr": pluralZeroOne,
+	"ru": pluralEastSlavic, "uk": pluralEastSlavic, "be": pluralEastSlavic, "sr": pluralEastSlavic, "hr": pluralEastSlavic, "bs": pluralEastSlavic,
+	"pl": pluralPolish,
+	"cs": pluralWestSlavic, "sk": pluralWestSlavic,
+	"ar": pluralArabic,
+}
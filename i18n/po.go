@@ -0,0 +1,129 @@
+package i18n
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// ReadPO adds the translations in a gettext .po file to the catalog. Untranslated and fuzzy
+// entries are ignored.
+func (c *Catalog) ReadPO(locale string, r io.Reader) error {
+	entries, err := parsePO(r)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.ID == "" || e.Fuzzy || !e.translated() {
+			continue
+		}
+		c.Add(locale, templ.Message{ID: e.ID, Plural: e.Plural, Context: e.Context}, e.Translations...)
+	}
+	return nil
+}
+
+type poEntry struct {
+	Context      string
+	ID           string
+	Plural       string
+	Translations []string
+	Fuzzy        bool
+}
+
+func (e poEntry) translated() bool {
+	for _, t := range e.Translations {
+		if t != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePO(r io.Reader) (entries []poEntry, err error) {
+	var e poEntry
+	// field points at the string being read, so that continuation lines can be appended.
+	var field *string
+	var hasEntry bool
+	flush := func() {
+		if hasEntry {
+			entries = append(entries, e)
+		}
+		e = poEntry{}
+		field = nil
+		hasEntry = false
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var lineNumber int
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			flush()
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			// A comment after the msgstr starts a new entry.
+			if field != nil && len(e.Translations) > 0 {
+				flush()
+			}
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				e.Fuzzy = true
+			}
+			continue
+		}
+		keyword, value, _ := strings.Cut(line, " ")
+		if strings.HasPrefix(line, `"`) {
+			keyword, value = "", line
+		}
+		s, err := strconv.Unquote(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid string %s: %w", lineNumber, value, err)
+		}
+		switch {
+		case keyword == "":
+			if field == nil {
+				return nil, fmt.Errorf("line %d: unexpected string", lineNumber)
+			}
+			*field += s
+			continue
+		case keyword == "msgctxt":
+			if hasEntry && len(e.Translations) > 0 {
+				flush()
+			}
+			e.Context = s
+			field = &e.Context
+		case keyword == "msgid":
+			if hasEntry && len(e.Translations) > 0 {
+				flush()
+			}
+			e.ID = s
+			field = &e.ID
+		case keyword == "msgid_plural":
+			e.Plural = s
+			field = &e.Plural
+		case keyword == "msgstr":
+			e.Translations = append(e.Translations, s)
+			field = &e.Translations[len(e.Translations)-1]
+		case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+			index, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+			if err != nil || index != len(e.Translations) {
+				return nil, fmt.Errorf("line %d: unexpected plural index %q", lineNumber, keyword)
+			}
+			e.Translations = append(e.Translations, s)
+			field = &e.Translations[len(e.Translations)-1]
+		default:
+			return nil, fmt.Errorf("line %d: unexpected keyword %q", lineNumber, keyword)
+		}
+		hasEntry = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return entries, nil
+}
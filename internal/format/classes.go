@@ -0,0 +1,24 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/a-h/templ/internal/tailwind"
+	parser "github.com/a-h/templ/parser/v2"
+	"github.com/a-h/templ/parser/v2/visitor"
+)
+
+// SortClasses sorts the classes in constant class attributes into the order that Tailwind CSS
+// outputs them, using the built-in rule table, so that Node.js is not required.
+func SortClasses(t *parser.TemplateFile) error {
+	v := visitor.New()
+	v.ConstantAttribute = func(n *parser.ConstantAttribute) error {
+		key, ok := n.Key.(parser.ConstantAttributeKey)
+		if !ok || !strings.EqualFold(key.Name, "class") {
+			return nil
+		}
+		n.Value = strings.Join(tailwind.Sort(strings.Fields(n.Value)), " ")
+		return nil
+	}
+	return v.VisitTemplateFile(t)
+}
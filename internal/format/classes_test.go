@@ -0,0 +1,39 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSortClasses(t *testing.T) {
+	input := `package test
+
+templ Button(primary bool) {
+	<button class="text-white px-4 sm:px-8 py-2 btn bg-sky-700 hover:bg-sky-800" type="button">
+		if primary {
+			<span class="font-bold  text-lg underline">Primary</span>
+		}
+	</button>
+	<div class={ "px-4 m-2" } data-class="px-4 m-2"></div>
+}
+`
+	expected := `package test
+
+templ Button(primary bool) {
+	<button class="btn bg-sky-700 px-4 py-2 text-white hover:bg-sky-800 sm:px-8" type="button">
+		if primary {
+			<span class="text-lg font-bold underline">Primary</span>
+		}
+	</button>
+	<div class={ "px-4 m-2" } data-class="px-4 m-2"></div>
+}
+`
+	actual, _, err := Templ([]byte(input), "", Config{PrettierCommand: "nonexistent-prettier-command", SortClasses: true})
+	if err != nil {
+		t.Fatalf("failed to format: %v", err)
+	}
+	if diff := cmp.Diff(expected, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -16,6 +16,8 @@ type Config struct {
 	PrettierCommand string
 	// PrettierRequired indicates that formatting using Prettier must be applied.
 	PrettierRequired bool
+	// SortClasses sorts the classes in constant class attributes into Tailwind CSS order.
+	SortClasses bool
 }
 
 // Templ formats templ source, returning the formatted output, whether it changed, and an error if any.
@@ -34,6 +36,11 @@ func Templ(src []byte, fileName string, config Config) (output []byte, changed b
 	if err = ApplyPrettier(t, config); err != nil {
 		return nil, false, err
 	}
+	if config.SortClasses {
+		if err = SortClasses(t); err != nil {
+			return nil, false, err
+		}
+	}
 
 	w := new(bytes.Buffer)
 	if err = t.Write(w); err != nil {
@@ -0,0 +1,495 @@
+package tailwind
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// group is a set of utilities that set the same CSS properties.
+type group struct {
+	id string
+	// conflicts are the IDs of the groups that set a subset of the properties of this group, and
+	// are overridden by it. For example, "p-4" overrides "px-2".
+	conflicts []string
+}
+
+// rule matches a utility to a group by its prefix and value, e.g. "px" and "4" in "px-4".
+type rule struct {
+	group int
+	valid func(value string) bool
+	// fallback rules are only used if no other rule with the same prefix matches.
+	fallback bool
+}
+
+type ruleDef struct {
+	prefix   string
+	valid    func(value string) bool
+	fallback bool
+}
+
+type groupDef struct {
+	id        string
+	rules     []ruleDef
+	conflicts []string
+}
+
+// exact matches utilities that don't have a value, e.g. "block".
+func exact(names ...string) (defs []ruleDef) {
+	for _, name := range names {
+		defs = append(defs, ruleDef{prefix: name, valid: isEmpty})
+	}
+	return defs
+}
+
+// prefix matches utilities with the prefix, and a value that is valid.
+func prefix(p string, valid func(string) bool) []ruleDef {
+	return []ruleDef{{prefix: p, valid: valid}}
+}
+
+// fallback matches utilities with the prefix and any value, if no other rule matches.
+func fallback(p string) []ruleDef {
+	return []ruleDef{{prefix: p, valid: isAny, fallback: true}}
+}
+
+// values matches utilities with the prefix and one of the values.
+func values(p string, vs ...string) []ruleDef {
+	return prefix(p, oneOf(vs...))
+}
+
+func rules(defs ...[]ruleDef) []ruleDef {
+	return slices.Concat(defs...)
+}
+
+func isEmpty(v string) bool { return v == "" }
+
+func isAny(v string) bool { return v != "" }
+
+func isEmptyOrAny(v string) bool { return true }
+
+func isNumber(v string) bool {
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+var tshirtSize = regexp.MustCompile(`^(\d+(\.\d+)?)?(xs|sm|md|lg|xl)$`)
+
+func isTshirtSize(v string) bool {
+	return tshirtSize.MatchString(v)
+}
+
+func arbitrary(v string) (content string, ok bool) {
+	if len(v) < 2 {
+		return "", false
+	}
+	if (v[0] == '[' && v[len(v)-1] == ']') || (v[0] == '(' && v[len(v)-1] == ')') {
+		return v[1 : len(v)-1], true
+	}
+	return "", false
+}
+
+var length = regexp.MustCompile(`^-?(\d+(\.\d+)?|\.\d+)(px|r?em|%|ch|ex|r?lh|[sld]?v[hwib]|vmin|vmax|cq[whib]|cqmin|cqmax|pt|pc|in|cm|mm|q)$|^0$|^(calc|min|max|clamp)\(`)
+
+// isArbitraryLength matches arbitrary values such as "[10px]" and "[length:var(--size)]".
+func isArbitraryLength(v string) bool {
+	content, ok := arbitrary(v)
+	if !ok {
+		return false
+	}
+	return strings.HasPrefix(content, "length:") || length.MatchString(content)
+}
+
+// isArbitraryNumber matches arbitrary values such as "[600]" and "[number:var(--weight)]".
+func isArbitraryNumber(v string) bool {
+	content, ok := arbitrary(v)
+	return ok && (strings.HasPrefix(content, "number:") || isNumber(content))
+}
+
+func isArbitraryImage(v string) bool {
+	content, ok := arbitrary(v)
+	if !ok {
+		return false
+	}
+	for _, p := range []string{"image:", "url:", "url(", "linear-gradient(", "radial-gradient(", "conic-gradient(", "repeating-", "image-set("} {
+		if strings.HasPrefix(content, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isArbitrarySize(v string) bool {
+	content, ok := arbitrary(v)
+	return ok && (strings.HasPrefix(content, "size:") || strings.HasPrefix(content, "length:") || strings.HasPrefix(content, "percentage:"))
+}
+
+func isArbitraryPosition(v string) bool {
+	content, ok := arbitrary(v)
+	return ok && strings.HasPrefix(content, "position:")
+}
+
+// isWidth matches the values of border, divide, ring and outline width utilities.
+func isWidth(v string) bool {
+	return v == "" || isNumber(v) || isArbitraryLength(v)
+}
+
+func or(validators ...func(string) bool) func(string) bool {
+	return func(v string) bool {
+		for _, valid := range validators {
+			if valid(v) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func oneOf(vs ...string) func(string) bool {
+	return func(v string) bool { return slices.Contains(vs, v) }
+}
+
+// spacing returns the groups for margin or padding utilities.
+func spacing(p string) []groupDef {
+	return []groupDef{
+		{id: p, rules: fallback(p), conflicts: []string{p + "x", p + "y", p + "s", p + "e", p + "t", p + "r", p + "b", p + "l"}},
+		{id: p + "x", rules: fallback(p + "x"), conflicts: []string{p + "r", p + "l"}},
+		{id: p + "y", rules: fallback(p + "y"), conflicts: []string{p + "t", p + "b"}},
+		{id: p + "s", rules: fallback(p + "s")},
+		{id: p + "e", rules: fallback(p + "e")},
+		{id: p + "t", rules: fallback(p + "t")},
+		{id: p + "r", rules: fallback(p + "r")},
+		{id: p + "b", rules: fallback(p + "b")},
+		{id: p + "l", rules: fallback(p + "l")},
+	}
+}
+
+// definitions of the groups, in the order that Tailwind CSS outputs their properties.
+var definitions = slices.Concat(
+	[]groupDef{
+		{id: "sr", rules: exact("sr-only", "not-sr-only")},
+		{id: "pointer-events", rules: fallback("pointer-events")},
+		{id: "visibility", rules: exact("visible", "invisible", "collapse")},
+		{id: "position", rules: exact("static", "fixed", "absolute", "relative", "sticky")},
+		{id: "inset", rules: fallback("inset"), conflicts: []string{"inset-x", "inset-y", "start", "end", "top", "right", "bottom", "left"}},
+		{id: "inset-x", rules: fallback("inset-x"), conflicts: []string{"right", "left"}},
+		{id: "inset-y", rules: fallback("inset-y"), conflicts: []string{"top", "bottom"}},
+		{id: "start", rules: fallback("start")},
+		{id: "end", rules: fallback("end")},
+		{id: "top", rules: fallback("top")},
+		{id: "right", rules: fallback("right")},
+		{id: "bottom", rules: fallback("bottom")},
+		{id: "left", rules: fallback("left")},
+		{id: "isolation", rules: exact("isolate", "isolation-auto")},
+		{id: "z", rules: fallback("z")},
+		{id: "order", rules: fallback("order")},
+		{id: "col", rules: rules(exact("col-auto"), fallback("col-span"), prefix("col", isArbitraryOrNumber))},
+		{id: "col-start", rules: fallback("col-start")},
+		{id: "col-end", rules: fallback("col-end")},
+		{id: "row", rules: rules(exact("row-auto"), fallback("row-span"), prefix("row", isArbitraryOrNumber))},
+		{id: "row-start", rules: fallback("row-start")},
+		{id: "row-end", rules: fallback("row-end")},
+		{id: "float", rules: fallback("float")},
+		{id: "clear", rules: fallback("clear")},
+		{id: "container", rules: exact("container")},
+	},
+	spacing("m"),
+	[]groupDef{
+		{id: "box-sizing", rules: exact("box-border", "box-content")},
+		{id: "line-clamp", rules: fallback("line-clamp"), conflicts: []string{"display", "overflow"}},
+		{id: "display", rules: exact("block", "inline-block", "inline", "flex", "inline-flex", "table", "inline-table",
+			"table-caption", "table-cell", "table-column", "table-column-group", "table-footer-group",
+			"table-header-group", "table-row-group", "table-row", "flow-root", "grid", "inline-grid", "contents",
+			"list-item", "hidden")},
+		{id: "aspect", rules: fallback("aspect")},
+		{id: "size", rules: fallback("size"), conflicts: []string{"w", "h"}},
+		{id: "h", rules: fallback("h")},
+		{id: "max-h", rules: fallback("max-h")},
+		{id: "min-h", rules: fallback("min-h")},
+		{id: "w", rules: fallback("w")},
+		{id: "max-w", rules: fallback("max-w")},
+		{id: "min-w", rules: fallback("min-w")},
+		{id: "flex", rules: fallback("flex"), conflicts: []string{"basis", "grow", "shrink"}},
+		{id: "shrink", rules: rules(prefix("shrink", isEmptyOrAny), prefix("flex-shrink", isEmptyOrAny))},
+		{id: "grow", rules: rules(prefix("grow", isEmptyOrAny), prefix("flex-grow", isEmptyOrAny))},
+		{id: "basis", rules: fallback("basis")},
+		{id: "table-layout", rules: exact("table-auto", "table-fixed")},
+		{id: "caption", rules: exact("caption-top", "caption-bottom")},
+		{id: "border-collapse", rules: exact("border-collapse", "border-separate")},
+		{id: "border-spacing", rules: fallback("border-spacing"), conflicts: []string{"border-spacing-x", "border-spacing-y"}},
+		{id: "border-spacing-x", rules: fallback("border-spacing-x")},
+		{id: "border-spacing-y", rules: fallback("border-spacing-y")},
+		{id: "origin", rules: fallback("origin")},
+		{id: "translate", rules: fallback("translate"), conflicts: []string{"translate-x", "translate-y", "translate-z"}},
+		{id: "translate-x", rules: fallback("translate-x")},
+		{id: "translate-y", rules: fallback("translate-y")},
+		{id: "translate-z", rules: fallback("translate-z")},
+		{id: "scale", rules: fallback("scale"), conflicts: []string{"scale-x", "scale-y", "scale-z"}},
+		{id: "scale-x", rules: fallback("scale-x")},
+		{id: "scale-y", rules: fallback("scale-y")},
+		{id: "scale-z", rules: fallback("scale-z")},
+		{id: "rotate", rules: fallback("rotate")},
+		{id: "rotate-x", rules: fallback("rotate-x")},
+		{id: "rotate-y", rules: fallback("rotate-y")},
+		{id: "rotate-z", rules: fallback("rotate-z")},
+		{id: "skew", rules: fallback("skew"), conflicts: []string{"skew-x", "skew-y"}},
+		{id: "skew-x", rules: fallback("skew-x")},
+		{id: "skew-y", rules: fallback("skew-y")},
+		{id: "transform", rules: exact("transform", "transform-none", "transform-gpu", "transform-cpu")},
+		{id: "animate", rules: fallback("animate")},
+		{id: "cursor", rules: fallback("cursor")},
+		{id: "touch", rules: fallback("touch")},
+		{id: "select", rules: fallback("select")},
+		{id: "resize", rules: prefix("resize", isEmptyOrAny)},
+		{id: "list-position", rules: exact("list-inside", "list-outside")},
+		{id: "list-type", rules: rules(exact("list-none", "list-disc", "list-decimal"), prefix("list", isArbitrary))},
+		{id: "list-image", rules: fallback("list-image")},
+		{id: "appearance", rules: fallback("appearance")},
+		{id: "columns", rules: fallback("columns")},
+		{id: "break-before", rules: fallback("break-before")},
+		{id: "break-inside", rules: fallback("break-inside")},
+		{id: "break-after", rules: fallback("break-after")},
+		{id: "auto-cols", rules: fallback("auto-cols")},
+		{id: "grid-cols", rules: fallback("grid-cols")},
+		{id: "grid-flow", rules: fallback("grid-flow")},
+		{id: "auto-rows", rules: fallback("auto-rows")},
+		{id: "grid-rows", rules: fallback("grid-rows")},
+		{id: "flex-direction", rules: exact("flex-row", "flex-row-reverse", "flex-col", "flex-col-reverse")},
+		{id: "flex-wrap", rules: exact("flex-wrap", "flex-wrap-reverse", "flex-nowrap")},
+		{id: "place-content", rules: fallback("place-content")},
+		{id: "place-items", rules: fallback("place-items")},
+		{id: "align-content", rules: exact("content-normal", "content-center", "content-start", "content-end",
+			"content-between", "content-around", "content-evenly", "content-baseline", "content-stretch")},
+		{id: "items", rules: fallback("items")},
+		{id: "justify", rules: fallback("justify")},
+		{id: "justify-items", rules: fallback("justify-items")},
+		{id: "gap", rules: fallback("gap"), conflicts: []string{"gap-x", "gap-y"}},
+		{id: "gap-x", rules: fallback("gap-x")},
+		{id: "gap-y", rules: fallback("gap-y")},
+		{id: "space-x", rules: fallback("space-x")},
+		{id: "space-y", rules: fallback("space-y")},
+		{id: "space-x-reverse", rules: exact("space-x-reverse")},
+		{id: "space-y-reverse", rules: exact("space-y-reverse")},
+		{id: "divide-x", rules: prefix("divide-x", isWidth)},
+		{id: "divide-y", rules: prefix("divide-y", isWidth)},
+		{id: "divide-x-reverse", rules: exact("divide-x-reverse")},
+		{id: "divide-y-reverse", rules: exact("divide-y-reverse")},
+		{id: "divide-style", rules: exact("divide-solid", "divide-dashed", "divide-dotted", "divide-double", "divide-none")},
+		{id: "divide-color", rules: fallback("divide")},
+		{id: "place-self", rules: fallback("place-self")},
+		{id: "self", rules: fallback("self")},
+		{id: "justify-self", rules: fallback("justify-self")},
+		{id: "overflow", rules: fallback("overflow"), conflicts: []string{"overflow-x", "overflow-y"}},
+		{id: "overflow-x", rules: fallback("overflow-x")},
+		{id: "overflow-y", rules: fallback("overflow-y")},
+		{id: "overscroll", rules: fallback("overscroll"), conflicts: []string{"overscroll-x", "overscroll-y"}},
+		{id: "overscroll-x", rules: fallback("overscroll-x")},
+		{id: "overscroll-y", rules: fallback("overscroll-y")},
+		{id: "scroll-behavior", rules: exact("scroll-auto", "scroll-smooth")},
+		{id: "rounded", rules: prefix("rounded", isEmptyOrAny), conflicts: []string{"rounded-s", "rounded-e", "rounded-t",
+			"rounded-r", "rounded-b", "rounded-l", "rounded-ss", "rounded-se", "rounded-ee", "rounded-es",
+			"rounded-tl", "rounded-tr", "rounded-br", "rounded-bl"}},
+		{id: "rounded-s", rules: prefix("rounded-s", isEmptyOrAny), conflicts: []string{"rounded-ss", "rounded-es"}},
+		{id: "rounded-e", rules: prefix("rounded-e", isEmptyOrAny), conflicts: []string{"rounded-se", "rounded-ee"}},
+		{id: "rounded-t", rules: prefix("rounded-t", isEmptyOrAny), conflicts: []string{"rounded-tl", "rounded-tr"}},
+		{id: "rounded-r", rules: prefix("rounded-r", isEmptyOrAny), conflicts: []string{"rounded-tr", "rounded-br"}},
+		{id: "rounded-b", rules: prefix("rounded-b", isEmptyOrAny), conflicts: []string{"rounded-br", "rounded-bl"}},
+		{id: "rounded-l", rules: prefix("rounded-l", isEmptyOrAny), conflicts: []string{"rounded-tl", "rounded-bl"}},
+		{id: "rounded-ss", rules: prefix("rounded-ss", isEmptyOrAny)},
+		{id: "rounded-se", rules: prefix("rounded-se", isEmptyOrAny)},
+		{id: "rounded-ee", rules: prefix("rounded-ee", isEmptyOrAny)},
+		{id: "rounded-es", rules: prefix("rounded-es", isEmptyOrAny)},
+		{id: "rounded-tl", rules: prefix("rounded-tl", isEmptyOrAny)},
+		{id: "rounded-tr", rules: prefix("rounded-tr", isEmptyOrAny)},
+		{id: "rounded-br", rules: prefix("rounded-br", isEmptyOrAny)},
+		{id: "rounded-bl", rules: prefix("rounded-bl", isEmptyOrAny)},
+		{id: "border-w", rules: prefix("border", isWidth), conflicts: []string{"border-w-x", "border-w-y", "border-w-s",
+			"border-w-e", "border-w-t", "border-w-r", "border-w-b", "border-w-l"}},
+		{id: "border-w-x", rules: prefix("border-x", isWidth), conflicts: []string{"border-w-r", "border-w-l"}},
+		{id: "border-w-y", rules: prefix("border-y", isWidth), conflicts: []string{"border-w-t", "border-w-b"}},
+		{id: "border-w-s", rules: prefix("border-s", isWidth)},
+		{id: "border-w-e", rules: prefix("border-e", isWidth)},
+		{id: "border-w-t", rules: prefix("border-t", isWidth)},
+		{id: "border-w-r", rules: prefix("border-r", isWidth)},
+		{id: "border-w-b", rules: prefix("border-b", isWidth)},
+		{id: "border-w-l", rules: prefix("border-l", isWidth)},
+		{id: "border-style", rules: exact("border-solid", "border-dashed", "border-dotted", "border-double",
+			"border-hidden", "border-none")},
+		{id: "border-color", rules: fallback("border"), conflicts: []string{"border-color-x", "border-color-y",
+			"border-color-s", "border-color-e", "border-color-t", "border-color-r", "border-color-b", "border-color-l"}},
+		{id: "border-color-x", rules: fallback("border-x"), conflicts: []string{"border-color-r", "border-color-l"}},
+		{id: "border-color-y", rules: fallback("border-y"), conflicts: []string{"border-color-t", "border-color-b"}},
+		{id: "border-color-s", rules: fallback("border-s")},
+		{id: "border-color-e", rules: fallback("border-e")},
+		{id: "border-color-t", rules: fallback("border-t")},
+		{id: "border-color-r", rules: fallback("border-r")},
+		{id: "border-color-b", rules: fallback("border-b")},
+		{id: "border-color-l", rules: fallback("border-l")},
+		{id: "bg-color", rules: fallback("bg")},
+		{id: "bg-image", rules: rules(exact("bg-none"), fallback("bg-linear"), fallback("bg-radial"),
+			fallback("bg-conic"), fallback("bg-gradient-to"), prefix("bg", isArbitraryImage))},
+		{id: "from", rules: fallback("from")},
+		{id: "via", rules: fallback("via")},
+		{id: "to", rules: fallback("to")},
+		{id: "box-decoration", rules: fallback("box-decoration")},
+		{id: "bg-size", rules: rules(exact("bg-auto", "bg-cover", "bg-contain"), prefix("bg-size", isAny), prefix("bg", isArbitrarySize))},
+		{id: "bg-attachment", rules: exact("bg-fixed", "bg-local", "bg-scroll")},
+		{id: "bg-clip", rules: fallback("bg-clip")},
+		{id: "bg-position", rules: rules(exact("bg-bottom", "bg-center", "bg-left", "bg-left-bottom", "bg-left-top",
+			"bg-right", "bg-right-bottom", "bg-right-top", "bg-top", "bg-top-left", "bg-top-right", "bg-bottom-left",
+			"bg-bottom-right"), prefix("bg-position", isAny), prefix("bg", isArbitraryPosition))},
+		{id: "bg-repeat", rules: exact("bg-repeat", "bg-no-repeat", "bg-repeat-x", "bg-repeat-y", "bg-repeat-round", "bg-repeat-space")},
+		{id: "bg-origin", rules: fallback("bg-origin")},
+		{id: "fill", rules: fallback("fill")},
+		{id: "stroke-w", rules: prefix("stroke", or(isNumber, isArbitraryLength, isArbitraryNumber))},
+		{id: "stroke", rules: fallback("stroke")},
+		{id: "object-fit", rules: exact("object-contain", "object-cover", "object-fill", "object-none", "object-scale-down")},
+		{id: "object-position", rules: fallback("object")},
+	},
+	spacing("p"),
+	[]groupDef{
+		{id: "text-align", rules: values("text", "left", "center", "right", "justify", "start", "end")},
+		{id: "indent", rules: fallback("indent")},
+		{id: "align", rules: fallback("align")},
+		{id: "font-family", rules: fallback("font")},
+		{id: "font-size", rules: prefix("text", or(oneOf("base"), isTshirtSize, isArbitraryLength)), conflicts: []string{"leading"}},
+		{id: "leading", rules: fallback("leading")},
+		{id: "font-weight", rules: prefix("font", or(oneOf("thin", "extralight", "light", "normal", "medium",
+			"semibold", "bold", "extrabold", "black"), isArbitraryNumber))},
+		{id: "tracking", rules: fallback("tracking")},
+		{id: "text-wrap", rules: values("text", "wrap", "nowrap", "balance", "pretty")},
+		{id: "overflow-wrap", rules: fallback("wrap")},
+		{id: "word-break", rules: exact("break-normal", "break-words", "break-all", "break-keep")},
+		{id: "text-overflow", rules: exact("truncate", "text-ellipsis", "text-clip")},
+		{id: "hyphens", rules: fallback("hyphens")},
+		{id: "whitespace", rules: fallback("whitespace")},
+		{id: "text-color", rules: fallback("text")},
+		{id: "text-transform", rules: exact("uppercase", "lowercase", "capitalize", "normal-case")},
+		{id: "font-style", rules: exact("italic", "not-italic")},
+		{id: "font-stretch", rules: fallback("font-stretch")},
+		{id: "decoration-line", rules: exact("underline", "overline", "line-through", "no-underline")},
+		{id: "decoration-style", rules: exact("decoration-solid", "decoration-double", "decoration-dotted", "decoration-dashed", "decoration-wavy")},
+		{id: "decoration-thickness", rules: prefix("decoration", or(isNumber, oneOf("auto", "from-font"), isArbitraryLength))},
+		{id: "decoration-color", rules: fallback("decoration")},
+		{id: "underline-offset", rules: fallback("underline-offset")},
+		{id: "font-smoothing", rules: exact("antialiased", "subpixel-antialiased")},
+		{id: "caret", rules: fallback("caret")},
+		{id: "accent", rules: fallback("accent")},
+		{id: "scheme", rules: fallback("scheme")},
+		{id: "opacity", rules: fallback("opacity")},
+		{id: "bg-blend", rules: fallback("bg-blend")},
+		{id: "mix-blend", rules: fallback("mix-blend")},
+		{id: "shadow", rules: prefix("shadow", or(isEmpty, isTshirtSize, oneOf("none", "inner"), isArbitraryShadow))},
+		{id: "shadow-color", rules: fallback("shadow")},
+		{id: "inset-shadow", rules: prefix("inset-shadow", or(isTshirtSize, oneOf("none"), isArbitraryShadow))},
+		{id: "inset-shadow-color", rules: fallback("inset-shadow")},
+		{id: "ring-w", rules: prefix("ring", isWidth)},
+		{id: "ring-inset", rules: exact("ring-inset")},
+		{id: "ring-color", rules: fallback("ring")},
+		{id: "inset-ring-w", rules: prefix("inset-ring", isWidth)},
+		{id: "inset-ring-color", rules: fallback("inset-ring")},
+		{id: "text-shadow", rules: prefix("text-shadow", or(isTshirtSize, oneOf("none"), isArbitraryShadow))},
+		{id: "text-shadow-color", rules: fallback("text-shadow")},
+		{id: "ring-offset-w", rules: prefix("ring-offset", or(isNumber, isArbitraryLength))},
+		{id: "ring-offset-color", rules: fallback("ring-offset")},
+		{id: "outline-style", rules: exact("outline-solid", "outline-dashed", "outline-dotted", "outline-double", "outline-none", "outline-hidden")},
+		{id: "outline-w", rules: prefix("outline", isWidth)},
+		{id: "outline-offset", rules: fallback("outline-offset")},
+		{id: "outline-color", rules: fallback("outline")},
+		{id: "blur", rules: prefix("blur", isEmptyOrAny)},
+		{id: "brightness", rules: fallback("brightness")},
+		{id: "contrast", rules: fallback("contrast")},
+		{id: "drop-shadow", rules: prefix("drop-shadow", or(isEmpty, isTshirtSize, oneOf("none"), isArbitraryShadow))},
+		{id: "drop-shadow-color", rules: fallback("drop-shadow")},
+		{id: "grayscale", rules: prefix("grayscale", isEmptyOrAny)},
+		{id: "hue-rotate", rules: fallback("hue-rotate")},
+		{id: "invert", rules: prefix("invert", isEmptyOrAny)},
+		{id: "saturate", rules: fallback("saturate")},
+		{id: "sepia", rules: prefix("sepia", isEmptyOrAny)},
+		{id: "filter", rules: exact("filter", "filter-none")},
+		{id: "backdrop-blur", rules: prefix("backdrop-blur", isEmptyOrAny)},
+		{id: "backdrop-brightness", rules: fallback("backdrop-brightness")},
+		{id: "backdrop-contrast", rules: fallback("backdrop-contrast")},
+		{id: "backdrop-grayscale", rules: prefix("backdrop-grayscale", isEmptyOrAny)},
+		{id: "backdrop-hue-rotate", rules: fallback("backdrop-hue-rotate")},
+		{id: "backdrop-invert", rules: prefix("backdrop-invert", isEmptyOrAny)},
+		{id: "backdrop-opacity", rules: fallback("backdrop-opacity")},
+		{id: "backdrop-saturate", rules: fallback("backdrop-saturate")},
+		{id: "backdrop-sepia", rules: prefix("backdrop-sepia", isEmptyOrAny)},
+		{id: "backdrop-filter", rules: exact("backdrop-filter", "backdrop-filter-none")},
+		{id: "transition", rules: prefix("transition", isEmptyOrAny)},
+		{id: "delay", rules: fallback("delay")},
+		{id: "duration", rules: fallback("duration")},
+		{id: "ease", rules: fallback("ease")},
+		{id: "will-change", rules: fallback("will-change")},
+		{id: "contain", rules: fallback("contain")},
+		{id: "content", rules: fallback("content")},
+	},
+)
+
+func isArbitrary(v string) bool {
+	_, ok := arbitrary(v)
+	return ok
+}
+
+func isArbitraryOrNumber(v string) bool {
+	return isNumber(v) || isArbitrary(v)
+}
+
+// isArbitraryShadow matches arbitrary shadows such as "[0_35px_60px_-15px_rgba(0,0,0,0.3)]", which
+// contain at least one length, unlike arbitrary colors.
+func isArbitraryShadow(v string) bool {
+	content, ok := arbitrary(v)
+	if !ok {
+		return false
+	}
+	if strings.HasPrefix(content, "shadow:") {
+		return true
+	}
+	for _, part := range strings.Split(content, "_") {
+		if length.MatchString(part) {
+			return true
+		}
+	}
+	return false
+}
+
+var groups, rulesByPrefix = buildRules(definitions)
+
+func buildRules(defs []groupDef) (groups []group, rulesByPrefix map[string][]rule) {
+	rulesByPrefix = make(map[string][]rule)
+	for i, def := range defs {
+		groups = append(groups, group{id: def.id, conflicts: def.conflicts})
+		for _, rd := range def.rules {
+			rulesByPrefix[rd.prefix] = append(rulesByPrefix[rd.prefix], rule{group: i, valid: rd.valid, fallback: rd.fallback})
+		}
+	}
+	for _, rs := range rulesByPrefix {
+		slices.SortStableFunc(rs, func(a, b rule) int {
+			switch {
+			case a.fallback == b.fallback:
+				return 0
+			case b.fallback:
+				return -1
+			default:
+				return 1
+			}
+		})
+	}
+	return groups, rulesByPrefix
+}
+
+// variants in the order that Tailwind CSS outputs them. Patterns ending with * match variants
+// with the prefix.
+var variants = []string{
+	"*", "**", "not-*", "group-*", "peer-*",
+	"first-letter", "first-line", "marker", "selection", "file", "placeholder", "backdrop", "before", "after",
+	"first", "last", "only", "odd", "even", "first-of-type", "last-of-type", "only-of-type",
+	"visited", "target", "open", "default", "checked", "indeterminate", "placeholder-shown", "autofill",
+	"optional", "required", "valid", "invalid", "user-valid", "user-invalid", "in-range", "out-of-range",
+	"read-only", "empty", "focus-within", "hover", "focus", "focus-visible", "active", "enabled", "disabled",
+	"inert", "in-*", "has-*", "aria-*", "data-*", "nth-*",
+	"supports-*", "motion-safe", "motion-reduce", "contrast-more", "contrast-less",
+	"max-*", "sm", "md", "lg", "xl", "2xl", "min-*", "@*",
+	"portrait", "landscape", "ltr", "rtl", "dark", "starting", "print", "forced-colors",
+}
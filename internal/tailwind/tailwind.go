@@ -0,0 +1,252 @@
+// Package tailwind resolves conflicts between, and sorts, Tailwind CSS utility classes using a
+// built-in rule table, so that Node.js and the Tailwind CSS compiler are not required.
+//
+// The rule table covers the utilities in the default Tailwind CSS theme. Classes that aren't in the
+// table, such as custom classes, are never treated as conflicting with other classes.
+package tailwind
+
+import (
+	"slices"
+	"strings"
+)
+
+// Version is the version of Tailwind CSS that the rule table is based on.
+const Version = "4.1"
+
+// class is a parsed Tailwind CSS class.
+type class struct {
+	// name is the original class name.
+	name string
+	// variants are the variants of the class, e.g. "hover" and "md" in "md:hover:px-4".
+	variants []string
+	// important is true if the class has the ! modifier.
+	important bool
+	// group is the index of the rule group that the utility belongs to, or -1 if it's unknown.
+	group int
+	// property is the property of an arbitrary property class, e.g. "mask-type" in "[mask-type:luminance]".
+	property string
+}
+
+func parseClass(name string) (c class) {
+	c.name = name
+	c.group = -1
+	segments := splitOutsideBrackets(name, ':')
+	c.variants = segments[:len(segments)-1]
+	utility := segments[len(segments)-1]
+	if strings.HasPrefix(utility, "!") {
+		c.important = true
+		utility = utility[1:]
+	}
+	if strings.HasSuffix(utility, "!") {
+		c.important = true
+		utility = utility[:len(utility)-1]
+	}
+	if strings.HasPrefix(utility, "[") && strings.HasSuffix(utility, "]") {
+		if property, _, ok := strings.Cut(utility[1:len(utility)-1], ":"); ok && property != "" {
+			c.property = property
+		}
+		return c
+	}
+	utility = strings.TrimPrefix(utility, "-")
+	// Modifiers such as the opacity in "bg-red-500/50" don't change the group, but fractions
+	// such as "w-1/2" are part of the value.
+	if index := indexOutsideBrackets(utility, '/'); index >= 0 {
+		if group := lookup(utility[:index]); group >= 0 {
+			c.group = group
+			return c
+		}
+	}
+	c.group = lookup(utility)
+	return c
+}
+
+// lookup returns the index of the rule group that the utility belongs to, or -1.
+func lookup(utility string) int {
+	if utility == "" {
+		return -1
+	}
+	if group, ok := matchRules(utility, ""); ok {
+		return group
+	}
+	// Arbitrary values may contain dashes, so only split the utility before them.
+	end := len(utility)
+	if index := strings.IndexAny(utility, "[("); index >= 0 {
+		end = index
+	}
+	for i := strings.LastIndex(utility[:end], "-"); i > 0; i = strings.LastIndex(utility[:i], "-") {
+		if group, ok := matchRules(utility[:i], utility[i+1:]); ok {
+			return group
+		}
+	}
+	return -1
+}
+
+func matchRules(prefix, value string) (group int, ok bool) {
+	for _, r := range rulesByPrefix[prefix] {
+		if r.valid(value) {
+			return r.group, true
+		}
+	}
+	return -1, false
+}
+
+// key returns the key used to find conflicting classes. Classes with the same variants and
+// important modifier conflict if they are in the same, or a conflicting, group.
+func (c class) key(group string) string {
+	variants := slices.Clone(c.variants)
+	slices.Sort(variants)
+	var sb strings.Builder
+	for _, v := range variants {
+		sb.WriteString(v)
+		sb.WriteByte(':')
+	}
+	if c.important {
+		sb.WriteByte('!')
+	}
+	sb.WriteString(group)
+	return sb.String()
+}
+
+// Merge removes classes that are overridden by later classes, e.g. "px-2 py-1 px-4" becomes
+// "py-1 px-4". Duplicate classes are also removed, keeping the last one.
+func Merge(classes []string) (merged []string) {
+	seen := make(map[string]struct{}, len(classes))
+	for i := len(classes) - 1; i >= 0; i-- {
+		name := classes[i]
+		if name == "" {
+			continue
+		}
+		c := parseClass(name)
+		var keys []string
+		switch {
+		case c.property != "":
+			keys = []string{c.key("[" + c.property + "]")}
+		case c.group >= 0:
+			g := groups[c.group]
+			keys = []string{c.key(g.id)}
+			for _, conflict := range g.conflicts {
+				keys = append(keys, c.key(conflict))
+			}
+		default:
+			keys = []string{"=" + name}
+		}
+		if _, overridden := seen[keys[0]]; overridden {
+			continue
+		}
+		for _, k := range keys {
+			seen[k] = struct{}{}
+		}
+		merged = append(merged, name)
+	}
+	slices.Reverse(merged)
+	return merged
+}
+
+// Sort sorts classes into the order that Tailwind CSS outputs them. Classes that aren't
+// Tailwind CSS utilities come first, in their original order, followed by utilities without
+// variants, then utilities with variants.
+func Sort(classes []string) (sorted []string) {
+	parsed := make([]class, len(classes))
+	for i, name := range classes {
+		parsed[i] = parseClass(name)
+	}
+	slices.SortStableFunc(parsed, compareClasses)
+	sorted = make([]string, len(parsed))
+	for i, c := range parsed {
+		sorted[i] = c.name
+	}
+	return sorted
+}
+
+func compareClasses(a, b class) int {
+	aKnown, bKnown := a.group >= 0 || a.property != "", b.group >= 0 || b.property != ""
+	if aKnown != bKnown {
+		if aKnown {
+			return 1
+		}
+		return -1
+	}
+	if !aKnown {
+		return 0
+	}
+	if c := compareVariants(a.variants, b.variants); c != 0 {
+		return c
+	}
+	// Arbitrary properties are output after utilities.
+	return compareGroups(a, b)
+}
+
+func compareGroups(a, b class) int {
+	ag, bg := a.group, b.group
+	if a.property != "" {
+		ag = len(groups)
+	}
+	if b.property != "" {
+		bg = len(groups)
+	}
+	return ag - bg
+}
+
+func compareVariants(a, b []string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	// Tailwind CSS nests variants from right to left, so the leftmost variant is the outermost.
+	for i := range a {
+		if c := variantOrder(a[i]) - variantOrder(b[i]); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func variantOrder(v string) int {
+	order := len(variants)
+	for i, pattern := range variants {
+		if pattern == v {
+			return i
+		}
+		if prefix, isPrefix := strings.CutSuffix(pattern, "*"); isPrefix && strings.HasPrefix(v, prefix) && i < order {
+			order = i
+		}
+	}
+	return order
+}
+
+func splitOutsideBrackets(s string, sep byte) (segments []string) {
+	var depth, start int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				segments = append(segments, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(segments, s[start:])
+}
+
+func indexOutsideBrackets(s string, c byte) int {
+	var depth int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case c:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
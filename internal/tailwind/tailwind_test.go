@@ -0,0 +1,84 @@
+package tailwind
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "", expected: ""},
+		{input: "px-2 py-1 px-4", expected: "py-1 px-4"},
+		{input: "p-4 px-2", expected: "p-4 px-2"},
+		{input: "px-2 p-4", expected: "p-4"},
+		{input: "mt-2 -mt-4", expected: "-mt-4"},
+		{input: "w-4 h-4 size-8", expected: "size-8"},
+		{input: "w-1/2 w-full", expected: "w-full"},
+		{input: "text-sm text-red-500 text-lg text-blue-600", expected: "text-lg text-blue-600"},
+		{input: "text-left text-center text-primary", expected: "text-center text-primary"},
+		{input: "leading-tight text-lg", expected: "text-lg"},
+		{input: "text-lg leading-tight", expected: "text-lg leading-tight"},
+		{input: "text-lg/7 text-sm", expected: "text-sm"},
+		{input: "font-bold font-sans font-medium", expected: "font-sans font-medium"},
+		{input: "bg-red-500 bg-blue-500/50 bg-cover bg-center", expected: "bg-blue-500/50 bg-cover bg-center"},
+		{input: "bg-[url(/a.png)] bg-none", expected: "bg-none"},
+		{input: "border border-2 border-red-500 border-dashed border-t-4", expected: "border-2 border-red-500 border-dashed border-t-4"},
+		{input: "border-t-4 border-2", expected: "border-2"},
+		{input: "rounded-tl-lg rounded-t-none rounded-md", expected: "rounded-md"},
+		{input: "shadow shadow-lg shadow-red-500", expected: "shadow-lg shadow-red-500"},
+		{input: "ring ring-2 ring-offset-2 ring-blue-500", expected: "ring-2 ring-offset-2 ring-blue-500"},
+		{input: "block flex hidden", expected: "hidden"},
+		{input: "flex flex-1 flex-row flex-none flex-col", expected: "flex flex-none flex-col"},
+		{input: "grow-0 shrink basis-1/2 flex-1", expected: "flex-1"},
+		{input: "inset-x-0 left-2 inset-0", expected: "inset-0"},
+		{input: "overflow-x-auto overflow-hidden", expected: "overflow-hidden"},
+		{input: "hover:bg-red-500 bg-blue-500 hover:bg-green-500", expected: "bg-blue-500 hover:bg-green-500"},
+		{input: "md:hover:px-2 hover:md:px-4", expected: "hover:md:px-4"},
+		{input: "md:px-2 lg:px-4", expected: "md:px-2 lg:px-4"},
+		{input: "!px-2 px-4", expected: "!px-2 px-4"},
+		{input: "px-2! !px-4", expected: "!px-4"},
+		{input: "[mask-type:luminance] [mask-type:alpha] [color:red]", expected: "[mask-type:alpha] [color:red]"},
+		{input: "p-[10px] p-[calc(100%-2rem)]", expected: "p-[calc(100%-2rem)]"},
+		{input: "text-[14px] text-[#333] text-(length:--size)", expected: "text-[#333] text-(length:--size)"},
+		{input: "card card btn px-2", expected: "card btn px-2"},
+		{input: "line-clamp-2 block", expected: "line-clamp-2 block"},
+		{input: "block line-clamp-2", expected: "line-clamp-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual := strings.Join(Merge(strings.Fields(tt.input)), " ")
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "", expected: ""},
+		{input: "text-white px-4 sm:px-8 py-2 sm:py-3 bg-sky-700 hover:bg-sky-800", expected: "bg-sky-700 px-4 py-2 text-white hover:bg-sky-800 sm:px-8 sm:py-3"},
+		{input: "flex card items-center custom gap-2", expected: "card custom flex items-center gap-2"},
+		{input: "p-4 m-2 relative block", expected: "relative m-2 block p-4"},
+		{input: "pt-2 px-2 p-4", expected: "p-4 px-2 pt-2"},
+		{input: "[mask-type:luminance] z-10", expected: "z-10 [mask-type:luminance]"},
+		{input: "dark:text-white md:text-lg hover:underline", expected: "hover:underline md:text-lg dark:text-white"},
+		{input: "font-bold text-lg font-sans", expected: "font-sans text-lg font-bold"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual := strings.Join(Sort(strings.Fields(tt.input)), " ")
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
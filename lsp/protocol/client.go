@@ -127,6 +127,18 @@ func clientDispatch(ctx context.Context, log *slog.Logger, client Client, reply
 
 		return true, reply(ctx, resp, err)
 
+	case MethodShowDocument: // request
+		defer log.Debug(MethodShowDocument, slog.Any("error", err))
+
+		var params ShowDocumentParams
+		if err := dec.Decode(&params); err != nil {
+			return true, replyParseError(ctx, reply, err)
+		}
+
+		resp, err := client.ShowDocument(ctx, &params)
+
+		return true, reply(ctx, resp, err)
+
 	case MethodTelemetryEvent: // notification
 		defer log.Debug(MethodTelemetryEvent, slog.Any("error", err))
 
@@ -211,6 +223,7 @@ type Client interface {
 	PublishDiagnostics(ctx context.Context, params *PublishDiagnosticsParams) (err error)
 	ShowMessage(ctx context.Context, params *ShowMessageParams) (err error)
 	ShowMessageRequest(ctx context.Context, params *ShowMessageRequestParams) (result *MessageActionItem, err error)
+	ShowDocument(ctx context.Context, params *ShowDocumentParams) (result *ShowDocumentResult, err error)
 	Telemetry(ctx context.Context, params any) (err error)
 	RegisterCapability(ctx context.Context, params *RegistrationParams) (err error)
 	UnregisterCapability(ctx context.Context, params *UnregistrationParams) (err error)
@@ -337,6 +350,21 @@ func (c *client) ShowMessageRequest(ctx context.Context, params *ShowMessageRequ
 	return result, nil
 }
 
+// ShowDocument sends the request from a server to a client to ask the client to display a particular document in the user interface.
+//
+// @since 3.16.0.
+func (c *client) ShowDocument(ctx context.Context, params *ShowDocumentParams) (_ *ShowDocumentResult, err error) {
+	c.logger.Debug("call " + MethodShowDocument)
+	defer c.logger.Debug("end "+MethodShowDocument, slog.Any("error", err))
+
+	var result *ShowDocumentResult
+	if err := Call(ctx, c.Conn, MethodShowDocument, params, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // Telemetry sends the notification from the server to the client to ask the client to log a telemetry event.
 func (c *client) Telemetry(ctx context.Context, params any) (err error) {
 	c.logger.Debug("call " + MethodTelemetryEvent)
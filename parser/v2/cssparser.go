@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/a-h/parse"
 )
 
@@ -25,8 +28,31 @@ var cssParser = parse.Func(func(pi *parse.Input) (r *CSSTemplate, ok bool, err e
 	r.Name = exp.Name
 	r.Expression = exp.Expression
 
+	if r.Properties, err = parseCSSProperties(pi); err != nil {
+		return r, false, err
+	}
+	return r, true, nil
+})
+
+// parseCSSProperties parses the properties and nested rules of a css template, or rule, up to and
+// including the closing brace.
+func parseCSSProperties(pi *parse.Input) (properties []CSSProperty, err error) {
+	properties = []CSSProperty{}
 	for {
 		var cssProperty CSSProperty
+		var ok bool
+
+		// Try for a nested rule, which must be parsed before properties, because selectors
+		// such as a:hover look like the start of a property.
+		// &:hover {
+		cssProperty, ok, err = parseCSSRule(pi)
+		if err != nil {
+			return
+		}
+		if ok {
+			properties = append(properties, cssProperty)
+			continue
+		}
 
 		// Try for an expression CSS declaration.
 		// background-color: { constants.BackgroundColor };
@@ -35,7 +61,7 @@ var cssParser = parse.Func(func(pi *parse.Input) (r *CSSTemplate, ok bool, err e
 			return
 		}
 		if ok {
-			r.Properties = append(r.Properties, cssProperty)
+			properties = append(properties, cssProperty)
 			continue
 		}
 
@@ -46,7 +72,7 @@ var cssParser = parse.Func(func(pi *parse.Input) (r *CSSTemplate, ok bool, err e
 			return
 		}
 		if ok {
-			r.Properties = append(r.Properties, cssProperty)
+			properties = append(properties, cssProperty)
 			continue
 		}
 
@@ -61,9 +87,67 @@ var cssParser = parse.Func(func(pi *parse.Input) (r *CSSTemplate, ok bool, err e
 			return
 		}
 
-		return r, true, nil
+		return properties, nil
 	}
-})
+}
+
+// emptyCSSDeclaration matches the start of a property whose value is on the next line, e.g.
+// "color: {", which isn't a rule.
+var emptyCSSDeclaration = regexp.MustCompile(`^-*[a-zA-Z][a-zA-Z0-9-]*\s*:\s*\{$`)
+
+// oneLineCSSRule matches a rule that's written on a single line, e.g. "&:hover { color: blue; }".
+// The first group is the prelude.
+var oneLineCSSRule = regexp.MustCompile(`^([^{};]+)\{[^{}]*\}$`)
+
+// Nested rules, e.g. pseudo-classes, and media queries.
+//
+//	&:hover {
+//	@media (max-width: 600px) {
+func parseCSSRule(pi *parse.Input) (r *CSSRule, ok bool, err error) {
+	start := pi.Index()
+
+	// Optional whitespace.
+	if _, _, err = parse.OptionalWhitespace.Parse(pi); err != nil {
+		return
+	}
+	// The rest of the line must end with an open brace.
+	lineStart := pi.Position()
+	var line string
+	if line, ok, err = parse.StringUntil(parse.NewLine).Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return nil, false, err
+	}
+	line = strings.TrimSpace(line)
+	// Properties are parsed line by line, so the properties of a rule must be on their own lines.
+	// An expression property, e.g. "color: { x }", isn't a rule.
+	if m := oneLineCSSRule.FindStringSubmatch(line); m != nil && !emptyCSSDeclaration.MatchString(m[1]+"{") {
+		return nil, false, parse.Error("css rule: nested rules must span multiple lines, with the closing brace on its own line", lineStart)
+	}
+	if !strings.HasSuffix(line, "{") || strings.Contains(line, ";") || emptyCSSDeclaration.MatchString(line) {
+		pi.Seek(start)
+		return nil, false, nil
+	}
+	r = &CSSRule{
+		Prelude: strings.Join(strings.Fields(strings.TrimSuffix(line, "{")), " "),
+	}
+	if r.Prelude == "" {
+		pi.Seek(start)
+		return nil, false, nil
+	}
+	// \n
+	if _, _, err = parse.NewLine.Parse(pi); err != nil {
+		return
+	}
+
+	if r.Properties, err = parseCSSProperties(pi); err != nil {
+		return r, false, err
+	}
+	// Optional \n after the closing brace.
+	if _, _, err = parse.NewLine.Parse(pi); err != nil {
+		return
+	}
+	return r, true, nil
+}
 
 // css Func() {
 type cssExpression struct {
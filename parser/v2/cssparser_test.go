@@ -333,6 +333,63 @@ background-color: { prop };
 				},
 			},
 		},
+		{
+			name: "css: nested rules",
+			input: `css Name() {
+color: #000000;
+a:hover, &:focus-visible {
+color: #ffffff;
+}
+@media (max-width: 600px) {
+&::before {
+content: "> ";
+}
+}
+}`,
+			expected: &CSSTemplate{
+				Name: "Name",
+				Range: Range{
+					From: Position{Index: 0, Line: 0, Col: 0},
+					To:   Position{Index: 134, Line: 10, Col: 1},
+				},
+				Expression: Expression{
+					Value: "Name()",
+					Range: Range{
+						From: Position{Index: 4, Line: 0, Col: 4},
+						To:   Position{Index: 10, Line: 0, Col: 10},
+					},
+				},
+				Properties: []CSSProperty{
+					&ConstantCSSProperty{
+						Name:  "color",
+						Value: "#000000",
+					},
+					&CSSRule{
+						Prelude: "a:hover, &:focus-visible",
+						Properties: []CSSProperty{
+							&ConstantCSSProperty{
+								Name:  "color",
+								Value: "#ffffff",
+							},
+						},
+					},
+					&CSSRule{
+						Prelude: "@media (max-width: 600px)",
+						Properties: []CSSProperty{
+							&CSSRule{
+								Prelude: "&::before",
+								Properties: []CSSProperty{
+									&ConstantCSSProperty{
+										Name:  "content",
+										Value: `"> "`,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -351,3 +408,46 @@ background-color: { prop };
 		})
 	}
 }
+
+func TestCSSParserErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{
+			name: "css: nested rules on a single line",
+			input: `css Name() {
+&:hover { color: blue; }
+}`,
+			expected: parse.Error("css rule: nested rules must span multiple lines, with the closing brace on its own line",
+				parse.Position{
+					Index: 13,
+					Line:  1,
+					Col:   0,
+				}),
+		},
+		{
+			name: "css: expression properties without a semicolon aren't rules",
+			input: `css Name() {
+color: { "blue" }
+}`,
+			expected: parse.Error("missing expected semicolon (;)",
+				parse.Position{
+					Index: 31,
+					Line:  2,
+					Col:   0,
+				}),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			_, _, err := cssParser.Parse(input)
+			if diff := cmp.Diff(tt.expected, err); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package parser
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// TranslateDirective marks all of the text within a template as translatable when it is placed
+// on the line before the template declaration.
+//
+//	//templ:i18n
+//	templ Page() {
+//	  <h1>Welcome</h1>
+//	}
+const TranslateDirective = "//templ:i18n"
+
+// Attributes used to mark elements as translatable. These attributes are not rendered.
+const (
+	// <p i18n>Hello, { name }</p>
+	TranslateAttribute = "i18n"
+	// <a i18n i18n-context="menu">Home</a>
+	TranslateContextAttribute = "i18n-context"
+	// <p i18n i18n-count={ n } i18n-plural="{ n } items">{ n } item</p>
+	TranslateCountAttribute  = "i18n-count"
+	TranslatePluralAttribute = "i18n-plural"
+)
+
+// translatableAttributes are the attributes that contain human readable text.
+var translatableAttributes = map[string]struct{}{
+	"abbr": {}, "alt": {}, "aria-description": {}, "aria-label": {}, "aria-placeholder": {},
+	"aria-roledescription": {}, "aria-valuetext": {}, "label": {}, "placeholder": {}, "title": {},
+}
+
+// IsTranslatableAttribute returns true if the attribute contains human readable text that is
+// translated when the element is marked as translatable.
+func IsTranslatableAttribute(name string) bool {
+	_, ok := translatableAttributes[strings.ToLower(name)]
+	return ok
+}
+
+// IsTranslationAttribute returns true if the attribute is used to mark an element as translatable,
+// i.e. it's the i18n boolean attribute, the i18n-context or i18n-plural constant attribute, or the
+// i18n-count expression attribute.
+func IsTranslationAttribute(attr Attribute) bool {
+	switch attr := attr.(type) {
+	case *BoolConstantAttribute:
+		return isConstantAttributeKey(attr.Key, TranslateAttribute)
+	case *ConstantAttribute:
+		return isConstantAttributeKey(attr.Key, TranslateContextAttribute) || isConstantAttributeKey(attr.Key, TranslatePluralAttribute)
+	case *ExpressionAttribute:
+		return isConstantAttributeKey(attr.Key, TranslateCountAttribute)
+	}
+	return false
+}
+
+func isConstantAttributeKey(key AttributeKey, name string) bool {
+	k, ok := key.(ConstantAttributeKey)
+	return ok && k.Name == name
+}
+
+// Message is text marked as translatable, extracted from a template.
+type Message struct {
+	// ID is the text of the message. Expressions are replaced by placeholders containing the
+	// expression, e.g. "Hello, { name }!".
+	ID string
+	// Plural is the plural form of the message, set with the i18n-plural attribute.
+	Plural string
+	// Context is used to distinguish between messages with the same text, set with the
+	// i18n-context attribute.
+	Context string
+	// Count is the expression used to select the plural form, set with the i18n-count attribute.
+	Count *Expression
+	// Args are the expressions within the message and its plural form.
+	Args []Expression
+	// Range of the message within the templ file.
+	Range Range
+	// LeadingSpace and TrailingSpace are true if the text has whitespace around it. The
+	// whitespace is not part of the message, but is rendered around the translation.
+	LeadingSpace  bool
+	TrailingSpace bool
+}
+
+// Translation is the translation state of an element, or template.
+type Translation struct {
+	// Translatable is true if the text within the element should be translated.
+	Translatable bool
+	Context      string
+	Plural       string
+	// PluralArgs are the expressions within the placeholders of the plural form.
+	PluralArgs []Expression
+	Count      *Expression
+}
+
+// Element returns the translation state of an element within the parent. Elements within a
+// translatable element are also translatable, and use the same context. The plural form only
+// applies to the text directly within the element that it's set on.
+func (t Translation) Element(e *Element) (child Translation) {
+	child.Translatable = t.Translatable
+	child.Context = t.Context
+	for _, attr := range e.Attributes {
+		if !IsTranslationAttribute(attr) {
+			continue
+		}
+		switch attr := attr.(type) {
+		case *BoolConstantAttribute:
+			child.Translatable = true
+		case *ConstantAttribute:
+			if isConstantAttributeKey(attr.Key, TranslateContextAttribute) {
+				child.Context = html.UnescapeString(attr.Value)
+				continue
+			}
+			child.Plural = html.UnescapeString(attr.Value)
+			child.PluralArgs = placeholderArgs(attr.Value, attr.ValueRange.From)
+		case *ExpressionAttribute:
+			count := attr.Expression
+			child.Count = &count
+		}
+	}
+	return child
+}
+
+// TextMessage creates a message from a run of text and string expressions. If the run
+// contains no words, e.g. it only contains punctuation, there is nothing to translate, and
+// ok is false.
+func (t Translation) TextMessage(run []Node) (msg Message, ok bool) {
+	var sb strings.Builder
+	for i, n := range run {
+		switch n := n.(type) {
+		case *Text:
+			sb.WriteString(html.UnescapeString(n.Value))
+			ok = ok || strings.IndexFunc(n.Value, unicode.IsLetter) >= 0
+		case *StringExpression:
+			msg.Args = appendArg(msg.Args, n.Expression)
+			sb.WriteString(placeholder(n.Expression.Value))
+		default:
+			return msg, false
+		}
+		if i < len(run)-1 {
+			if ws, isTrailer := n.(WhitespaceTrailer); isTrailer && ws.Trailing() != SpaceNone {
+				sb.WriteString(" ")
+			}
+		}
+	}
+	if !ok {
+		return msg, false
+	}
+	text := sb.String()
+	msg.ID = collapseWhitespace(text)
+	msg.LeadingSpace = strings.TrimLeftFunc(text, unicode.IsSpace) != text
+	msg.TrailingSpace = strings.TrimRightFunc(text, unicode.IsSpace) != text
+	msg.Context = t.Context
+	msg.Range = Range{From: nodeRange(run[0]).From, To: nodeRange(run[len(run)-1]).To}
+	if t.Plural != "" && t.Count != nil {
+		msg.Plural = collapseWhitespace(t.Plural)
+		msg.Count = t.Count
+		for _, arg := range t.PluralArgs {
+			msg.Args = appendArg(msg.Args, arg)
+		}
+	}
+	return msg, true
+}
+
+// AttributeMessage creates a message from a translatable constant attribute.
+func (t Translation) AttributeMessage(attr *ConstantAttribute) (msg Message, ok bool) {
+	k, isConstant := attr.Key.(ConstantAttributeKey)
+	if !isConstant || !IsTranslatableAttribute(k.Name) || strings.TrimSpace(attr.Value) == "" {
+		return msg, false
+	}
+	return Message{
+		ID:      html.UnescapeString(attr.Value),
+		Context: t.Context,
+		Range:   attr.ValueRange,
+	}, true
+}
+
+// TextRuns splits nodes into runs of text and string expressions that are translated as a
+// single message. Each run is returned with its index within nodes.
+func TextRuns(nodes []Node) (runs map[int][]Node) {
+	runs = make(map[int][]Node)
+	start := -1
+	for i, n := range nodes {
+		switch n.(type) {
+		case *Text, *StringExpression:
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			runs[start] = nodes[start:i]
+			start = -1
+		}
+	}
+	if start >= 0 {
+		runs[start] = nodes[start:]
+	}
+	return runs
+}
+
+// Messages returns the translatable messages within the template file, in the order that
+// they appear.
+func (tf *TemplateFile) Messages() (msgs []Message) {
+	for _, n := range tf.Nodes {
+		t, ok := n.(*HTMLTemplate)
+		if !ok {
+			continue
+		}
+		msgs = appendMessages(msgs, Translation{Translatable: t.Translatable}, t.Children)
+	}
+	return msgs
+}
+
+func appendMessages(msgs []Message, t Translation, nodes []Node) []Message {
+	runs := TextRuns(nodes)
+	for i := 0; i < len(nodes); i++ {
+		if run, isRun := runs[i]; isRun {
+			if msg, ok := t.TextMessage(run); ok && t.Translatable {
+				msgs = append(msgs, msg)
+			}
+			i += len(run) - 1
+			continue
+		}
+		switch n := nodes[i].(type) {
+		case *Element:
+			child := t.Element(n)
+			if child.Translatable {
+				for _, attr := range n.Attributes {
+					if ca, isConstant := attr.(*ConstantAttribute); isConstant {
+						if msg, ok := child.AttributeMessage(ca); ok {
+							msgs = append(msgs, msg)
+						}
+					}
+				}
+			}
+			msgs = appendMessages(msgs, child, n.Children)
+		case *IfExpression:
+			msgs = appendMessages(msgs, t, n.Then)
+			for _, elseIf := range n.ElseIfs {
+				msgs = appendMessages(msgs, t, elseIf.Then)
+			}
+			msgs = appendMessages(msgs, t, n.Else)
+		case *SwitchExpression:
+			for _, c := range n.Cases {
+				msgs = appendMessages(msgs, t, c.Children)
+			}
+		case *ForExpression:
+			msgs = appendMessages(msgs, t, n.Children)
+		case *TemplElementExpression:
+			msgs = appendMessages(msgs, t, n.Children)
+		}
+	}
+	return msgs
+}
+
+// isTranslateDirective returns true if the Go code ends with the translate directive.
+func isTranslateDirective(code string) bool {
+	code = strings.TrimSpace(code)
+	lastLine := code[strings.LastIndex(code, "\n")+1:]
+	return strings.TrimSpace(lastLine) == TranslateDirective
+}
+
+func placeholder(expr string) string {
+	return "{ " + strings.TrimSpace(expr) + " }"
+}
+
+// placeholderArgs returns the expressions within placeholders in a message, such as the plural
+// form, with their positions within the templ file, given the position of the message.
+func placeholderArgs(s string, from Position) (args []Expression) {
+	var offset int
+	for {
+		start := strings.Index(s[offset:], "{")
+		if start < 0 {
+			return args
+		}
+		start += offset + 1
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return args
+		}
+		end += start
+		value := s[start:end]
+		if expr := strings.TrimSpace(value); expr != "" {
+			exprStart := start + strings.Index(value, expr)
+			args = append(args, Expression{
+				Value: expr,
+				Range: Range{
+					From: advancePosition(from, s[:exprStart]),
+					To:   advancePosition(from, s[:exprStart+len(expr)]),
+				},
+			})
+		}
+		offset = end + 1
+	}
+}
+
+// advancePosition returns the position after the text, which starts at the position.
+func advancePosition(pos Position, text string) Position {
+	pos.Index += int64(len(text))
+	if i := strings.LastIndex(text, "\n"); i >= 0 {
+		pos.Line += uint32(strings.Count(text, "\n"))
+		pos.Col = uint32(len(text) - i - 1)
+		return pos
+	}
+	pos.Col += uint32(len(text))
+	return pos
+}
+
+func appendArg(args []Expression, arg Expression) []Expression {
+	for _, a := range args {
+		if strings.TrimSpace(a.Value) == strings.TrimSpace(arg.Value) {
+			return args
+		}
+	}
+	return append(args, arg)
+}
+
+func collapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func nodeRange(n Node) Range {
+	switch n := n.(type) {
+	case *Text:
+		return n.Range
+	case *StringExpression:
+		return n.Range
+	}
+	return Range{}
+}
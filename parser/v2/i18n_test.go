@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []Message
+	}{
+		{
+			name: "text is not translatable by default",
+			input: `package main
+
+templ Page() {
+	<p>Hello</p>
+}
+`,
+			expected: nil,
+		},
+		{
+			name: "elements with the i18n attribute are translatable",
+			input: `package main
+
+templ Page(name string) {
+	<p i18n>Hello, { name }!</p>
+	<p>Not translated</p>
+}
+`,
+			expected: []Message{
+				{ID: "Hello, { name }!", Args: []Expression{{Value: "name"}}},
+			},
+		},
+		{
+			name: "text within nested elements is split into separate messages",
+			input: `package main
+
+templ Page(name string) {
+	<p i18n>Signed in as <strong>{ name }</strong>. <a href="/logout" title="Sign out">Sign out</a></p>
+}
+`,
+			expected: []Message{
+				{ID: "Signed in as", TrailingSpace: true},
+				{ID: "Sign out"},
+				{ID: "Sign out"},
+			},
+		},
+		{
+			name: "the context applies to child elements",
+			input: `package main
+
+templ Page() {
+	<nav i18n i18n-context="menu">
+		<a href="/">Home</a>
+	</nav>
+}
+`,
+			expected: []Message{
+				{ID: "Home", Context: "menu"},
+			},
+		},
+		{
+			name: "plural forms are set with attributes",
+			input: `package main
+
+templ Items(n int) {
+	<p i18n i18n-count={ n } i18n-plural="{ n } items">One item</p>
+}
+`,
+			expected: []Message{
+				{ID: "One item", Plural: "{ n } items", Count: &Expression{Value: "n"}, Args: []Expression{{Value: "n"}}},
+			},
+		},
+		{
+			name: "templates can be marked with the directive",
+			input: `package main
+
+//templ:i18n
+templ Page() {
+	<h1>Welcome</h1>
+	if true {
+		<p>Conditional   text</p>
+	}
+}
+
+templ Other() {
+	<p>Not translated</p>
+}
+`,
+			expected: []Message{
+				{ID: "Welcome"},
+				{ID: "Conditional text"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tf, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			actual := tf.Messages()
+			// Ranges are tested separately.
+			for i := range actual {
+				actual[i].Range = Range{}
+				for j := range actual[i].Args {
+					actual[i].Args[j].Range = Range{}
+				}
+				if actual[i].Count != nil {
+					actual[i].Count.Range = Range{}
+				}
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestMessageRange(t *testing.T) {
+	tf, err := ParseString(`package main
+
+templ Page(name string) {
+	<p i18n>Hello, { name }!</p>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	msgs := tf.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	expected := Range{
+		From: Position{Index: 49, Line: 3, Col: 9},
+		To:   Position{Index: 65, Line: 3, Col: 25},
+	}
+	if diff := cmp.Diff(expected, msgs[0].Range); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestPluralArgRanges(t *testing.T) {
+	tf, err := ParseString(`package main
+
+templ Page(n int, name string) {
+	<p i18n i18n-count={ n } i18n-plural="{ n } items for { name }">One item for { name }</p>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	msgs := tf.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	// The name argument is taken from the text, and the n argument from the plural form.
+	expected := []Expression{
+		{
+			Value: "name",
+			Range: Range{
+				From: Position{Index: 127, Line: 3, Col: 80},
+				To:   Position{Index: 131, Line: 3, Col: 84},
+			},
+		},
+		{
+			Value: "n",
+			Range: Range{
+				From: Position{Index: 88, Line: 3, Col: 41},
+				To:   Position{Index: 89, Line: 3, Col: 42},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, msgs[0].Args); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestIsTranslationAttribute(t *testing.T) {
+	tf, err := ParseString(`package main
+
+templ Page(n int) {
+	<p i18n i18n-context="x" i18n-count={ n } i18n-plural="y" class="z" title="t" i18n-other="o" i18n-count="1"></p>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	e := tf.Nodes[0].(*HTMLTemplate).Children[1].(*Element)
+	var actual []bool
+	for _, attr := range e.Attributes {
+		actual = append(actual, IsTranslationAttribute(attr))
+	}
+	if diff := cmp.Diff([]bool{true, true, true, true, false, false, false, false}, actual); diff != "" {
+		t.Error(diff)
+	}
+}
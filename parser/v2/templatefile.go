@@ -120,6 +120,11 @@ outer:
 			return tf, false, err
 		}
 		if matched {
+			if len(tf.Nodes) > 0 {
+				if prev, ok := tf.Nodes[len(tf.Nodes)-1].(*TemplateFileGoExpression); ok {
+					tn.Translatable = isTranslateDirective(prev.Expression.Value)
+				}
+			}
 			tf.Nodes = append(tf.Nodes, tn)
 			_, _, _ = parse.OptionalWhitespace.Parse(pi)
 			continue
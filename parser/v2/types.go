@@ -268,6 +268,9 @@ func (ws *Whitespace) Visit(v Visitor) error {
 //	  color: #ffffff;
 //	  background-color: { constants.BackgroundColor };
 //	  background-image: url('./somewhere.png');
+//	  &:hover {
+//	    color: { constants.HoverColor };
+//	  }
 //	}
 type CSSTemplate struct {
 	Range      Range
@@ -362,6 +365,34 @@ func (c *ExpressionCSSProperty) Visit(v Visitor) error {
 	return v.VisitExpressionCSSProperty(c)
 }
 
+// CSSRule is a nested rule in a css template, e.g. a pseudo-class, or a media query.
+//
+//	&:hover {
+//	  color: red;
+//	}
+type CSSRule struct {
+	// Prelude is the selector, or at-rule, e.g. "&:hover", or "@media (max-width: 600px)".
+	Prelude    string
+	Properties []CSSProperty
+}
+
+func (c *CSSRule) IsCSSProperty() bool { return true }
+func (c *CSSRule) Write(w io.Writer, indent int) error {
+	if err := writeIndent(w, indent, c.Prelude, " {\n"); err != nil {
+		return err
+	}
+	for _, p := range c.Properties {
+		if err := p.Write(w, indent+1); err != nil {
+			return err
+		}
+	}
+	return writeIndent(w, indent, "}\n")
+}
+
+func (c *CSSRule) Visit(v Visitor) error {
+	return v.VisitCSSRule(c)
+}
+
 // <!DOCTYPE html>
 type DocType struct {
 	Range      Range
@@ -391,6 +422,8 @@ type HTMLTemplate struct {
 	Range      Range
 	Expression Expression
 	Children   []Node
+	// Translatable is true if the template is preceded by the //templ:i18n directive.
+	Translatable bool
 }
 
 func (t *HTMLTemplate) IsTemplateFileNode() bool { return true }
@@ -1,6 +1,11 @@
 package parser
 
 // Visitor is an interface for visiting nodes in the parse tree.
+//
+// Methods are added when new node types are added to the parse tree, e.g. VisitCSSRule for
+// nested CSS rules, which breaks types that implement every method themselves. Use visitor.New
+// from the parser/v2/visitor package instead, and override the functions for the nodes of
+// interest, or embed *visitor.Visitor, so that new methods have a default implementation.
 type Visitor interface {
 	VisitTemplateFile(*TemplateFile) error
 	VisitTemplateFileGoExpression(*TemplateFileGoExpression) error
@@ -9,6 +14,7 @@ type Visitor interface {
 	VisitCSSTemplate(*CSSTemplate) error
 	VisitConstantCSSProperty(*ConstantCSSProperty) error
 	VisitExpressionCSSProperty(*ExpressionCSSProperty) error
+	VisitCSSRule(*CSSRule) error
 	VisitDocType(*DocType) error
 	VisitHTMLTemplate(*HTMLTemplate) error
 	VisitText(*Text) error
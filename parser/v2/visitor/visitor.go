@@ -50,6 +50,14 @@ func New() *Visitor {
 		}
 		return nil
 	}
+	v.CSSRule = func(n *parser.CSSRule) error {
+		for _, prop := range n.Properties {
+			if err := prop.Visit(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	v.DocType = func(n *parser.DocType) error {
 		return nil
 	}
@@ -197,6 +205,10 @@ func New() *Visitor {
 
 // Visitor implements the parser.Visitor interface. Each function corresponds to a node type in the parse tree.
 // Override these functions to provide custom behavior when visiting nodes.
+//
+// Types that implement parser.Visitor can embed a Visitor created by New, so that they keep
+// compiling when methods are added to parser.Visitor, e.g. VisitCSSRule. The default
+// implementations visit child nodes using the embedded Visitor, not the embedding type.
 type Visitor struct {
 	TemplateFile             func(n *parser.TemplateFile) error
 	TemplateFileGoExpression func(n *parser.TemplateFileGoExpression) error
@@ -205,6 +217,7 @@ type Visitor struct {
 	CSSTemplate              func(n *parser.CSSTemplate) error
 	ConstantCSSProperty      func(n *parser.ConstantCSSProperty) error
 	ExpressionCSSProperty    func(n *parser.ExpressionCSSProperty) error
+	CSSRule                  func(n *parser.CSSRule) error
 	DocType                  func(n *parser.DocType) error
 	HTMLTemplate             func(n *parser.HTMLTemplate) error
 	Text                     func(n *parser.Text) error
@@ -260,6 +273,10 @@ func (v *Visitor) VisitExpressionCSSProperty(n *parser.ExpressionCSSProperty) er
 	return v.ExpressionCSSProperty(n)
 }
 
+func (v *Visitor) VisitCSSRule(n *parser.CSSRule) error {
+	return v.CSSRule(n)
+}
+
 func (v *Visitor) VisitDocType(n *parser.DocType) error {
 	return v.DocType(n)
 }
@@ -137,3 +137,29 @@ templ index(res *hn.SearchResponse) {
 		t.Fatalf("expected != actual:\n%s", diff)
 	}
 }
+
+// elementCounter implements parser.Visitor by embedding a Visitor, so it doesn't need to
+// implement every method.
+type elementCounter struct {
+	*visitor.Visitor
+	count int
+}
+
+func (ec *elementCounter) VisitElement(e *parser.Element) error {
+	ec.count++
+	return nil
+}
+
+func TestVisitorEmbedding(t *testing.T) {
+	ec := &elementCounter{Visitor: visitor.New()}
+	var v parser.Visitor = ec
+	if err := (&parser.Element{Name: "div"}).Visit(v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (&parser.CSSRule{}).Visit(v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ec.count != 1 {
+		t.Errorf("expected 1 element, got %d", ec.count)
+	}
+}
@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"context"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// Translation is used by generated code to render a message marked as translatable.
+//
+// The translation is HTML escaped, and each placeholder, e.g. "{ name }", is replaced by the
+// escaped value of the matching expression. args is a list of expression and value pairs.
+func Translation(ctx context.Context, msg templ.Message, count int, args ...string) string {
+	text := templ.Translate(ctx, msg, count)
+	var sb strings.Builder
+	for {
+		start := strings.Index(text, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(text[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+		value, ok := placeholderValue(text[start+1:end], args)
+		if !ok {
+			// Not a placeholder, output the text as-is.
+			sb.WriteString(templ.EscapeString(text[:end+1]))
+			text = text[end+1:]
+			continue
+		}
+		sb.WriteString(templ.EscapeString(text[:start]))
+		sb.WriteString(templ.EscapeString(value))
+		text = text[end+1:]
+	}
+	sb.WriteString(templ.EscapeString(text))
+	return sb.String()
+}
+
+// placeholderValue returns the value of the expression within a placeholder. Whitespace is
+// ignored, since translators may not preserve it.
+func placeholderValue(expr string, args []string) (value string, ok bool) {
+	expr = strings.Join(strings.Fields(expr), "")
+	for i := 0; i+1 < len(args); i += 2 {
+		if strings.Join(strings.Fields(args[i]), "") == expr {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
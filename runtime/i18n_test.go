@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+type translatorFunc func(locale string, msg templ.Message, count int) (string, bool)
+
+func (f translatorFunc) Translate(locale string, msg templ.Message, count int) (string, bool) {
+	return f(locale, msg, count)
+}
+
+func TestTranslation(t *testing.T) {
+	translator := translatorFunc(func(locale string, msg templ.Message, count int) (string, bool) {
+		if locale != "fr" {
+			return "", false
+		}
+		switch msg.ID {
+		case "Hello, { name }!":
+			return "Bonjour,{name} !", true
+		case "Braces":
+			return "{ not a placeholder } & <b>", true
+		}
+		return "", false
+	})
+	tests := []struct {
+		name     string
+		locale   string
+		msg      templ.Message
+		count    int
+		args     []string
+		expected string
+	}{
+		{
+			name:     "untranslated messages are interpolated and escaped",
+			msg:      templ.Message{ID: "Hello, { name }!"},
+			args:     []string{"name", "<Alice>"},
+			expected: "Hello, &lt;Alice&gt;!",
+		},
+		{
+			name:     "placeholders ignore whitespace",
+			locale:   "fr",
+			msg:      templ.Message{ID: "Hello, { name }!"},
+			args:     []string{"name", "Alice"},
+			expected: "Bonjour,Alice !",
+		},
+		{
+			name:     "text in braces that isn't a placeholder is kept",
+			locale:   "fr",
+			msg:      templ.Message{ID: "Braces"},
+			expected: "{ not a placeholder } &amp; &lt;b&gt;",
+		},
+		{
+			name:     "the plural form is used when the count is not 1",
+			msg:      templ.Message{ID: "One item", Plural: "{ n } items"},
+			count:    2,
+			args:     []string{"n", "2"},
+			expected: "2 items",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := templ.WithTranslator(context.Background(), translator)
+			ctx = templ.WithLocale(ctx, tt.locale)
+			actual := Translation(ctx, tt.msg, tt.count, tt.args...)
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
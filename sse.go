@@ -0,0 +1,202 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSSEKeepAlive is the interval between keep-alive comments sent by the SSEWriter, which
+// stop proxies from closing idle connections.
+const DefaultSSEKeepAlive = 15 * time.Second
+
+// SSEEvent is a server-sent event.
+type SSEEvent struct {
+	// ID is sent back by the browser in the Last-Event-ID header when it reconnects.
+	ID string
+	// Event is the name of the event. If empty, the browser dispatches a "message" event.
+	Event string
+	// Retry is the time the browser waits before reconnecting, if not zero.
+	Retry time.Duration
+	// Data is rendered as the data of the event.
+	Data Component
+}
+
+// SSEWriter sends server-sent events to the browser.
+type SSEWriter struct {
+	w           http.ResponseWriter
+	rc          *http.ResponseController
+	ctx         context.Context
+	lastEventID string
+	keepAlive   time.Duration
+
+	m    sync.Mutex
+	err  error
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// WithSSEKeepAlive sets the interval between keep-alive comments. If zero, keep-alive comments
+// are not sent.
+func WithSSEKeepAlive(interval time.Duration) func(*SSEWriter) {
+	return func(s *SSEWriter) {
+		s.keepAlive = interval
+	}
+}
+
+// SSE sends the headers of a server-sent event stream, and returns a writer for the events.
+// Close must be called before the handler returns, to stop sending keep-alive comments.
+//
+//	sse := templ.SSE(w, r)
+//	defer sse.Close()
+//	for {
+//		select {
+//		case <-sse.Done():
+//			return
+//		case msg := <-messages:
+//			if err := sse.Send(message(msg)); err != nil {
+//				return
+//			}
+//		}
+//	}
+func SSE(w http.ResponseWriter, r *http.Request, options ...func(*SSEWriter)) *SSEWriter {
+	s := &SSEWriter{
+		w:           w,
+		rc:          http.NewResponseController(w),
+		ctx:         r.Context(),
+		lastEventID: r.Header.Get("Last-Event-ID"),
+		keepAlive:   DefaultSSEKeepAlive,
+		stop:        make(chan struct{}),
+	}
+	for _, o := range options {
+		o(s)
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	// Stop nginx from buffering the events.
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	s.m.Lock()
+	s.err = s.rc.Flush()
+	s.m.Unlock()
+	if s.keepAlive > 0 {
+		s.wg.Add(1)
+		go s.sendKeepAlives()
+	}
+	return s
+}
+
+// LastEventID returns the ID of the last event received by the browser before it reconnected,
+// so that the events it missed can be sent. It's empty for the first connection.
+func (s *SSEWriter) LastEventID() string {
+	return s.lastEventID
+}
+
+// Done returns a channel that's closed when the request is done, e.g. because the browser
+// disconnected.
+func (s *SSEWriter) Done() <-chan struct{} {
+	return s.ctx.Done()
+}
+
+// Send renders the component as the data of a "message" event.
+func (s *SSEWriter) Send(c Component) error {
+	return s.SendEvent(SSEEvent{Data: c})
+}
+
+// SendEvent renders the event, and sends it to the browser.
+func (s *SSEWriter) SendEvent(e SSEEvent) (err error) {
+	if strings.ContainsAny(e.ID, "\r\n\x00") {
+		return errors.New("templ: SSE event IDs must not contain newlines or null characters")
+	}
+	if strings.ContainsAny(e.Event, "\r\n") {
+		return errors.New("templ: SSE event names must not contain newlines")
+	}
+	buf := GetBuffer()
+	defer ReleaseBuffer(buf)
+	if e.Data != nil {
+		if err = e.Data.Render(s.ctx, buf); err != nil {
+			return err
+		}
+	}
+	var event bytes.Buffer
+	if e.ID != "" {
+		event.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		event.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		event.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	writeSSEData(&event, buf.Bytes())
+	event.WriteByte('\n')
+	return s.write(event.Bytes())
+}
+
+// writeSSEData writes each line of the data as a separate data field, so that multi-line HTML
+// doesn't end the event early.
+func writeSSEData(w *bytes.Buffer, data []byte) {
+	// A carriage return on its own also ends a line in an event stream.
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+	for line := range bytes.SplitSeq(data, []byte("\n")) {
+		w.WriteString("data: ")
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+}
+
+func (s *SSEWriter) write(p []byte) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(p); err != nil {
+		s.err = err
+		return err
+	}
+	s.err = s.rc.Flush()
+	return s.err
+}
+
+func (s *SSEWriter) sendKeepAlives() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.write([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Close stops sending keep-alive comments. It doesn't end the response, which ends when the
+// handler returns.
+func (s *SSEWriter) Close() error {
+	s.m.Lock()
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	s.m.Unlock()
+	s.wg.Wait()
+	return nil
+}
@@ -0,0 +1,125 @@
+package templ_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSSE(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    templ.SSEEvent
+		expected string
+	}{
+		{
+			name:     "components are sent as message events",
+			event:    templ.SSEEvent{Data: templ.Raw("<p>Hello</p>")},
+			expected: "data: <p>Hello</p>\n\n",
+		},
+		{
+			name:     "multi-line output is split across data lines",
+			event:    templ.SSEEvent{Data: templ.Raw("<ul>\n<li>1</li>\r\n<li>2</li>\r</ul>")},
+			expected: "data: <ul>\ndata: <li>1</li>\ndata: <li>2</li>\ndata: </ul>\n\n",
+		},
+		{
+			name:     "events can have IDs, names and a retry time",
+			event:    templ.SSEEvent{ID: "42", Event: "update", Retry: 3 * time.Second, Data: templ.Raw("<p>Hello</p>")},
+			expected: "id: 42\nevent: update\nretry: 3000\ndata: <p>Hello</p>\n\n",
+		},
+		{
+			name:     "events without data have an empty data line",
+			event:    templ.SSEEvent{Event: "ping"},
+			expected: "event: ping\ndata: \n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/events", nil)
+			sse := templ.SSE(w, r)
+			if err := sse.SendEvent(tt.event); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := sse.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.Body.String()); diff != "" {
+				t.Error(diff)
+			}
+			expectedHeaders := map[string]string{
+				"Content-Type":      "text/event-stream",
+				"Cache-Control":     "no-cache",
+				"X-Accel-Buffering": "no",
+			}
+			for k, expected := range expectedHeaders {
+				if actual := w.Header().Get(k); actual != expected {
+					t.Errorf("expected %s header %q, got %q", k, expected, actual)
+				}
+			}
+			if !w.Flushed {
+				t.Error("expected the response to be flushed")
+			}
+		})
+	}
+	t.Run("IDs and names that would end the event early are rejected", func(t *testing.T) {
+		sse := templ.SSE(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events", nil))
+		defer func() {
+			_ = sse.Close()
+		}()
+		if err := sse.SendEvent(templ.SSEEvent{ID: "1\n2"}); err == nil {
+			t.Error("expected an error for the ID")
+		}
+		if err := sse.SendEvent(templ.SSEEvent{Event: "a\nb"}); err == nil {
+			t.Error("expected an error for the name")
+		}
+	})
+	t.Run("the Last-Event-ID header is available on reconnect", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/events", nil)
+		r.Header.Set("Last-Event-ID", "41")
+		sse := templ.SSE(httptest.NewRecorder(), r)
+		defer func() {
+			_ = sse.Close()
+		}()
+		if id := sse.LastEventID(); id != "41" {
+			t.Errorf("expected 41, got %q", id)
+		}
+	})
+	t.Run("keep-alive comments are sent", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		sse := templ.SSE(w, httptest.NewRequest(http.MethodGet, "/events", nil), templ.WithSSEKeepAlive(time.Millisecond))
+		time.Sleep(20 * time.Millisecond)
+		if err := sse.Close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(w.Body.String(), ": keep-alive\n\n") {
+			t.Errorf("expected keep-alive comments, got %q", w.Body.String())
+		}
+	})
+	t.Run("events are not sent after the request is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		w := httptest.NewRecorder()
+		sse := templ.SSE(w, httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx))
+		defer func() {
+			_ = sse.Close()
+		}()
+		cancel()
+		select {
+		case <-sse.Done():
+		case <-time.After(time.Second):
+			t.Fatal("expected Done to be closed")
+		}
+		if err := sse.Send(templ.Raw("<p>Hello</p>")); err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("expected nothing to be written, got %q", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,55 @@
+// Package tailwind resolves conflicts between Tailwind CSS utility classes, so that components can
+// accept classes that override their defaults.
+//
+// It's a separate package to templ, so that the rule table is only linked into programs that use it.
+package tailwind
+
+import (
+	"strings"
+
+	"github.com/a-h/templ"
+	tw "github.com/a-h/templ/internal/tailwind"
+)
+
+// Version is the version of Tailwind CSS that MergeClasses, and the class sorting in templ fmt,
+// are based on.
+const Version = tw.Version
+
+// MergeClasses is like templ.Classes, but resolves conflicts between Tailwind CSS utility classes
+// by removing classes that are overridden by later classes.
+//
+//	tailwind.MergeClasses("px-2 py-1 text-sm", "px-4") // "py-1 text-sm px-4"
+//
+// Conflicts are found using a built-in table of the utilities in the default Tailwind CSS theme,
+// see Version. Classes that aren't in the table are never removed, except for duplicates.
+func MergeClasses(classes ...any) templ.CSSClasses {
+	names := tw.Merge(strings.Fields(templ.Classes(classes...).String()))
+	components := map[string]templ.ComponentCSSClass{}
+	addComponents(components, classes)
+	merged := make(templ.CSSClasses, len(names))
+	for i, name := range names {
+		// Keep component classes so that their CSS is rendered.
+		if c, ok := components[name]; ok {
+			merged[i] = c
+			continue
+		}
+		merged[i] = name
+	}
+	return merged
+}
+
+// addComponents adds the component classes within the classes to the map.
+func addComponents(components map[string]templ.ComponentCSSClass, classes []any) {
+	for _, item := range classes {
+		switch c := item.(type) {
+		case templ.ComponentCSSClass:
+			components[c.ClassName()] = c
+		case templ.CSSClasses:
+			addComponents(components, c)
+		case []templ.CSSClass:
+			for _, cc := range c {
+				addComponents(components, []any{cc})
+			}
+		}
+	}
+}
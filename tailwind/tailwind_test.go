@@ -0,0 +1,70 @@
+package tailwind_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/tailwind"
+)
+
+func TestMergeClasses(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []any
+		expected string
+	}{
+		{
+			name:     "later utilities override earlier utilities",
+			input:    []any{"px-2 py-1 text-sm", "px-4"},
+			expected: "py-1 text-sm px-4",
+		},
+		{
+			name:     "utilities override the parts of earlier utilities that they set",
+			input:    []any{"px-2", "p-4"},
+			expected: "p-4",
+		},
+		{
+			name:     "variants are merged separately",
+			input:    []any{"bg-white hover:bg-gray-100", "bg-black"},
+			expected: "hover:bg-gray-100 bg-black",
+		},
+		{
+			name:     "unknown classes are kept",
+			input:    []any{"btn px-2", "btn-primary px-4"},
+			expected: "btn btn-primary px-4",
+		},
+		{
+			name: "disabled classes are removed before merging",
+			input: []any{
+				"px-2",
+				templ.KV("px-4", false),
+				map[string]bool{"text-red-500": true},
+			},
+			expected: "px-2 text-red-500",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := tailwind.MergeClasses(test.input...).String()
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+	t.Run("component CSS is rendered", func(t *testing.T) {
+		c := templ.ComponentCSSClass{ID: "red_1234", Class: ".red_1234{color:red;}"}
+		classes := tailwind.MergeClasses("px-2", templ.Classes(c), "px-4")
+		b := new(bytes.Buffer)
+		if err := templ.RenderCSSItems(context.Background(), b, classes...); err != nil {
+			t.Fatalf("failed to render CSS: %v", err)
+		}
+		if b.String() != `<style type="text/css">.red_1234{color:red;}</style>` {
+			t.Errorf("unexpected CSS: %q", b.String())
+		}
+		if classes.String() != "red_1234 px-4" {
+			t.Errorf("unexpected classes: %q", classes.String())
+		}
+	})
+}
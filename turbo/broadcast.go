@@ -0,0 +1,94 @@
+package turbo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/a-h/templ"
+)
+
+// subscriberBufferSize is the number of messages that can be queued for a subscriber. Subscribers
+// that fall further behind are disconnected, and reconnect automatically.
+const subscriberBufferSize = 16
+
+// Broadcaster sends Turbo Streams to subscribed browsers as server-sent events, for use with the
+// <turbo-stream-source> element:
+//
+//	<turbo-stream-source src="/messages/stream"></turbo-stream-source>
+//
+// The zero value is ready to use.
+type Broadcaster struct {
+	m           sync.Mutex
+	subscribers map[chan string]struct{}
+}
+
+// Broadcast renders the streams, and sends them to every subscriber as a single message.
+func (b *Broadcaster) Broadcast(ctx context.Context, streams ...Stream) error {
+	var buf bytes.Buffer
+	for _, s := range streams {
+		if err := s.Render(ctx, &buf); err != nil {
+			return err
+		}
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+	msg := buf.String()
+	b.m.Lock()
+	defer b.m.Unlock()
+	for sub := range b.subscribers {
+		select {
+		case sub <- msg:
+		default:
+			delete(b.subscribers, sub)
+			close(sub)
+		}
+	}
+	return nil
+}
+
+func (b *Broadcaster) subscribe() chan string {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if b.subscribers == nil {
+		b.subscribers = map[chan string]struct{}{}
+	}
+	sub := make(chan string, subscriberBufferSize)
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (b *Broadcaster) unsubscribe(sub chan string) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub)
+	}
+}
+
+// ServeHTTP subscribes the browser to broadcasts until the request is cancelled.
+func (b *Broadcaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sub := b.subscribe()
+	defer b.unsubscribe(sub)
+
+	sse := templ.SSE(w, r)
+	defer func() {
+		_ = sse.Close()
+	}()
+	for {
+		select {
+		case <-sse.Done():
+			return
+		case msg, ok := <-sub:
+			if !ok {
+				return
+			}
+			if err := sse.Send(templ.Raw(msg)); err != nil {
+				return
+			}
+		}
+	}
+}
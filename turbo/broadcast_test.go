@@ -0,0 +1,78 @@
+package turbo
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBroadcaster(t *testing.T) {
+	var b Broadcaster
+	srv := httptest.NewServer(&b)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", ct)
+	}
+
+	// The subscriber is registered before the headers are sent.
+	err = b.Broadcast(context.Background(),
+		Stream{Action: ActionAppend, Target: "messages", Template: templ.Raw("<p>Line 1\nLine 2</p>")},
+		Stream{Action: ActionRemove, Targets: ".empty"},
+	)
+	if err != nil {
+		t.Fatalf("failed to broadcast: %v", err)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if scanner.Text() == "" {
+			break
+		}
+		lines = append(lines, scanner.Text())
+	}
+	expected := []string{
+		`data: <turbo-stream action="append" target="messages"><template><p>Line 1`,
+		`data: Line 2</p></template></turbo-stream><turbo-stream action="remove" targets=".empty"></turbo-stream>`,
+	}
+	if diff := cmp.Diff(expected, lines); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Run("subscribers are removed when the request ends", func(t *testing.T) {
+		cancel()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			b.m.Lock()
+			n := len(b.subscribers)
+			b.m.Unlock()
+			if n == 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected no subscribers, got %d", n)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package turbo
+
+import (
+	"net/http"
+
+	"github.com/a-h/templ"
+)
+
+// frameFragmentID is the templ.Fragment ID of a Frame, which is a distinct type so that it
+// doesn't match other fragments.
+type frameFragmentID string
+
+// FrameID returns the id of the <turbo-frame> that made the request, or an empty string if the
+// request wasn't made by a frame.
+func FrameID(r *http.Request) string {
+	return r.Header.Get("Turbo-Frame")
+}
+
+// Handler creates a http.Handler that renders the component. When a <turbo-frame> makes the
+// request, only the Frame with the same id is rendered, instead of the whole page.
+//
+// The options are the same as for templ.Handler.
+func Handler(c templ.Component, options ...func(*templ.ComponentHandler)) http.Handler {
+	ch := templ.Handler(c, options...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Caches must store responses for each frame separately.
+		w.Header().Add("Vary", "Turbo-Frame")
+		id := FrameID(r)
+		if id == "" {
+			ch.ServeHTTP(w, r)
+			return
+		}
+		frame := *ch
+		frame.FragmentIDs = []any{frameFragmentID(id)}
+		frame.ServeHTTP(w, r)
+	})
+}
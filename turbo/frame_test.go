@@ -0,0 +1,66 @@
+package turbo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFrameHandler(t *testing.T) {
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err := io.WriteString(w, "<h1>Page</h1>"); err != nil {
+			return err
+		}
+		if err := Frame("messages", templ.Attributes{"target": "_top"}).Render(templ.WithChildren(ctx, templ.Raw("<p>Message</p>")), w); err != nil {
+			return err
+		}
+		// A fragment with the same ID as a frame isn't a frame.
+		return templ.Fragment("messages").Render(templ.WithChildren(ctx, templ.Raw("<p>Fragment</p>")), w)
+	})
+	h := Handler(page, templ.WithStatus(http.StatusAccepted))
+
+	tests := []struct {
+		name     string
+		frame    string
+		expected string
+	}{
+		{
+			name:     "requests not made by a frame receive the page",
+			expected: `<h1>Page</h1><turbo-frame id="messages" target="_top"><p>Message</p></turbo-frame><p>Fragment</p>`,
+		},
+		{
+			name:     "requests made by a frame receive the frame",
+			frame:    "messages",
+			expected: `<turbo-frame id="messages" target="_top"><p>Message</p></turbo-frame>`,
+		},
+		{
+			name:     "requests made by a frame that isn't on the page receive nothing",
+			frame:    "other",
+			expected: ``,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.frame != "" {
+				r.Header.Set("Turbo-Frame", tt.frame)
+			}
+			h.ServeHTTP(w, r)
+			if w.Code != http.StatusAccepted {
+				t.Errorf("expected the handler options to be used, got status %d", w.Code)
+			}
+			if diff := cmp.Diff("Turbo-Frame", w.Header().Get("Vary")); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.expected, w.Body.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package turbo
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBroadcastLineEndings(t *testing.T) {
+	start := `data: <turbo-stream action="append" target="messages"><template>`
+	end := "</template></turbo-stream>"
+	tests := []struct {
+		name     string
+		html     string
+		expected []string
+	}{
+		{
+			name:     "LF",
+			html:     "a\nb",
+			expected: []string{start + "a", "data: b" + end},
+		},
+		{
+			name:     "CRLF",
+			html:     "a\r\nb",
+			expected: []string{start + "a", "data: b" + end},
+		},
+		{
+			name:     "CR",
+			html:     "a\rb",
+			expected: []string{start + "a", "data: b" + end},
+		},
+		{
+			name:     "mixed",
+			html:     "a\r\nb\rc\n",
+			expected: []string{start + "a", "data: b", "data: c", "data: " + end},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b Broadcaster
+			srv := httptest.NewServer(&b)
+			defer srv.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("failed to subscribe: %v", err)
+			}
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+
+			err = b.Broadcast(context.Background(), Stream{Action: ActionAppend, Target: "messages", Template: templ.Raw(tt.html)})
+			if err != nil {
+				t.Fatalf("failed to broadcast: %v", err)
+			}
+			var lines []string
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				if scanner.Text() == "" {
+					break
+				}
+				lines = append(lines, scanner.Text())
+			}
+			if diff := cmp.Diff(tt.expected, lines); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -2,12 +2,64 @@ package turbo
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"strings"
 
 	"github.com/a-h/templ"
 )
 
+// ContentType is the content type of a Turbo Stream response.
+const ContentType = "text/vnd.turbo-stream.html"
+
+// Action is a Turbo Stream action.
+type Action string
+
+const (
+	ActionAppend  Action = "append"
+	ActionPrepend Action = "prepend"
+	ActionReplace Action = "replace"
+	ActionUpdate  Action = "update"
+	ActionRemove  Action = "remove"
+	ActionBefore  Action = "before"
+	ActionAfter   Action = "after"
+	ActionRefresh Action = "refresh"
+)
+
+// Stream is a <turbo-stream> element, which performs an action on the target elements.
+// Stream implements templ.Component, so it can be rendered in templates, or broadcast.
+type Stream struct {
+	Action Action
+	// Target is the id of the element to perform the action on.
+	Target string
+	// Targets is a CSS selector for the elements to perform the action on. If set, Target is
+	// ignored.
+	Targets string
+	// Morph morphs the existing content into the new content, instead of replacing it.
+	// It's only used by the replace and update actions.
+	Morph bool
+	// RequestID is used by the refresh action to avoid refreshing the page that made the request.
+	RequestID string
+	// Template is the content used by the action. The remove and refresh actions don't have
+	// content.
+	Template templ.Component
+}
+
+func (s Stream) Render(ctx context.Context, w io.Writer) error {
+	return streamTemplate(s).Render(ctx, w)
+}
+
+// Write sets the Content-Type header, and writes the streams to the response.
+func Write(ctx context.Context, w http.ResponseWriter, streams ...Stream) error {
+	w.Header().Set("Content-Type", ContentType)
+	for _, s := range streams {
+		if err := s.Render(ctx, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Append adds an append action to the output stream.
 func Append(w http.ResponseWriter, target string, template templ.Component) error {
 	return AppendWithContext(context.Background(), w, target, template)
@@ -15,8 +67,7 @@ func Append(w http.ResponseWriter, target string, template templ.Component) erro
 
 // AppendWithContext adds an append action to the output stream.
 func AppendWithContext(ctx context.Context, w http.ResponseWriter, target string, template templ.Component) error {
-	w.Header().Set("Content-Type", "text/vnd.turbo-stream.html")
-	return actionTemplate("append", target).Render(templ.WithChildren(ctx, template), w)
+	return Write(ctx, w, Stream{Action: ActionAppend, Target: target, Template: template})
 }
 
 // Prepend adds a prepend action to the output stream.
@@ -26,8 +77,7 @@ func Prepend(w http.ResponseWriter, target string, template templ.Component) err
 
 // PrependWithContext adds a prepend action to the output stream.
 func PrependWithContext(ctx context.Context, w http.ResponseWriter, target string, template templ.Component) error {
-	w.Header().Set("Content-Type", "text/vnd.turbo-stream.html")
-	return actionTemplate("prepend", target).Render(templ.WithChildren(ctx, template), w)
+	return Write(ctx, w, Stream{Action: ActionPrepend, Target: target, Template: template})
 }
 
 // Replace adds a replace action to the output stream.
@@ -37,8 +87,7 @@ func Replace(w http.ResponseWriter, target string, template templ.Component) err
 
 // ReplaceWithContext adds a replace action to the output stream.
 func ReplaceWithContext(ctx context.Context, w http.ResponseWriter, target string, template templ.Component) error {
-	w.Header().Set("Content-Type", "text/vnd.turbo-stream.html")
-	return actionTemplate("replace", target).Render(templ.WithChildren(ctx, template), w)
+	return Write(ctx, w, Stream{Action: ActionReplace, Target: target, Template: template})
 }
 
 // Update adds an update action to the output stream.
@@ -48,8 +97,7 @@ func Update(w http.ResponseWriter, target string, template templ.Component) erro
 
 // UpdateWithContext adds an update action to the output stream.
 func UpdateWithContext(ctx context.Context, w http.ResponseWriter, target string, template templ.Component) error {
-	w.Header().Set("Content-Type", "text/vnd.turbo-stream.html")
-	return actionTemplate("update", target).Render(templ.WithChildren(ctx, template), w)
+	return Write(ctx, w, Stream{Action: ActionUpdate, Target: target, Template: template})
 }
 
 // Remove adds a remove action to the output stream.
@@ -59,12 +107,63 @@ func Remove(w http.ResponseWriter, target string) error {
 
 // RemoveWithContext adds a remove action to the output stream.
 func RemoveWithContext(ctx context.Context, w http.ResponseWriter, target string) error {
-	w.Header().Set("Content-Type", "text/vnd.turbo-stream.html")
-	return removeTemplate("remove", target).Render(ctx, w)
+	return Write(ctx, w, Stream{Action: ActionRemove, Target: target})
+}
+
+// Before adds a before action to the output stream, which inserts the template before the target.
+func Before(w http.ResponseWriter, target string, template templ.Component) error {
+	return BeforeWithContext(context.Background(), w, target, template)
+}
+
+// BeforeWithContext adds a before action to the output stream.
+func BeforeWithContext(ctx context.Context, w http.ResponseWriter, target string, template templ.Component) error {
+	return Write(ctx, w, Stream{Action: ActionBefore, Target: target, Template: template})
+}
+
+// After adds an after action to the output stream, which inserts the template after the target.
+func After(w http.ResponseWriter, target string, template templ.Component) error {
+	return AfterWithContext(context.Background(), w, target, template)
+}
+
+// AfterWithContext adds an after action to the output stream.
+func AfterWithContext(ctx context.Context, w http.ResponseWriter, target string, template templ.Component) error {
+	return Write(ctx, w, Stream{Action: ActionAfter, Target: target, Template: template})
+}
+
+// ReplaceMorph adds a replace action to the output stream, which morphs the target into the
+// template.
+func ReplaceMorph(w http.ResponseWriter, target string, template templ.Component) error {
+	return ReplaceMorphWithContext(context.Background(), w, target, template)
+}
+
+// ReplaceMorphWithContext adds a replace action with morphing to the output stream.
+func ReplaceMorphWithContext(ctx context.Context, w http.ResponseWriter, target string, template templ.Component) error {
+	return Write(ctx, w, Stream{Action: ActionReplace, Target: target, Morph: true, Template: template})
+}
+
+// UpdateMorph adds an update action to the output stream, which morphs the contents of the
+// target into the template.
+func UpdateMorph(w http.ResponseWriter, target string, template templ.Component) error {
+	return UpdateMorphWithContext(context.Background(), w, target, template)
+}
+
+// UpdateMorphWithContext adds an update action with morphing to the output stream.
+func UpdateMorphWithContext(ctx context.Context, w http.ResponseWriter, target string, template templ.Component) error {
+	return Write(ctx, w, Stream{Action: ActionUpdate, Target: target, Morph: true, Template: template})
+}
+
+// Refresh adds a refresh action to the output stream, which reloads the page.
+func Refresh(w http.ResponseWriter) error {
+	return RefreshWithContext(context.Background(), w)
+}
+
+// RefreshWithContext adds a refresh action to the output stream.
+func RefreshWithContext(ctx context.Context, w http.ResponseWriter) error {
+	return Write(ctx, w, Stream{Action: ActionRefresh})
 }
 
 // IsTurboRequest returns true if the incoming request is able to receive a Turbo stream.
 // This is determined by checking the request header for "text/vnd.turbo-stream.html"
 func IsTurboRequest(r *http.Request) bool {
-	return strings.Contains(r.Header.Get("accept"), "text/vnd.turbo-stream.html")
+	return strings.Contains(r.Header.Get("accept"), ContentType)
 }
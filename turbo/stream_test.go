@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/a-h/templ"
 	"github.com/a-h/templ/internal/htmlfind"
+	"github.com/google/go-cmp/cmp"
 	"golang.org/x/net/html"
 )
 
@@ -126,3 +128,69 @@ func TestIsTurboRequest(t *testing.T) {
 		t.Error("request not correctly recognised as a Turbo stream request")
 	}
 }
+
+func TestStreamActions(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(w http.ResponseWriter) error
+		expected string
+	}{
+		{
+			name:     "before",
+			write:    func(w http.ResponseWriter) error { return Before(w, "target", contentTemplate) },
+			expected: `<turbo-stream action="before" target="target"><template>content</template></turbo-stream>`,
+		},
+		{
+			name:     "after",
+			write:    func(w http.ResponseWriter) error { return After(w, "target", contentTemplate) },
+			expected: `<turbo-stream action="after" target="target"><template>content</template></turbo-stream>`,
+		},
+		{
+			name:     "replace with morphing",
+			write:    func(w http.ResponseWriter) error { return ReplaceMorph(w, "target", contentTemplate) },
+			expected: `<turbo-stream action="replace" target="target" method="morph"><template>content</template></turbo-stream>`,
+		},
+		{
+			name:     "update with morphing",
+			write:    func(w http.ResponseWriter) error { return UpdateMorph(w, "target", contentTemplate) },
+			expected: `<turbo-stream action="update" target="target" method="morph"><template>content</template></turbo-stream>`,
+		},
+		{
+			name:     "refresh",
+			write:    Refresh,
+			expected: `<turbo-stream action="refresh"></turbo-stream>`,
+		},
+		{
+			name: "multiple targets",
+			write: func(w http.ResponseWriter) error {
+				return Write(context.Background(), w, Stream{Action: ActionRemove, Target: "ignored", Targets: ".notification"})
+			},
+			expected: `<turbo-stream action="remove" targets=".notification"></turbo-stream>`,
+		},
+		{
+			name: "multiple streams",
+			write: func(w http.ResponseWriter) error {
+				return Write(context.Background(), w,
+					Stream{Action: ActionAppend, Targets: "#messages li", Template: contentTemplate},
+					Stream{Action: ActionRefresh, RequestID: "abc"},
+				)
+			},
+			expected: `<turbo-stream action="append" targets="#messages li"><template>content</template></turbo-stream>` +
+				`<turbo-stream action="refresh" request-id="abc"></turbo-stream>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if err := tt.write(w); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.Body.String()); diff != "" {
+				t.Error(diff)
+			}
+			if w.Header().Get("Content-Type") != ContentType {
+				t.Errorf("expected Content-Type %q, got %q", ContentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}